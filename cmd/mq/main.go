@@ -34,32 +34,119 @@ func main() {
 		panic(err)
 	}
 
-	// Create WAL logger
-	wal, err := wal.Open(wal.Options{
-		DirPath:        cfg.Wal.WalDirPath,
-		SegmentSize:    cfg.Wal.WalSegmentSize,
-		SegmentFileExt: cfg.Wal.WalSegmentFileExt,
-		Sync:           cfg.Wal.WalSync,
-		BytesPerSync:   cfg.Wal.WalBytesPerSync,
-	})
-	if err != nil {
-		slog.Error("failed to open WAL")
+	// Create storage service, either in memory and recovered from the WAL, or on disk
+	var store storage.Storage
+	var closeStorage func() error
+	switch cfg.Storage.StorageEngine {
+	case config.StorageEngineMemory:
+		// Recover from the damaged records of the WAL before it is written to
+		if err := storage.RecoverWal(
+			&storage.WalRecoveryOptions{
+				DirPath:        cfg.Wal.WalDirPath,
+				SegmentFileExt: cfg.Wal.WalSegmentFileExt,
+				Mode:           storage.WalRecoveryMode(cfg.Wal.WalRecoveryMode),
+			},
+		); err != nil {
+			slog.Error(
+				"failed to recover WAL",
+				slog.Any("error", err),
+			)
+			os.Exit(1)
+		}
+
+		// Create WAL logger
+		wal, err := wal.Open(wal.Options{
+			DirPath:        cfg.Wal.WalDirPath,
+			SegmentSize:    cfg.Wal.WalSegmentSize,
+			SegmentFileExt: cfg.Wal.WalSegmentFileExt,
+			Sync:           cfg.Wal.WalSync,
+			BytesPerSync:   cfg.Wal.WalBytesPerSync,
+		})
+		if err != nil {
+			slog.Error("failed to open WAL")
+			os.Exit(1)
+		}
+
+		memoryStorage, err := storage.NewMemoryStorage(
+			&storage.MemoryStorageOptions{
+				Wal:               wal,
+				WalDirPath:        cfg.Wal.WalDirPath,
+				WalSegmentFileExt: cfg.Wal.WalSegmentFileExt,
+				WalSync:           cfg.Wal.WalSync,
+				WalRecoveryMode:   storage.WalRecoveryMode(cfg.Wal.WalRecoveryMode),
+				BatchSize:         cfg.Storage.StorageBatchSize,
+				SyncOnStartup:     cfg.Storage.StorageSyncOnStartup,
+				DedupWindow:       cfg.Storage.StorageDedupWindow,
+				SweepInterval:     cfg.Storage.StorageSweepInterval,
+				SnapshotDirPath:   cfg.Storage.StorageSnapshotDirPath,
+				SnapshotInterval:  cfg.Storage.StorageSnapshotInterval,
+			},
+		)
+		if err != nil {
+			slog.Error(
+				"failed to replay WAL",
+				slog.Any("error", err),
+			)
+			os.Exit(1)
+		}
+
+		store = memoryStorage
+		closeStorage = func() error {
+			// The background work of the storage, and its final snapshot, write to the WAL until it is closed
+			if err := memoryStorage.Close(); err != nil {
+				slog.Error(
+					"failed to take snapshot",
+					slog.Any("error", err),
+				)
+			}
+			if err := wal.Sync(); err != nil {
+				return err
+			}
+			return wal.Close()
+		}
+	case config.StorageEngineDisk:
+		diskStorage, err := storage.NewDiskStorage(
+			&storage.DiskStorageOptions{
+				DirPath:       cfg.Storage.StorageDirPath,
+				SegmentSize:   cfg.Storage.StorageSegmentSize,
+				Sync:          cfg.Storage.StorageSync,
+				BatchSize:     cfg.Storage.StorageBatchSize,
+				DedupWindow:   cfg.Storage.StorageDedupWindow,
+				SweepInterval: cfg.Storage.StorageSweepInterval,
+			},
+		)
+		if err != nil {
+			slog.Error(
+				"failed to open disk storage",
+				slog.Any("error", err),
+			)
+			os.Exit(1)
+		}
+
+		store = diskStorage
+		closeStorage = func() error {
+			if err := diskStorage.Sync(); err != nil {
+				return err
+			}
+			diskStorage.Close()
+			return nil
+		}
+	default:
+		slog.Error(
+			"unknown storage engine",
+			slog.String("engine", cfg.Storage.StorageEngine),
+		)
 		os.Exit(1)
 	}
 
-	// Create storage service
-	memoryStorage := storage.NewMemoryStorage(
-		&storage.MemoryStorageOptions{
-			Wal:           wal,
-			BatchSize:     cfg.Storage.StorageBatchSize,
-			SyncOnStartup: cfg.Storage.StorageSyncOnStartup,
-		},
-	)
-
-	// Create mq service
+	// Create mq service, sharing the generator of the server
+	generator := utils.NewGenerator()
 	srv := mq.NewService(
 		&mq.ServiceOptions{
-			Storage: memoryStorage,
+			Storage:              store,
+			Generator:            generator,
+			AutoCreateChannels:   cfg.Channel.ChannelAutoCreate,
+			AutoCreateNamespaces: cfg.Channel.ChannelAutoCreateNamespaces,
 		},
 	)
 
@@ -67,7 +154,7 @@ func main() {
 	server := mq.NewServer(
 		&mq.ServerOptions{
 			Validator: utils.NewValidator(),
-			Generator: utils.NewGenerator(),
+			Generator: generator,
 			Service:   srv,
 		},
 	)
@@ -130,11 +217,17 @@ func main() {
 	)
 	defer cancel()
 
-	// Gracefully stop the broker server with timeout
+	// Gracefully stop the broker server with timeout, then stop publishing and close the storage
 	done := make(chan struct{})
 	go func() {
-		_ = wal.Sync()
 		grpcServer.GracefulStop()
+		srv.Close()
+		if err := closeStorage(); err != nil {
+			slog.Error(
+				"failed to close storage",
+				slog.Any("error", err),
+			)
+		}
 		close(done)
 	}()
 
@@ -144,5 +237,8 @@ func main() {
 	case <-ctx.Done():
 		slog.Warn("server shutdown timed out, forcing stop")
 		grpcServer.Stop()
+
+		// The published messages still queued are saved or refused, and the storage closed, once the server stops
+		<-done
 	}
 }
@@ -21,9 +21,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MQService_CreateChannel_FullMethodName = "/mq.MQService/CreateChannel"
-	MQService_Publish_FullMethodName       = "/mq.MQService/Publish"
-	MQService_Subscribe_FullMethodName     = "/mq.MQService/Subscribe"
+	MQService_CreateChannel_FullMethodName       = "/mq.MQService/CreateChannel"
+	MQService_DeleteChannel_FullMethodName       = "/mq.MQService/DeleteChannel"
+	MQService_PurgeChannel_FullMethodName        = "/mq.MQService/PurgeChannel"
+	MQService_ListChannels_FullMethodName        = "/mq.MQService/ListChannels"
+	MQService_DescribeChannel_FullMethodName     = "/mq.MQService/DescribeChannel"
+	MQService_UpdateChannelConfig_FullMethodName = "/mq.MQService/UpdateChannelConfig"
+	MQService_Publish_FullMethodName             = "/mq.MQService/Publish"
+	MQService_PublishBatch_FullMethodName        = "/mq.MQService/PublishBatch"
+	MQService_PublishStream_FullMethodName       = "/mq.MQService/PublishStream"
+	MQService_Subscribe_FullMethodName           = "/mq.MQService/Subscribe"
+	MQService_Ack_FullMethodName                 = "/mq.MQService/Ack"
+	MQService_Nack_FullMethodName                = "/mq.MQService/Nack"
+	MQService_Reject_FullMethodName              = "/mq.MQService/Reject"
 )
 
 // MQServiceClient is the client API for MQService service.
@@ -34,10 +44,30 @@ const (
 type MQServiceClient interface {
 	// CreateChannel creates a new channel
 	CreateChannel(ctx context.Context, in *CreateChannelRequest, opts ...grpc.CallOption) (*CreateChannelResponse, error)
+	// DeleteChannel deletes a channel along with its messages and cursors, its subscribers are disconnected
+	DeleteChannel(ctx context.Context, in *DeleteChannelRequest, opts ...grpc.CallOption) (*DeleteChannelResponse, error)
+	// PurgeChannel drops the messages of a channel, the channel and its configuration are kept
+	PurgeChannel(ctx context.Context, in *PurgeChannelRequest, opts ...grpc.CallOption) (*PurgeChannelResponse, error)
+	// ListChannels lists the names of the channels, a page at a time
+	ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error)
+	// DescribeChannel returns the configuration, the messages and the subscribers of a channel
+	DescribeChannel(ctx context.Context, in *DescribeChannelRequest, opts ...grpc.CallOption) (*DescribeChannelResponse, error)
+	// UpdateChannelConfig replaces the configuration of a channel
+	UpdateChannelConfig(ctx context.Context, in *UpdateChannelConfigRequest, opts ...grpc.CallOption) (*UpdateChannelConfigResponse, error)
 	// Publisher publishes a message to a channel
 	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	// Publisher publishes several messages at once, they are all saved or none is
+	PublishBatch(ctx context.Context, in *PublishBatchRequest, opts ...grpc.CallOption) (*PublishBatchResponse, error)
+	// Publisher publishes messages over a long-lived stream, and receives their acknowledgements in order
+	PublishStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PublishRequest, PublishStreamResponse], error)
 	// Consumer subscribes to a channel and receives a stream of messages
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
+	// Consumer acknowledges the messages it has processed
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error)
+	// Consumer hands back the messages it failed to process, so that they are redelivered
+	Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*NackResponse, error)
+	// Consumer rejects the messages it cannot process, they are dead-lettered once they exceed the channel's max delivery attempts
+	Reject(ctx context.Context, in *RejectRequest, opts ...grpc.CallOption) (*RejectResponse, error)
 }
 
 type mQServiceClient struct {
@@ -58,6 +88,56 @@ func (c *mQServiceClient) CreateChannel(ctx context.Context, in *CreateChannelRe
 	return out, nil
 }
 
+func (c *mQServiceClient) DeleteChannel(ctx context.Context, in *DeleteChannelRequest, opts ...grpc.CallOption) (*DeleteChannelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteChannelResponse)
+	err := c.cc.Invoke(ctx, MQService_DeleteChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) PurgeChannel(ctx context.Context, in *PurgeChannelRequest, opts ...grpc.CallOption) (*PurgeChannelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeChannelResponse)
+	err := c.cc.Invoke(ctx, MQService_PurgeChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) ListChannels(ctx context.Context, in *ListChannelsRequest, opts ...grpc.CallOption) (*ListChannelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChannelsResponse)
+	err := c.cc.Invoke(ctx, MQService_ListChannels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) DescribeChannel(ctx context.Context, in *DescribeChannelRequest, opts ...grpc.CallOption) (*DescribeChannelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DescribeChannelResponse)
+	err := c.cc.Invoke(ctx, MQService_DescribeChannel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) UpdateChannelConfig(ctx context.Context, in *UpdateChannelConfigRequest, opts ...grpc.CallOption) (*UpdateChannelConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateChannelConfigResponse)
+	err := c.cc.Invoke(ctx, MQService_UpdateChannelConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *mQServiceClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PublishResponse)
@@ -68,9 +148,32 @@ func (c *mQServiceClient) Publish(ctx context.Context, in *PublishRequest, opts
 	return out, nil
 }
 
+func (c *mQServiceClient) PublishBatch(ctx context.Context, in *PublishBatchRequest, opts ...grpc.CallOption) (*PublishBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishBatchResponse)
+	err := c.cc.Invoke(ctx, MQService_PublishBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) PublishStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PublishRequest, PublishStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MQService_ServiceDesc.Streams[0], MQService_PublishStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PublishRequest, PublishStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MQService_PublishStreamClient = grpc.BidiStreamingClient[PublishRequest, PublishStreamResponse]
+
 func (c *mQServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &MQService_ServiceDesc.Streams[0], MQService_Subscribe_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &MQService_ServiceDesc.Streams[1], MQService_Subscribe_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +190,36 @@ func (c *mQServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, o
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type MQService_SubscribeClient = grpc.ServerStreamingClient[Message]
 
+func (c *mQServiceClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AckResponse)
+	err := c.cc.Invoke(ctx, MQService_Ack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*NackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NackResponse)
+	err := c.cc.Invoke(ctx, MQService_Nack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mQServiceClient) Reject(ctx context.Context, in *RejectRequest, opts ...grpc.CallOption) (*RejectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RejectResponse)
+	err := c.cc.Invoke(ctx, MQService_Reject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MQServiceServer is the server API for MQService service.
 // All implementations must embed UnimplementedMQServiceServer
 // for forward compatibility.
@@ -95,10 +228,30 @@ type MQService_SubscribeClient = grpc.ServerStreamingClient[Message]
 type MQServiceServer interface {
 	// CreateChannel creates a new channel
 	CreateChannel(context.Context, *CreateChannelRequest) (*CreateChannelResponse, error)
+	// DeleteChannel deletes a channel along with its messages and cursors, its subscribers are disconnected
+	DeleteChannel(context.Context, *DeleteChannelRequest) (*DeleteChannelResponse, error)
+	// PurgeChannel drops the messages of a channel, the channel and its configuration are kept
+	PurgeChannel(context.Context, *PurgeChannelRequest) (*PurgeChannelResponse, error)
+	// ListChannels lists the names of the channels, a page at a time
+	ListChannels(context.Context, *ListChannelsRequest) (*ListChannelsResponse, error)
+	// DescribeChannel returns the configuration, the messages and the subscribers of a channel
+	DescribeChannel(context.Context, *DescribeChannelRequest) (*DescribeChannelResponse, error)
+	// UpdateChannelConfig replaces the configuration of a channel
+	UpdateChannelConfig(context.Context, *UpdateChannelConfigRequest) (*UpdateChannelConfigResponse, error)
 	// Publisher publishes a message to a channel
 	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
+	// Publisher publishes several messages at once, they are all saved or none is
+	PublishBatch(context.Context, *PublishBatchRequest) (*PublishBatchResponse, error)
+	// Publisher publishes messages over a long-lived stream, and receives their acknowledgements in order
+	PublishStream(grpc.BidiStreamingServer[PublishRequest, PublishStreamResponse]) error
 	// Consumer subscribes to a channel and receives a stream of messages
 	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Message]) error
+	// Consumer acknowledges the messages it has processed
+	Ack(context.Context, *AckRequest) (*AckResponse, error)
+	// Consumer hands back the messages it failed to process, so that they are redelivered
+	Nack(context.Context, *NackRequest) (*NackResponse, error)
+	// Consumer rejects the messages it cannot process, they are dead-lettered once they exceed the channel's max delivery attempts
+	Reject(context.Context, *RejectRequest) (*RejectResponse, error)
 	mustEmbedUnimplementedMQServiceServer()
 }
 
@@ -112,12 +265,42 @@ type UnimplementedMQServiceServer struct{}
 func (UnimplementedMQServiceServer) CreateChannel(context.Context, *CreateChannelRequest) (*CreateChannelResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateChannel not implemented")
 }
+func (UnimplementedMQServiceServer) DeleteChannel(context.Context, *DeleteChannelRequest) (*DeleteChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteChannel not implemented")
+}
+func (UnimplementedMQServiceServer) PurgeChannel(context.Context, *PurgeChannelRequest) (*PurgeChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeChannel not implemented")
+}
+func (UnimplementedMQServiceServer) ListChannels(context.Context, *ListChannelsRequest) (*ListChannelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChannels not implemented")
+}
+func (UnimplementedMQServiceServer) DescribeChannel(context.Context, *DescribeChannelRequest) (*DescribeChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeChannel not implemented")
+}
+func (UnimplementedMQServiceServer) UpdateChannelConfig(context.Context, *UpdateChannelConfigRequest) (*UpdateChannelConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateChannelConfig not implemented")
+}
 func (UnimplementedMQServiceServer) Publish(context.Context, *PublishRequest) (*PublishResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Publish not implemented")
 }
+func (UnimplementedMQServiceServer) PublishBatch(context.Context, *PublishBatchRequest) (*PublishBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishBatch not implemented")
+}
+func (UnimplementedMQServiceServer) PublishStream(grpc.BidiStreamingServer[PublishRequest, PublishStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PublishStream not implemented")
+}
 func (UnimplementedMQServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Message]) error {
 	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
 }
+func (UnimplementedMQServiceServer) Ack(context.Context, *AckRequest) (*AckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ack not implemented")
+}
+func (UnimplementedMQServiceServer) Nack(context.Context, *NackRequest) (*NackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Nack not implemented")
+}
+func (UnimplementedMQServiceServer) Reject(context.Context, *RejectRequest) (*RejectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reject not implemented")
+}
 func (UnimplementedMQServiceServer) mustEmbedUnimplementedMQServiceServer() {}
 func (UnimplementedMQServiceServer) testEmbeddedByValue()                   {}
 
@@ -157,6 +340,96 @@ func _MQService_CreateChannel_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MQService_DeleteChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).DeleteChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_DeleteChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).DeleteChannel(ctx, req.(*DeleteChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_PurgeChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).PurgeChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_PurgeChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).PurgeChannel(ctx, req.(*PurgeChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_ListChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).ListChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_ListChannels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).ListChannels(ctx, req.(*ListChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_DescribeChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).DescribeChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_DescribeChannel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).DescribeChannel(ctx, req.(*DescribeChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_UpdateChannelConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateChannelConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).UpdateChannelConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_UpdateChannelConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).UpdateChannelConfig(ctx, req.(*UpdateChannelConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MQService_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PublishRequest)
 	if err := dec(in); err != nil {
@@ -175,6 +448,31 @@ func _MQService_Publish_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MQService_PublishBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).PublishBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_PublishBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).PublishBatch(ctx, req.(*PublishBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_PublishStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MQServiceServer).PublishStream(&grpc.GenericServerStream[PublishRequest, PublishStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MQService_PublishStreamServer = grpc.BidiStreamingServer[PublishRequest, PublishStreamResponse]
+
 func _MQService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(SubscribeRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -186,6 +484,60 @@ func _MQService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) err
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type MQService_SubscribeServer = grpc.ServerStreamingServer[Message]
 
+func _MQService_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_Ack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_Nack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).Nack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_Nack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).Nack(ctx, req.(*NackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MQService_Reject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RejectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MQServiceServer).Reject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MQService_Reject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MQServiceServer).Reject(ctx, req.(*RejectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MQService_ServiceDesc is the grpc.ServiceDesc for MQService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -197,12 +549,54 @@ var MQService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateChannel",
 			Handler:    _MQService_CreateChannel_Handler,
 		},
+		{
+			MethodName: "DeleteChannel",
+			Handler:    _MQService_DeleteChannel_Handler,
+		},
+		{
+			MethodName: "PurgeChannel",
+			Handler:    _MQService_PurgeChannel_Handler,
+		},
+		{
+			MethodName: "ListChannels",
+			Handler:    _MQService_ListChannels_Handler,
+		},
+		{
+			MethodName: "DescribeChannel",
+			Handler:    _MQService_DescribeChannel_Handler,
+		},
+		{
+			MethodName: "UpdateChannelConfig",
+			Handler:    _MQService_UpdateChannelConfig_Handler,
+		},
 		{
 			MethodName: "Publish",
 			Handler:    _MQService_Publish_Handler,
 		},
+		{
+			MethodName: "PublishBatch",
+			Handler:    _MQService_PublishBatch_Handler,
+		},
+		{
+			MethodName: "Ack",
+			Handler:    _MQService_Ack_Handler,
+		},
+		{
+			MethodName: "Nack",
+			Handler:    _MQService_Nack_Handler,
+		},
+		{
+			MethodName: "Reject",
+			Handler:    _MQService_Reject_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PublishStream",
+			Handler:       _MQService_PublishStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "Subscribe",
 			Handler:       _MQService_Subscribe_Handler,
@@ -12,23 +12,64 @@ import (
 // envPrefix is the prefix used for environment variables
 const envPrefix = ""
 
+const (
+	// StorageEngineMemory keeps the messages in memory, they are recovered from the WAL on startup
+	StorageEngineMemory = "memory"
+
+	// StorageEngineDisk keeps the messages on disk, in a segmented log per channel they are read from
+	StorageEngineDisk = "disk"
+)
+
 // Configuration represents the configuration of the application
 type Configuration struct {
 	Storage
 	Wal
+	Channel
 	Server
 	Environment
 }
 
 // Storage holds configuration settings for storage operations.
 type Storage struct {
+	// StorageEngine selects where the messages are stored, either "memory" or "disk".
+	// default: memory
+	StorageEngine string `envconfig:"STORAGE_ENGINE" default:"memory"`
+
+	// StorageDirPath specifies the directory path where the disk storage keeps the channels.
+	// default: ./channels
+	StorageDirPath string `envconfig:"STORAGE_DIR_PATH" default:"./channels"`
+
+	// StorageSegmentSize defines the size (in bytes) past which the disk storage starts a new segment of a channel.
+	// default: 52428800 bytes (50MB)
+	StorageSegmentSize int64 `envconfig:"STORAGE_SEGMENT_SIZE" default:"52428800"` // 50MB (5,24,28,800) bytes
+
+	// StorageSync controls whether each write operation of the disk storage should be fsync’d.
+	// default: true
+	StorageSync bool `envconfig:"STORAGE_SYNC" default:"true"`
+
 	// StorageBatchSize specifies the batch size for storage operations.
 	// default: 500
 	StorageBatchSize uint64 `envconfig:"STORAGE_BATCH_SIZE" default:"500"`
 
-	// StorageSyncOnStartup indicates whether to perform a storage synchronization on startup.
+	// StorageSyncOnStartup indicates whether the memory storage is synchronized with the WAL on startup.
 	// default: true
 	StorageSyncOnStartup bool `envconfig:"STORAGE_SYNC_ON_STARTUP" default:"true"`
+
+	// StorageDedupWindow specifies for how long published messages are deduplicated on their idempotency key.
+	// default: 2m
+	StorageDedupWindow time.Duration `envconfig:"STORAGE_DEDUP_WINDOW" default:"2m"`
+
+	// StorageSnapshotDirPath specifies the directory path where the memory storage keeps its snapshots.
+	// default: ./snapshots
+	StorageSnapshotDirPath string `envconfig:"STORAGE_SNAPSHOT_DIR_PATH" default:"./snapshots"`
+
+	// StorageSnapshotInterval specifies how often the memory storage takes a snapshot, so that only the WAL after it is replayed on startup, 0 disables snapshots.
+	// default: 5m
+	StorageSnapshotInterval time.Duration `envconfig:"STORAGE_SNAPSHOT_INTERVAL" default:"5m"`
+
+	// StorageSweepInterval specifies how often expired messages are dropped from memory, and messages past retention deleted.
+	// default: 30s
+	StorageSweepInterval time.Duration `envconfig:"STORAGE_SWEEP_INTERVAL" default:"30s"`
 }
 
 // Wal represents the configuration for the Write-Ahead Logging (WAL) mechanism.
@@ -52,6 +93,22 @@ type Wal struct {
 	// WalBytesPerSync specifies the number of bytes to write before calling fsync.
 	// default: 5000 bytes (5KB)
 	WalBytesPerSync uint32 `envconfig:"WAL_BYTES_PER_SYNC" default:"5000"`
+
+	// WalRecoveryMode selects how damaged WAL records, such as the ones torn by a crash, are recovered from on startup:
+	// "truncate" truncates the WAL at the first damaged record, "skip" skips the damaged records and "refuse" refuses to start.
+	// default: truncate
+	WalRecoveryMode string `envconfig:"WAL_RECOVERY_MODE" default:"truncate"`
+}
+
+// Channel holds the configuration settings for channels.
+type Channel struct {
+	// ChannelAutoCreate controls whether publishing to a channel that does not exist creates it.
+	// default: false
+	ChannelAutoCreate bool `envconfig:"CHANNEL_AUTO_CREATE" default:"false"`
+
+	// ChannelAutoCreateNamespaces overrides ChannelAutoCreate for the channels of a namespace, as namespace:enabled pairs
+	// separated by commas (e.g. "orders:true,tmp:false"). The longest namespace a channel belongs to wins.
+	ChannelAutoCreateNamespaces map[string]bool `envconfig:"CHANNEL_AUTO_CREATE_NAMESPACES"`
 }
 
 // Server holds the configuration settings for server.
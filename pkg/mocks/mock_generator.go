@@ -33,6 +33,20 @@ func (m *MockGenerator) EXPECT() *MockGeneratorMockRecorder {
 	return m.recorder
 }
 
+// GetCurrentTimeMillis mocks base method.
+func (m *MockGenerator) GetCurrentTimeMillis() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentTimeMillis")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// GetCurrentTimeMillis indicates an expected call of GetCurrentTimeMillis.
+func (mr *MockGeneratorMockRecorder) GetCurrentTimeMillis() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentTimeMillis", reflect.TypeOf((*MockGenerator)(nil).GetCurrentTimeMillis))
+}
+
 // GetCurrentTimestamp mocks base method.
 func (m *MockGenerator) GetCurrentTimestamp() int64 {
 	m.ctrl.T.Helper()
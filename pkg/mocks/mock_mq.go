@@ -35,36 +35,165 @@ func (m *MockMQ) EXPECT() *MockMQMockRecorder {
 	return m.recorder
 }
 
+// Ack mocks base method.
+func (m *MockMQ) Ack(arg0 context.Context, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ack", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ack indicates an expected call of Ack.
+func (mr *MockMQMockRecorder) Ack(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockMQ)(nil).Ack), arg0, arg1, arg2)
+}
+
 // CreateChannel mocks base method.
-func (m *MockMQ) CreateChannel(arg0 context.Context, arg1 string) error {
+func (m *MockMQ) CreateChannel(arg0 context.Context, arg1 string, arg2 *mq.ChannelConfig) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateChannel", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateChannel", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateChannel indicates an expected call of CreateChannel.
-func (mr *MockMQMockRecorder) CreateChannel(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockMQMockRecorder) CreateChannel(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockMQ)(nil).CreateChannel), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockMQ)(nil).CreateChannel), arg0, arg1, arg2)
 }
 
-// Publish mocks base method.
-func (m *MockMQ) Publish(arg0 context.Context, arg1 string, arg2 *mq.Message) error {
+// DeleteChannel mocks base method.
+func (m *MockMQ) DeleteChannel(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Publish", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "DeleteChannel", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeleteChannel indicates an expected call of DeleteChannel.
+func (mr *MockMQMockRecorder) DeleteChannel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannel", reflect.TypeOf((*MockMQ)(nil).DeleteChannel), arg0, arg1)
+}
+
+// DescribeChannel mocks base method.
+func (m *MockMQ) DescribeChannel(arg0 context.Context, arg1 string) (*mq.DescribeChannelResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeChannel", arg0, arg1)
+	ret0, _ := ret[0].(*mq.DescribeChannelResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeChannel indicates an expected call of DescribeChannel.
+func (mr *MockMQMockRecorder) DescribeChannel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeChannel", reflect.TypeOf((*MockMQ)(nil).DescribeChannel), arg0, arg1)
+}
+
+// ListChannels mocks base method.
+func (m *MockMQ) ListChannels(arg0 context.Context, arg1 string, arg2 uint32, arg3 string) ([]string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChannels", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListChannels indicates an expected call of ListChannels.
+func (mr *MockMQMockRecorder) ListChannels(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChannels", reflect.TypeOf((*MockMQ)(nil).ListChannels), arg0, arg1, arg2, arg3)
+}
+
+// Nack mocks base method.
+func (m *MockMQ) Nack(arg0 context.Context, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nack", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Nack indicates an expected call of Nack.
+func (mr *MockMQMockRecorder) Nack(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nack", reflect.TypeOf((*MockMQ)(nil).Nack), arg0, arg1, arg2)
+}
+
+// Publish mocks base method.
+func (m *MockMQ) Publish(arg0 context.Context, arg1 string, arg2 *mq.Message) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Publish indicates an expected call of Publish.
 func (mr *MockMQMockRecorder) Publish(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockMQ)(nil).Publish), arg0, arg1, arg2)
 }
 
+// PublishAsync mocks base method.
+func (m *MockMQ) PublishAsync(arg0 context.Context, arg1 string, arg2 *mq.Message, arg3 func(uint64, error)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PublishAsync", arg0, arg1, arg2, arg3)
+}
+
+// PublishAsync indicates an expected call of PublishAsync.
+func (mr *MockMQMockRecorder) PublishAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAsync", reflect.TypeOf((*MockMQ)(nil).PublishAsync), arg0, arg1, arg2, arg3)
+}
+
+// PublishBatch mocks base method.
+func (m *MockMQ) PublishBatch(arg0 context.Context, arg1 []*mq.WalEntry) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishBatch", arg0, arg1)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishBatch indicates an expected call of PublishBatch.
+func (mr *MockMQMockRecorder) PublishBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishBatch", reflect.TypeOf((*MockMQ)(nil).PublishBatch), arg0, arg1)
+}
+
+// PurgeChannel mocks base method.
+func (m *MockMQ) PurgeChannel(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeChannel", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeChannel indicates an expected call of PurgeChannel.
+func (mr *MockMQMockRecorder) PurgeChannel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeChannel", reflect.TypeOf((*MockMQ)(nil).PurgeChannel), arg0, arg1)
+}
+
+// Reject mocks base method.
+func (m *MockMQ) Reject(arg0 context.Context, arg1 string, arg2 []string, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockMQMockRecorder) Reject(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockMQ)(nil).Reject), arg0, arg1, arg2, arg3)
+}
+
 // Subscribe mocks base method.
-func (m *MockMQ) Subscribe(arg0 context.Context, arg1 *mq.Subscriber, arg2 mq.Offset, arg3 uint64, arg4 string, arg5 chan<- *mq.Message) error {
+func (m *MockMQ) Subscribe(arg0 context.Context, arg1 *mq.Subscriber, arg2 string, arg3 *mq.SubscribeOptions, arg4 chan<- *mq.Message, arg5 chan<- error) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Subscribe", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(error)
@@ -90,3 +219,17 @@ func (mr *MockMQMockRecorder) UnSubscribe(arg0, arg1, arg2 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnSubscribe", reflect.TypeOf((*MockMQ)(nil).UnSubscribe), arg0, arg1, arg2)
 }
+
+// UpdateChannelConfig mocks base method.
+func (m *MockMQ) UpdateChannelConfig(arg0 context.Context, arg1 string, arg2 *mq.ChannelConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChannelConfig", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChannelConfig indicates an expected call of UpdateChannelConfig.
+func (mr *MockMQMockRecorder) UpdateChannelConfig(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannelConfig", reflect.TypeOf((*MockMQ)(nil).UpdateChannelConfig), arg0, arg1, arg2)
+}
@@ -0,0 +1,63 @@
+// pkg/mocks/mock_publish_stream.go
+
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+type PublishStreamMock struct {
+	grpc.ServerStream
+	ctx            context.Context
+	recvToServer   chan *pb.PublishRequest
+	sentFromServer chan *pb.PublishStreamResponse
+}
+
+func NewPublishStreamMock(ctx context.Context, reqs []*pb.PublishRequest) *PublishStreamMock {
+	m := &PublishStreamMock{
+		ctx:            ctx,
+		recvToServer:   make(chan *pb.PublishRequest, len(reqs)),
+		sentFromServer: make(chan *pb.PublishStreamResponse, len(reqs)),
+	}
+
+	for _, req := range reqs {
+		m.recvToServer <- req
+	}
+	close(m.recvToServer)
+
+	return m
+}
+
+func (m *PublishStreamMock) Context() context.Context {
+	return m.ctx
+}
+
+func (m *PublishStreamMock) Recv() (*pb.PublishRequest, error) {
+	req, ok := <-m.recvToServer
+	if !ok {
+		return nil, io.EOF
+	}
+
+	return req, nil
+}
+
+func (m *PublishStreamMock) Send(res *pb.PublishStreamResponse) error {
+	m.sentFromServer <- res
+	return nil
+}
+
+func (m *PublishStreamMock) Sent() []*pb.PublishStreamResponse {
+	close(m.sentFromServer)
+
+	sent := make([]*pb.PublishStreamResponse, 0, len(m.sentFromServer))
+	for res := range m.sentFromServer {
+		sent = append(sent, res)
+	}
+
+	return sent
+}
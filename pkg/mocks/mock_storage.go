@@ -48,6 +48,20 @@ func (mr *MockStorageMockRecorder) ChannelExists(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelExists", reflect.TypeOf((*MockStorage)(nil).ChannelExists), arg0)
 }
 
+// CommitOffset mocks base method.
+func (m *MockStorage) CommitOffset(arg0, arg1 string, arg2 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitOffset", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitOffset indicates an expected call of CommitOffset.
+func (mr *MockStorageMockRecorder) CommitOffset(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitOffset", reflect.TypeOf((*MockStorage)(nil).CommitOffset), arg0, arg1, arg2)
+}
+
 // CreateChannel mocks base method.
 func (m *MockStorage) CreateChannel(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -62,6 +76,64 @@ func (mr *MockStorageMockRecorder) CreateChannel(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockStorage)(nil).CreateChannel), arg0)
 }
 
+// DeleteChannel mocks base method.
+func (m *MockStorage) DeleteChannel(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChannel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChannel indicates an expected call of DeleteChannel.
+func (mr *MockStorageMockRecorder) DeleteChannel(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannel", reflect.TypeOf((*MockStorage)(nil).DeleteChannel), arg0)
+}
+
+// GetChannelConfig mocks base method.
+func (m *MockStorage) GetChannelConfig(arg0 string) (*mq.ChannelConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelConfig", arg0)
+	ret0, _ := ret[0].(*mq.ChannelConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelConfig indicates an expected call of GetChannelConfig.
+func (mr *MockStorageMockRecorder) GetChannelConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelConfig", reflect.TypeOf((*MockStorage)(nil).GetChannelConfig), arg0)
+}
+
+// GetChannelStats mocks base method.
+func (m *MockStorage) GetChannelStats(arg0 string) (*mq.ChannelStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelStats", arg0)
+	ret0, _ := ret[0].(*mq.ChannelStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelStats indicates an expected call of GetChannelStats.
+func (mr *MockStorageMockRecorder) GetChannelStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelStats", reflect.TypeOf((*MockStorage)(nil).GetChannelStats), arg0)
+}
+
+// GetChannels mocks base method.
+func (m *MockStorage) GetChannels() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannels")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetChannels indicates an expected call of GetChannels.
+func (mr *MockStorageMockRecorder) GetChannels() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannels", reflect.TypeOf((*MockStorage)(nil).GetChannels))
+}
+
 // GetMessages mocks base method.
 func (m *MockStorage) GetMessages(arg0, arg1 string, arg2 uint64) ([]*mq.Message, uint64, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +150,35 @@ func (mr *MockStorageMockRecorder) GetMessages(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockStorage)(nil).GetMessages), arg0, arg1, arg2)
 }
 
+// GetOffsetByTime mocks base method.
+func (m *MockStorage) GetOffsetByTime(arg0 string, arg1 int64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOffsetByTime", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOffsetByTime indicates an expected call of GetOffsetByTime.
+func (mr *MockStorageMockRecorder) GetOffsetByTime(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOffsetByTime", reflect.TypeOf((*MockStorage)(nil).GetOffsetByTime), arg0, arg1)
+}
+
+// PurgeChannel mocks base method.
+func (m *MockStorage) PurgeChannel(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeChannel", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeChannel indicates an expected call of PurgeChannel.
+func (mr *MockStorageMockRecorder) PurgeChannel(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeChannel", reflect.TypeOf((*MockStorage)(nil).PurgeChannel), arg0)
+}
+
 // RemoveChannelFromSubscriberMap mocks base method.
 func (m *MockStorage) RemoveChannelFromSubscriberMap(arg0, arg1 string) {
 	m.ctrl.T.Helper()
@@ -104,3 +205,61 @@ func (mr *MockStorageMockRecorder) SaveMessage(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMessage", reflect.TypeOf((*MockStorage)(nil).SaveMessage), arg0, arg1)
 }
+
+// SaveMessages mocks base method.
+func (m *MockStorage) SaveMessages(arg0 []*mq.WalEntry) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMessages", arg0)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveMessages indicates an expected call of SaveMessages.
+func (mr *MockStorageMockRecorder) SaveMessages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMessages", reflect.TypeOf((*MockStorage)(nil).SaveMessages), arg0)
+}
+
+// SetChannelConfig mocks base method.
+func (m *MockStorage) SetChannelConfig(arg0 string, arg1 *mq.ChannelConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetChannelConfig", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChannelConfig indicates an expected call of SetChannelConfig.
+func (mr *MockStorageMockRecorder) SetChannelConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChannelConfig", reflect.TypeOf((*MockStorage)(nil).SetChannelConfig), arg0, arg1)
+}
+
+// WatchChannel mocks base method.
+func (m *MockStorage) WatchChannel(arg0 string) (<-chan struct{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchChannel", arg0)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchChannel indicates an expected call of WatchChannel.
+func (mr *MockStorageMockRecorder) WatchChannel(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchChannel", reflect.TypeOf((*MockStorage)(nil).WatchChannel), arg0)
+}
+
+// WatchChannels mocks base method.
+func (m *MockStorage) WatchChannels() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchChannels")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// WatchChannels indicates an expected call of WatchChannels.
+func (mr *MockStorageMockRecorder) WatchChannels() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchChannels", reflect.TypeOf((*MockStorage)(nil).WatchChannels))
+}
@@ -0,0 +1,141 @@
+// pkg/mq/ack.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+// delivery is a message delivered through a subscription, identified by its ack id
+type delivery struct {
+	subscription *subscription
+	messageID    string
+}
+
+// resolveAckIDs returns the deliveries the ack ids refer to, all of them are resolved
+// before any is acknowledged so that a request is either applied entirely or not at all
+func (s *Service) resolveAckIDs(
+	channel string,
+	ackIDs []string,
+) ([]delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := make([]delivery, 0, len(ackIDs))
+	for _, id := range ackIDs {
+		cursorID, messageID, err := parseAckID(id)
+		if err != nil {
+			slog.Error(
+				"invalid ack id",
+				slog.String("channel", channel),
+				slog.String("ack_id", id),
+			)
+			return nil, status.Error(codes.InvalidArgument, ErrInvalidAckID.Error())
+		}
+
+		subscription, exists := s.findSubscription(channel, cursorID, messageID)
+		if !exists {
+			slog.Error(
+				"subscription does not exist",
+				slog.String("channel", channel),
+				slog.String("ack_id", id),
+			)
+			return nil, status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error())
+		}
+
+		deliveries = append(deliveries, delivery{
+			subscription: subscription,
+			messageID:    messageID,
+		})
+	}
+
+	return deliveries, nil
+}
+
+// findSubscription returns the subscription with the cursor to the channel, for a partitioned channel
+// the subscription to the partition the message is in flight in
+func (s *Service) findSubscription(
+	channel string,
+	cursorID string,
+	messageID string,
+) (*subscription, bool) {
+	n := s.partitions(channel)
+
+	var found *subscription
+	for p := uint32(0); p < n; p++ {
+		subscription, exists := s.subscriptions[subscriptionKey{
+			channel:  storage.PartitionChannel(channel, p),
+			cursorID: cursorID,
+		}]
+		if !exists {
+			continue
+		}
+		if n == 1 || subscription.isInflight(messageID) {
+			return subscription, true
+		}
+		if found == nil {
+			found = subscription
+		}
+	}
+
+	return found, found != nil
+}
+
+// Ack acknowledges the messages delivered with the specified ack ids, so that they are not redelivered.
+// Messages that are no longer in flight (already acknowledged, or redelivered) are ignored.
+func (s *Service) Ack(
+	ctx context.Context,
+	channel string,
+	ackIDs []string,
+) error {
+	deliveries, err := s.resolveAckIDs(channel, ackIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		d.subscription.ack(d.messageID)
+	}
+
+	slog.Info(
+		"messages acknowledged",
+		slog.String("channel", channel),
+		slog.Int("count", len(deliveries)),
+	)
+	return nil
+}
+
+type ackInput struct {
+	Channel string   `validate:"required"`
+	AckIDs  []string `validate:"required,min=1,dive,required"`
+}
+
+// gRPC implementation of the Ack method
+func (s *Server) Ack(
+	ctx context.Context,
+	req *pb.AckRequest,
+) (*pb.AckResponse, error) {
+	input := &ackInput{
+		Channel: req.GetChannel(),
+		AckIDs:  req.GetAckIds(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// Acknowledge the messages
+	if err := s.srv.Ack(ctx, input.Channel, input.AckIDs); err != nil {
+		return nil, err
+	}
+
+	return &pb.AckResponse{}, nil
+}
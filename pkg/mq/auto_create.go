@@ -0,0 +1,77 @@
+// pkg/mq/auto_create.go
+
+package mq
+
+import (
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// autoCreate reports whether a channel that does not exist is created when a message is published to it.
+// The policy of the longest namespace the channel belongs to (orders and orders.eu for orders.eu.created)
+// overrides the policy of the broker, channels with an invalid name are never created.
+func (s *Service) autoCreate(channel string) bool {
+	if !validChannelName(channel) {
+		return false
+	}
+
+	enabled := s.autoCreateChannels
+	longest := -1
+	for namespace, namespaceEnabled := range s.autoCreateNamespaces {
+		if len(namespace) > longest && strings.HasPrefix(channel, namespace+channelTokenSeparator) {
+			enabled = namespaceEnabled
+			longest = len(namespace)
+		}
+	}
+	return enabled
+}
+
+// ensureChannel checks that the channel exists before a message is published to it, and creates it
+// if the auto-creation policy allows it, the caller must hold the lock of the service
+func (s *Service) ensureChannel(channel string) error {
+	create, err := s.checkChannel(channel)
+	if err != nil || !create {
+		return err
+	}
+
+	return s.autoCreateChannel(channel)
+}
+
+// checkChannel checks that the channel exists before a message is published to it, or that the auto-creation
+// policy allows creating it, and reports whether it has to be created
+func (s *Service) checkChannel(channel string) (bool, error) {
+	if s.storage.ChannelExists(channel) {
+		return false, nil
+	}
+
+	if !s.autoCreate(channel) {
+		slog.Error(
+			"cannot publish to non-existent channel",
+			slog.String("channel", channel),
+		)
+		return false, status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error())
+	}
+
+	return true, nil
+}
+
+// autoCreateChannel creates a channel a message is published to, the caller must hold the lock of the service
+func (s *Service) autoCreateChannel(channel string) error {
+	if err := s.storage.CreateChannel(channel); err != nil {
+		slog.Error(
+			"failed to auto-create channel",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error())
+	}
+
+	slog.Info(
+		"channel auto-created",
+		slog.String("channel", channel),
+	)
+	return nil
+}
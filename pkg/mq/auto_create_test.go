@@ -0,0 +1,225 @@
+// pkg/mq/auto_create_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestAutoCreate(t *testing.T) {
+	service := NewService(
+		&ServiceOptions{
+			Storage:            nil,
+			AutoCreateChannels: true,
+			AutoCreateNamespaces: map[string]bool{
+				"orders":    false,
+				"orders.eu": true,
+				"tmp":       false,
+			},
+		},
+	)
+
+	tests := []struct {
+		channel string
+		want    bool
+	}{
+		{channel: "test-channel", want: true},
+		{channel: "orders", want: true},
+		{channel: "orders.created", want: false},
+		{channel: "orders.eu", want: false},
+		{channel: "orders.eu.created", want: true},
+		{channel: "orders.us.created", want: false},
+		{channel: "ordersx.created", want: true},
+		{channel: "tmp.test", want: false},
+		{channel: "test.*", want: false},
+		{channel: "test.>", want: false},
+		{channel: storage.PartitionChannel("test-channel", 1), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			assert.Equal(t, tt.want, service.autoCreate(tt.channel))
+		})
+	}
+
+	// The namespaces can enable auto-creation when the broker does not
+	service = NewService(
+		&ServiceOptions{
+			Storage:              nil,
+			AutoCreateChannels:   false,
+			AutoCreateNamespaces: map[string]bool{"events": true},
+		},
+	)
+	assert.False(t, service.autoCreate("test-channel"))
+	assert.True(t, service.autoCreate("events.created"))
+}
+
+func TestPublishAutoCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage:              mockStorage,
+			AutoCreateChannels:   true,
+			AutoCreateNamespaces: map[string]bool{"orders": false},
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		channel string
+		setup   func()
+		err     error
+	}{
+		{
+			name:    "error: auto-creation disabled for the namespace",
+			channel: "orders.created",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("orders.created").
+					Return(false)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:    "error: invalid channel name",
+			channel: "test.*",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test.*").
+					Return(false)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:    "error: failed to create channel",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel("test-channel").
+					Return(storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error()),
+		},
+		{
+			name:    "success: channel created and message saved",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel("test-channel").
+					Return(nil)
+				mockStorage.EXPECT().
+					SaveMessage("test-channel", gomock.Any()).
+					Return(uint64(0), nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			_, err := service.Publish(ctx, tt.channel, &pb.Message{
+				Id:      "unique-message-id",
+				Content: []byte("test-content"),
+			})
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestPublishBatchAutoCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage:              mockStorage,
+			AutoCreateChannels:   true,
+			AutoCreateNamespaces: map[string]bool{"orders": false},
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		channels []string
+		setup    func()
+		err      error
+	}{
+		{
+			name:     "error: a later channel cannot be created, none is",
+			channels: []string{"test-channel", "orders.created"},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(false)
+				mockStorage.EXPECT().
+					ChannelExists("orders.created").
+					Return(false)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:     "success: channel created once and messages saved",
+			channels: []string{"test-channel", "test-channel"},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(false).
+					Times(2)
+				mockStorage.EXPECT().
+					CreateChannel("test-channel").
+					Return(nil)
+				mockStorage.EXPECT().
+					SaveMessages(gomock.Any()).
+					Return([]uint64{0, 1}, nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			entries := make([]*pb.WalEntry, 0, len(tt.channels))
+			for _, channel := range tt.channels {
+				entries = append(entries, &pb.WalEntry{
+					Channel: channel,
+					Message: &pb.Message{
+						Id:      "unique-message-id",
+						Content: []byte("test-content"),
+					},
+				})
+			}
+			_, err := service.PublishBatch(ctx, entries)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
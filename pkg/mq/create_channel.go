@@ -10,13 +10,24 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
 )
 
-// CreateChannel creates a new channel, if it doesn't already exist else joins the existing channel
+// CreateChannel creates a new channel with the specified configuration, if it doesn't already exist
+// else joins the existing channel (the configuration of an existing channel is left untouched)
 func (s *Service) CreateChannel(
 	ctx context.Context,
 	channel string,
+	config *pb.ChannelConfig,
 ) error {
+	if !validChannelName(channel) || !validChannelName(config.GetDeadLetterChannel()) {
+		slog.Error(
+			"cannot create channel with invalid name",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error())
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -39,6 +50,31 @@ func (s *Service) CreateChannel(
 		return status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error())
 	}
 
+	// Create the other partitions of the channel, the first one is stored under the name of the channel itself
+	for p := uint32(1); p < config.GetPartitions(); p++ {
+		if err := s.storage.CreateChannel(storage.PartitionChannel(channel, p)); err != nil {
+			slog.Error(
+				"failed to create partition",
+				slog.String("channel", channel),
+				slog.Uint64("partition", uint64(p)),
+				slog.Any("error", err),
+			)
+			return status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error())
+		}
+	}
+
+	// Persist the configuration of the channel, if any
+	if config != nil {
+		if err := s.storage.SetChannelConfig(channel, config); err != nil {
+			slog.Error(
+				"failed to set channel config",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+			return status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error())
+		}
+	}
+
 	slog.Info(
 		"channel created",
 		slog.String("channel", channel),
@@ -47,8 +83,23 @@ func (s *Service) CreateChannel(
 	return nil
 }
 
-type createChannelInput struct {
-	Channel string `validate:"required"`
+type channelConfigInput struct {
+	Channel             string        `validate:"required"`
+	MaxDeliveryAttempts uint32        `validate:"required_with=DeadLetterChannel"`
+	DeadLetterChannel   string        `validate:"required_with=MaxDeliveryAttempts,omitempty,nefield=Channel"`
+	Partitions          uint32        `validate:"lte=256"`
+	Durability          pb.Durability `validate:"durability"`
+}
+
+// newChannelConfigInput returns the input of a request creating a channel, or updating the configuration of a channel
+func newChannelConfigInput(channel string, config *pb.ChannelConfig) *channelConfigInput {
+	return &channelConfigInput{
+		Channel:             channel,
+		MaxDeliveryAttempts: config.GetMaxDeliveryAttempts(),
+		DeadLetterChannel:   config.GetDeadLetterChannel(),
+		Partitions:          config.GetPartitions(),
+		Durability:          config.GetDurability(),
+	}
 }
 
 // gRPC implementation of the CreateChannel method
@@ -56,9 +107,7 @@ func (s *Server) CreateChannel(
 	ctx context.Context,
 	req *pb.CreateChannelRequest,
 ) (*pb.CreateChannelResponse, error) {
-	input := &createChannelInput{
-		Channel: req.GetChannel(),
-	}
+	input := newChannelConfigInput(req.GetChannel(), req.GetConfig())
 
 	// Validate the input request
 	if err := s.validator.ValidateStruct(input); err != nil {
@@ -66,7 +115,7 @@ func (s *Server) CreateChannel(
 	}
 
 	// Create a new channel
-	if err := s.srv.CreateChannel(ctx, input.Channel); err != nil {
+	if err := s.srv.CreateChannel(ctx, input.Channel, req.GetConfig()); err != nil {
 		return nil, err
 	}
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/hitesh22rana/mq/pkg/mocks"
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
 )
 
 func TestCreateChannelService(t *testing.T) {
@@ -29,11 +30,16 @@ func TestCreateChannelService(t *testing.T) {
 
 	ctx := context.Background()
 	channel := "test-channel"
+	config := &pb.ChannelConfig{
+		MaxDeliveryAttempts: 5,
+		DeadLetterChannel:   "test-dead-letter-channel",
+	}
 
 	tests := []struct {
-		name  string
-		setup func()
-		err   error
+		name   string
+		config *pb.ChannelConfig
+		setup  func()
+		err    error
 	}{
 		{
 			name: "error: channel already exists",
@@ -44,6 +50,24 @@ func TestCreateChannelService(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "error: dead-letter channel with wildcards",
+			config: &pb.ChannelConfig{
+				MaxDeliveryAttempts: 5,
+				DeadLetterChannel:   "dead-letters.*",
+			},
+			setup: func() {},
+			err:   status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
+		{
+			name: "error: channel name with the partition separator",
+			config: &pb.ChannelConfig{
+				MaxDeliveryAttempts: 5,
+				DeadLetterChannel:   "dead-letters#1",
+			},
+			setup: func() {},
+			err:   status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
 		{
 			name: "error: create channel storage error",
 			setup: func() {
@@ -68,12 +92,82 @@ func TestCreateChannelService(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name:   "error: set channel config storage error",
+			config: config,
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel(channel).
+					Return(nil)
+				mockStorage.EXPECT().
+					SetChannelConfig(channel, config).
+					Return(storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error()),
+		},
+		{
+			name:   "success: create channel with config",
+			config: config,
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel(channel).
+					Return(nil)
+				mockStorage.EXPECT().
+					SetChannelConfig(channel, config).
+					Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:   "error: create partition storage error",
+			config: &pb.ChannelConfig{Partitions: 3},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel(channel).
+					Return(nil)
+				mockStorage.EXPECT().
+					CreateChannel(channel + "#1").
+					Return(storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error()),
+		},
+		{
+			name:   "success: create channel with partitions",
+			config: &pb.ChannelConfig{Partitions: 3},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel(channel).
+					Return(nil)
+				mockStorage.EXPECT().
+					CreateChannel(channel + "#1").
+					Return(nil)
+				mockStorage.EXPECT().
+					CreateChannel(channel + "#2").
+					Return(nil)
+				mockStorage.EXPECT().
+					SetChannelConfig(channel, &pb.ChannelConfig{Partitions: 3}).
+					Return(nil)
+			},
+			err: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setup()
-			err := service.CreateChannel(ctx, channel)
+			err := service.CreateChannel(ctx, channel, tt.config)
 			assert.Equal(t, tt.err, err)
 		})
 	}
@@ -126,7 +220,7 @@ func TestCreateChannelServer(t *testing.T) {
 					ValidateStruct(gomock.Any()).
 					Return(nil)
 				mockService.EXPECT().
-					CreateChannel(ctx, channel).
+					CreateChannel(ctx, channel, nil).
 					Return(nil)
 			},
 			err: nil,
@@ -141,7 +235,7 @@ func TestCreateChannelServer(t *testing.T) {
 					ValidateStruct(gomock.Any()).
 					Return(nil)
 				mockService.EXPECT().
-					CreateChannel(ctx, channel).
+					CreateChannel(ctx, channel, nil).
 					Return(status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error()))
 			},
 			err: status.Error(codes.Unavailable, ErrUnableToCreateChannel.Error()),
@@ -156,7 +250,7 @@ func TestCreateChannelServer(t *testing.T) {
 					ValidateStruct(gomock.Any()).
 					Return(nil)
 				mockService.EXPECT().
-					CreateChannel(ctx, channel).
+					CreateChannel(ctx, channel, nil).
 					Return(nil)
 			},
 			err: nil,
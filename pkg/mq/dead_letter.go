@@ -0,0 +1,77 @@
+// pkg/mq/dead_letter.go
+
+package mq
+
+import (
+	"log/slog"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// deadLetter returns the function moving the messages of the channel that exceed its max delivery
+// attempts to its dead-letter channel, the configuration of the channel is read on every failure
+func (s *Service) deadLetter(channel string) deadLetterFunc {
+	return func(msg *pb.Message, attempts uint32, reason string) bool {
+		config, err := s.storage.GetChannelConfig(channel)
+		if err != nil {
+			return false
+		}
+
+		// Keep redelivering the message until it exceeds the max delivery attempts
+		maxDeliveryAttempts := config.GetMaxDeliveryAttempts()
+		deadLetterChannel := config.GetDeadLetterChannel()
+		if maxDeliveryAttempts == 0 || attempts < maxDeliveryAttempts || deadLetterChannel == "" {
+			return false
+		}
+
+		// The dead-letter channel is created with the first message moved to it, whatever the auto-creation policy
+		if !s.storage.ChannelExists(deadLetterChannel) {
+			if err := s.storage.CreateChannel(deadLetterChannel); err != nil {
+				slog.Error(
+					"failed to create dead-letter channel, redelivering message",
+					slog.String("channel", channel),
+					slog.String("dead_letter_channel", deadLetterChannel),
+					slog.Any("error", err),
+				)
+				return false
+			}
+		}
+
+		// Save the message to the dead-letter channel, along with why it ended up there
+		deadMsg := &pb.Message{
+			Id:        msg.GetId(),
+			Content:   msg.GetContent(),
+			CreatedAt: msg.GetCreatedAt(),
+			Key:       msg.GetKey(),
+			Headers:   msg.GetHeaders(),
+			DeadLetter: &pb.DeadLetter{
+				Channel:          channel,
+				DeliveryAttempts: attempts,
+				Reason:           reason,
+				Offset:           msg.GetOffset(),
+				Partition:        msg.GetPartition(),
+			},
+		}
+		s.expire(deadLetterChannel, deadMsg)
+		if _, err := s.storage.SaveMessage(s.route(deadLetterChannel, deadMsg), deadMsg); err != nil {
+			slog.Error(
+				"failed to dead-letter message, redelivering it",
+				slog.String("channel", channel),
+				slog.String("dead_letter_channel", deadLetterChannel),
+				slog.String("message", msg.GetId()),
+				slog.Any("error", err),
+			)
+			return false
+		}
+
+		slog.Warn(
+			"message dead-lettered",
+			slog.String("channel", channel),
+			slog.String("dead_letter_channel", deadLetterChannel),
+			slog.String("message", msg.GetId()),
+			slog.Uint64("attempts", uint64(attempts)),
+			slog.String("reason", reason),
+		)
+		return true
+	}
+}
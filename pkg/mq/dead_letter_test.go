@@ -0,0 +1,209 @@
+// pkg/mq/dead_letter_test.go
+
+package mq
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	// The dead-letter channel has a single partition
+	mockStorage.EXPECT().
+		GetChannelConfig("test-dead-letter-channel").
+		Return(&pb.ChannelConfig{}, nil).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	channel := "test-channel"
+	deadLetterChannel := "test-dead-letter-channel"
+	reason := "test-reason"
+	config := &pb.ChannelConfig{
+		MaxDeliveryAttempts: 3,
+		DeadLetterChannel:   deadLetterChannel,
+	}
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+		Offset:    uint64(42),
+	}
+	deadMsg := &pb.Message{
+		Id:        msg.GetId(),
+		Content:   msg.GetContent(),
+		CreatedAt: msg.GetCreatedAt(),
+		DeadLetter: &pb.DeadLetter{
+			Channel:          channel,
+			DeliveryAttempts: 3,
+			Reason:           reason,
+			Offset:           uint64(42),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		attempts uint32
+		setup    func()
+		want     bool
+	}{
+		{
+			name:     "redeliver: channel does not exist",
+			attempts: 3,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(nil, errors.New("channel does not exist"))
+			},
+			want: false,
+		},
+		{
+			name:     "redeliver: no max delivery attempts",
+			attempts: 3,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(&pb.ChannelConfig{}, nil)
+			},
+			want: false,
+		},
+		{
+			name:     "redeliver: max delivery attempts not reached",
+			attempts: 2,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(config, nil)
+			},
+			want: false,
+		},
+		{
+			name:     "redeliver: failed to create the dead-letter channel",
+			attempts: 3,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(config, nil)
+				mockStorage.EXPECT().
+					ChannelExists(deadLetterChannel).
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel(deadLetterChannel).
+					Return(storage.ErrInternal)
+			},
+			want: false,
+		},
+		{
+			name:     "redeliver: failed to save to the dead-letter channel",
+			attempts: 3,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(config, nil)
+				mockStorage.EXPECT().
+					ChannelExists(deadLetterChannel).
+					Return(true)
+				mockStorage.EXPECT().
+					SaveMessage(deadLetterChannel, deadMsg).
+					Return(uint64(0), storage.ErrInternal)
+			},
+			want: false,
+		},
+		{
+			name:     "success: message dead-lettered",
+			attempts: 3,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(config, nil)
+				mockStorage.EXPECT().
+					ChannelExists(deadLetterChannel).
+					Return(true)
+				mockStorage.EXPECT().
+					SaveMessage(deadLetterChannel, deadMsg).
+					Return(uint64(1), nil)
+			},
+			want: true,
+		},
+		{
+			name:     "success: message dead-lettered to a new dead-letter channel",
+			attempts: 3,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig(channel).
+					Return(config, nil)
+				mockStorage.EXPECT().
+					ChannelExists(deadLetterChannel).
+					Return(false)
+				mockStorage.EXPECT().
+					CreateChannel(deadLetterChannel).
+					Return(nil)
+				mockStorage.EXPECT().
+					SaveMessage(deadLetterChannel, deadMsg).
+					Return(uint64(0), nil)
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got := service.deadLetter(channel)(msg, tt.attempts, reason)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSubscriptionDeadLetter(t *testing.T) {
+	key := subscriptionKey{
+		channel:  "test-channel",
+		cursorID: "unique-subscriber-id",
+	}
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+	}
+
+	// Dead-letter the message on its second failure
+	var failures []string
+	sub := newSubscription(
+		key,
+		time.Minute,
+		nil,
+		func(m *pb.Message, attempts uint32, reason string) bool {
+			failures = append(failures, reason)
+			return attempts >= 2
+		},
+		nil,
+	)
+
+	sub.deliver(msg)
+	sub.fail(msg.GetId(), reasonNacked)
+	assert.Equal(t, []*pb.Message{msg}, sub.takePending())
+
+	sub.deliver(msg)
+	sub.fail(msg.GetId(), "test-reason")
+	assert.Empty(t, sub.takePending())
+	assert.Empty(t, sub.inflight)
+	assert.Empty(t, sub.attempts)
+	assert.Equal(t, []string{reasonNacked, "test-reason"}, failures)
+}
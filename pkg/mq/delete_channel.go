@@ -0,0 +1,121 @@
+// pkg/mq/delete_channel.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+// DeleteChannel deletes the channel along with its partitions, its messages and the cursors of its subscribers.
+// The subscribers of the channel are disconnected with the error that the channel was deleted, the subscribers of
+// a pattern matching it stay connected to the other matching channels. A channel created later on under the same
+// name starts out empty, and is subscribed to again by the subscribers of the patterns matching it.
+func (s *Service) DeleteChannel(
+	ctx context.Context,
+	channel string,
+) error {
+	if !validChannelName(channel) {
+		slog.Error(
+			"cannot delete channel with invalid name",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.storage.ChannelExists(channel) {
+		slog.Error(
+			"cannot delete non-existent channel",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.NotFound, ErrChannelDoesNotExist.Error())
+	}
+
+	// The partitions are only known until the channel is deleted
+	n := s.partitions(channel)
+
+	if err := s.storage.DeleteChannel(channel); err != nil {
+		slog.Error(
+			"failed to delete channel",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return status.Error(codes.Unavailable, ErrUnableToDeleteChannel.Error())
+	}
+
+	s.endSubscriptions(channel, n)
+
+	slog.Info(
+		"channel deleted",
+		slog.String("channel", channel),
+	)
+
+	return nil
+}
+
+// endSubscriptions ends the subscriptions to the n partitions of the deleted channel, the subscriptions of consumer groups
+// and durable subscriptions are not kept. The members subscribed to the channel itself are told it was deleted, the
+// members subscribed through a pattern only stop forwarding it and subscribe to it again if it is created again.
+// The caller must hold the lock of the service.
+func (s *Service) endSubscriptions(channel string, n uint32) {
+	err := status.Error(codes.NotFound, ErrChannelDeleted.Error())
+	for p := uint32(0); p < n; p++ {
+		partitionChannel := storage.PartitionChannel(channel, p)
+		for key, subscription := range s.subscriptions {
+			if key.channel != partitionChannel {
+				continue
+			}
+
+			subscription.failWith(err)
+			subscription.cancel()
+			subscription.stop()
+			delete(s.subscriptions, key)
+		}
+		delete(s.channelToSubscribers, partitionChannel)
+	}
+
+	for key := range s.partitionGroups {
+		if key.channel == channel {
+			delete(s.partitionGroups, key)
+		}
+	}
+
+	for _, ws := range s.wildcards {
+		delete(ws.channels, channel)
+	}
+}
+
+type deleteChannelInput struct {
+	Channel string `validate:"required"`
+}
+
+// gRPC implementation of the DeleteChannel method
+func (s *Server) DeleteChannel(
+	ctx context.Context,
+	req *pb.DeleteChannelRequest,
+) (*pb.DeleteChannelResponse, error) {
+	input := &deleteChannelInput{
+		Channel: req.GetChannel(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// Delete the channel
+	if err := s.srv.DeleteChannel(ctx, input.Channel); err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteChannelResponse{}, nil
+}
@@ -0,0 +1,364 @@
+// pkg/mq/delete_channel_test.go
+
+package mq
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestDeleteChannelService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		channel string
+		setup   func()
+		err     error
+	}{
+		{
+			name:    "error: channel name with the partition separator",
+			channel: "test-channel#1",
+			setup:   func() {},
+			err:     status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
+		{
+			name:    "error: channel does not exist",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(false)
+			},
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:    "error: delete channel storage error",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(true)
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{}, nil)
+				mockStorage.EXPECT().
+					DeleteChannel("test-channel").
+					Return(storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToDeleteChannel.Error()),
+		},
+		{
+			name:    "success: channel deleted",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(true)
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{Partitions: 3}, nil)
+				mockStorage.EXPECT().
+					DeleteChannel("test-channel").
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			err := service.DeleteChannel(ctx, tt.channel)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestDeleteChannelDisconnectsSubscribers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	sub := &pb.Subscriber{
+		Id:    "unique-subscriber-id",
+		Ip:    "ip-address",
+		Group: "test-group",
+	}
+	channel := "test-channel"
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	// The channel has nothing to read
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true)
+	mockStorage.EXPECT().
+		WatchChannel(channel).
+		Return((<-chan struct{})(make(chan struct{})), nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetMessages(channel, cursorID(sub), OffsetBeginning).
+		Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+		AnyTimes()
+
+	err := service.Subscribe(
+		ctx,
+		sub,
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		errChan,
+	)
+	assert.NoError(t, err)
+
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true)
+	mockStorage.EXPECT().
+		DeleteChannel(channel).
+		Return(nil)
+	assert.NoError(t, service.DeleteChannel(ctx, channel))
+
+	select {
+	case err := <-errChan:
+		assert.Equal(t, status.Error(codes.NotFound, ErrChannelDeleted.Error()), err)
+	case <-time.After(time.Second):
+		t.Fatal("the deletion of the channel was not reported")
+	}
+
+	// The subscription of the group is not kept, so that the group starts over if the channel is created again
+	assert.Empty(t, service.subscriptions)
+	assert.Empty(t, service.channelToSubscribers)
+}
+
+func TestDeleteChannelKeepsWildcardSubscribers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := &pb.Subscriber{
+		Id: "unique-subscriber-id",
+		Ip: "ip-address",
+	}
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	// The matching channels have nothing to read, until a message is published to orders.us
+	idle := make(chan struct{})
+	notify := make(chan struct{}, 1)
+	var deleted, published atomic.Bool
+	mockStorage.EXPECT().
+		GetChannels().
+		DoAndReturn(func() []string {
+			if deleted.Load() {
+				return []string{"orders.us"}
+			}
+			return []string{"orders.eu", "orders.us"}
+		}).
+		AnyTimes()
+	mockStorage.EXPECT().
+		WatchChannels().
+		Return((<-chan struct{})(idle)).
+		AnyTimes()
+	mockStorage.EXPECT().
+		WatchChannel("orders.eu").
+		Return((<-chan struct{})(idle), nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		WatchChannel("orders.us").
+		Return((<-chan struct{})(notify), nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetMessages("orders.eu", sub.GetId(), gomock.Any()).
+		Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetMessages("orders.us", sub.GetId(), gomock.Any()).
+		DoAndReturn(func(channel string, cursorID string, offset uint64) ([]*pb.Message, uint64, error) {
+			if published.CompareAndSwap(true, false) {
+				return []*pb.Message{{Id: "unique-message-id"}}, uint64(0), nil
+			}
+			return nil, uint64(0), storage.ErrInvalidOffset
+		}).
+		AnyTimes()
+
+	err := service.Subscribe(
+		ctx,
+		sub,
+		"orders.*",
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		errChan,
+	)
+	assert.NoError(t, err)
+
+	mockStorage.EXPECT().
+		ChannelExists("orders.eu").
+		Return(true)
+	mockStorage.EXPECT().
+		DeleteChannel("orders.eu").
+		DoAndReturn(func(channel string) error {
+			deleted.Store(true)
+			return nil
+		})
+	assert.NoError(t, service.DeleteChannel(ctx, "orders.eu"))
+
+	// The subscriber is still forwarded the messages of the other matching channel
+	published.Store(true)
+	notify <- struct{}{}
+	select {
+	case got := <-msgChan:
+		assert.Equal(t, "orders.us", got.GetChannel())
+	case err := <-errChan:
+		t.Fatalf("the deletion of a matching channel ended the subscription: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("message of the other matching channel was not pushed")
+	}
+
+	service.mu.Lock()
+	assert.Equal(t, map[string]struct{}{"orders.us": {}}, service.wildcards[sub].channels)
+	assert.Len(t, service.subscriptions, 1)
+	service.mu.Unlock()
+
+	mockStorage.EXPECT().
+		RemoveChannelFromSubscriberMap("orders.us", sub.GetId()).
+		Return()
+	cancel()
+	assert.NoError(t, service.UnSubscribe(ctx, sub, "orders.*"))
+	assert.Empty(t, errChan)
+}
+
+func TestDeleteChannelServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+
+	tests := []struct {
+		name  string
+		req   *pb.DeleteChannelRequest
+		setup func()
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.DeleteChannelRequest{
+				Channel: "",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: channel does not exist",
+			req: &pb.DeleteChannelRequest{
+				Channel: channel,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					DeleteChannel(ctx, channel).
+					Return(status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()))
+			},
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name: "success: channel deleted",
+			req: &pb.DeleteChannelRequest{
+				Channel: channel,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					DeleteChannel(ctx, channel).
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			_, err := server.DeleteChannel(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
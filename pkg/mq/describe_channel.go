@@ -0,0 +1,128 @@
+// pkg/mq/describe_channel.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+// DescribeChannel returns the configuration of the channel, along with the messages and the subscribers of each of its
+// partitions. The offset of a subscriber is the offset its cursor reads the partition from next, 0 until it has read it.
+func (s *Service) DescribeChannel(
+	ctx context.Context,
+	channel string,
+) (*pb.DescribeChannelResponse, error) {
+	if !validChannelName(channel) {
+		slog.Error(
+			"cannot describe channel with invalid name",
+			slog.String("channel", channel),
+		)
+		return nil, status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config, err := s.storage.GetChannelConfig(channel)
+	if err != nil {
+		slog.Error(
+			"cannot describe non-existent channel",
+			slog.String("channel", channel),
+		)
+		return nil, status.Error(codes.NotFound, ErrChannelDoesNotExist.Error())
+	}
+
+	n := s.partitions(channel)
+	description := &pb.DescribeChannelResponse{
+		Channel:      channel,
+		Config:       config,
+		MessageCount: 0,
+		SizeBytes:    0,
+		Partitions:   make([]*pb.PartitionDescription, 0, n),
+	}
+	for p := uint32(0); p < n; p++ {
+		partitionChannel := storage.PartitionChannel(channel, p)
+		stats, err := s.storage.GetChannelStats(partitionChannel)
+		if err != nil {
+			slog.Error(
+				"failed to get channel stats",
+				slog.String("channel", partitionChannel),
+				slog.Any("error", err),
+			)
+			return nil, status.Error(codes.Unavailable, ErrUnableToDescribeChannel.Error())
+		}
+
+		description.MessageCount += stats.GetMessageCount()
+		description.SizeBytes += stats.GetSizeBytes()
+		description.Partitions = append(description.Partitions, &pb.PartitionDescription{
+			Partition:   p,
+			Stats:       stats,
+			Subscribers: s.describeSubscribers(partitionChannel),
+		})
+	}
+
+	return description, nil
+}
+
+// describeSubscribers returns the subscribers of the channel (or of a partition of a channel) in the order
+// they subscribed in, the caller must hold the lock of the service
+func (s *Service) describeSubscribers(channel string) []*pb.SubscriberDescription {
+	subscribers := make([]*pb.SubscriberDescription, 0, len(s.channelToSubscribers[channel]))
+	for sub, subscribedAt := range s.channelToSubscribers[channel] {
+		key := subscriptionKey{
+			channel:  channel,
+			cursorID: cursorID(sub),
+		}
+
+		var offset uint64
+		if subscription, exists := s.subscriptions[key]; exists {
+			offset = subscription.nextOffset()
+		}
+
+		subscribers = append(subscribers, &pb.SubscriberDescription{
+			Subscriber:   sub,
+			SubscribedAt: subscribedAt.UnixMilli(),
+			Cursor:       key.cursorID,
+			Offset:       offset,
+		})
+	}
+
+	sort.Slice(subscribers, func(i, j int) bool {
+		if subscribers[i].GetSubscribedAt() != subscribers[j].GetSubscribedAt() {
+			return subscribers[i].GetSubscribedAt() < subscribers[j].GetSubscribedAt()
+		}
+		return subscribers[i].GetSubscriber().GetId() < subscribers[j].GetSubscriber().GetId()
+	})
+
+	return subscribers
+}
+
+type describeChannelInput struct {
+	Channel string `validate:"required"`
+}
+
+// gRPC implementation of the DescribeChannel method
+func (s *Server) DescribeChannel(
+	ctx context.Context,
+	req *pb.DescribeChannelRequest,
+) (*pb.DescribeChannelResponse, error) {
+	input := &describeChannelInput{
+		Channel: req.GetChannel(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// Describe the channel
+	return s.srv.DescribeChannel(ctx, input.Channel)
+}
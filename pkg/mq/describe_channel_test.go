@@ -0,0 +1,292 @@
+// pkg/mq/describe_channel_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestDescribeChannelService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	config := &pb.ChannelConfig{Partitions: 2, RetentionMessages: 100}
+	stats := &pb.ChannelStats{
+		MessageCount:        3,
+		FirstOffset:         2,
+		LastOffset:          4,
+		FirstCreatedAt:      100,
+		LastCreatedAt:       102,
+		NextOffset:          5,
+		SizeBytes:           60,
+		DelayedMessageCount: 1,
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		setup   func()
+		res     *pb.DescribeChannelResponse
+		err     error
+	}{
+		{
+			name:    "error: channel name with wildcards",
+			channel: "test-channel.*",
+			setup:   func() {},
+			res:     nil,
+			err:     status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
+		{
+			name:    "error: channel does not exist",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(nil, storage.ErrInternal)
+			},
+			res: nil,
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:    "error: get channel stats storage error",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(config, nil).
+					Times(2)
+				mockStorage.EXPECT().
+					GetChannelStats("test-channel").
+					Return(nil, storage.ErrInternal)
+			},
+			res: nil,
+			err: status.Error(codes.Unavailable, ErrUnableToDescribeChannel.Error()),
+		},
+		{
+			name:    "success: channel described",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(config, nil).
+					Times(2)
+				mockStorage.EXPECT().
+					GetChannelStats("test-channel").
+					Return(stats, nil)
+				mockStorage.EXPECT().
+					GetChannelStats("test-channel#1").
+					Return(&pb.ChannelStats{}, nil)
+			},
+			res: &pb.DescribeChannelResponse{
+				Channel:      "test-channel",
+				Config:       config,
+				MessageCount: 3,
+				SizeBytes:    60,
+				Partitions: []*pb.PartitionDescription{
+					{
+						Partition:   0,
+						Stats:       stats,
+						Subscribers: []*pb.SubscriberDescription{},
+					},
+					{
+						Partition:   1,
+						Stats:       &pb.ChannelStats{},
+						Subscribers: []*pb.SubscriberDescription{},
+					},
+				},
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			res, err := service.DescribeChannel(ctx, tt.channel)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+		})
+	}
+}
+
+func TestDescribeChannelSubscribers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	sub := &pb.Subscriber{
+		Id:    "unique-subscriber-id",
+		Ip:    "ip-address",
+		Group: "test-group",
+	}
+	channel := "test-channel"
+
+	// The subscriber reads the messages up to offset 4, and waits for the next ones
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true)
+	mockStorage.EXPECT().
+		WatchChannel(channel).
+		Return((<-chan struct{})(make(chan struct{})), nil).
+		AnyTimes()
+	gomock.InOrder(
+		mockStorage.EXPECT().
+			GetMessages(channel, cursorID(sub), OffsetBeginning).
+			Return([]*pb.Message{}, uint64(4), nil),
+		mockStorage.EXPECT().
+			GetMessages(channel, cursorID(sub), uint64(5)).
+			Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+			AnyTimes(),
+	)
+	mockStorage.EXPECT().
+		GetChannelStats(channel).
+		Return(&pb.ChannelStats{NextOffset: 5}, nil).
+		AnyTimes()
+
+	before := time.Now().UnixMilli()
+	err := service.Subscribe(
+		ctx,
+		sub,
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		make(chan *pb.Message),
+		make(chan error, 1),
+	)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		description, err := service.DescribeChannel(ctx, channel)
+		assert.NoError(t, err)
+		return description.GetPartitions()[0].GetSubscribers()[0].GetOffset() == 5
+	}, time.Second, 10*time.Millisecond)
+
+	description, err := service.DescribeChannel(ctx, channel)
+	assert.NoError(t, err)
+	subscribers := description.GetPartitions()[0].GetSubscribers()
+	assert.Len(t, subscribers, 1)
+	assert.Equal(t, sub, subscribers[0].GetSubscriber())
+	assert.Equal(t, cursorID(sub), subscribers[0].GetCursor())
+	assert.GreaterOrEqual(t, subscribers[0].GetSubscribedAt(), before)
+}
+
+func TestDescribeChannelServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	description := &pb.DescribeChannelResponse{
+		Channel: channel,
+	}
+
+	tests := []struct {
+		name  string
+		req   *pb.DescribeChannelRequest
+		setup func()
+		res   *pb.DescribeChannelResponse
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.DescribeChannelRequest{
+				Channel: "",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			res: nil,
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: channel does not exist",
+			req: &pb.DescribeChannelRequest{
+				Channel: channel,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					DescribeChannel(ctx, channel).
+					Return(nil, status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()))
+			},
+			res: nil,
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name: "success: channel described",
+			req: &pb.DescribeChannelRequest{
+				Channel: channel,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					DescribeChannel(ctx, channel).
+					Return(description, nil)
+			},
+			res: description,
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			res, err := server.DescribeChannel(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// pkg/mq/filter.go
+
+package mq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A filter expression selects messages by their headers, it is made of conditions on header
+// values combined with AND and OR (AND binds tighter), and grouped with parentheses:
+//
+//	type = 'order.created'
+//	type = order.created AND region IN ('eu', 'us')
+//	(type PREFIX 'order.' OR priority != low) AND tenant = acme
+//
+// Values are quoted with single or double quotes, or left bare when they only contain
+// letters, digits and the characters ._-/: . Keywords are case-insensitive.
+// A condition on a header the message does not have only matches for !=.
+
+// filter matches messages by their headers
+type filter interface {
+	match(headers map[string]string) bool
+}
+
+// andFilter matches the messages matched by all of its filters
+type andFilter []filter
+
+func (f andFilter) match(headers map[string]string) bool {
+	for _, operand := range f {
+		if !operand.match(headers) {
+			return false
+		}
+	}
+	return true
+}
+
+// orFilter matches the messages matched by any of its filters
+type orFilter []filter
+
+func (f orFilter) match(headers map[string]string) bool {
+	for _, operand := range f {
+		if operand.match(headers) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalFilter matches the messages whose header is equal to the value, or not equal if negated
+type equalFilter struct {
+	key    string
+	value  string
+	negate bool
+}
+
+func (f *equalFilter) match(headers map[string]string) bool {
+	value, exists := headers[f.key]
+	return (exists && value == f.value) != f.negate
+}
+
+// inFilter matches the messages whose header is equal to one of the values
+type inFilter struct {
+	key    string
+	values map[string]struct{}
+}
+
+func (f *inFilter) match(headers map[string]string) bool {
+	value, exists := headers[f.key]
+	if !exists {
+		return false
+	}
+
+	_, in := f.values[value]
+	return in
+}
+
+// prefixFilter matches the messages whose header starts with the prefix
+type prefixFilter struct {
+	key    string
+	prefix string
+}
+
+func (f *prefixFilter) match(headers map[string]string) bool {
+	value, exists := headers[f.key]
+	return exists && strings.HasPrefix(value, f.prefix)
+}
+
+// tokenKind is the kind of a token of a filter expression
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+)
+
+// token is a token of a filter expression, along with its position in the expression
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// isWordChar reports whether the character can be part of a bare word
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		strings.IndexByte("._-/:", c) >= 0
+}
+
+// tokenize splits a filter expression into tokens
+func tokenize(expr string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), pos: i})
+			i++
+		case c == '!':
+			if i+1 >= len(expr) || expr[i+1] != '=' {
+				return nil, fmt.Errorf("%w: unexpected '!' at position %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: "!=", pos: i})
+			i += 2
+		case c == '\'' || c == '"':
+			// Quoted strings end at the next matching quote, a backslash escapes the next character
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != c; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) {
+					j++
+				}
+				value.WriteByte(expr[j])
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidFilter, i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value.String(), pos: i})
+			i = j + 1
+		case isWordChar(c):
+			j := i
+			for j < len(expr) && isWordChar(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[i:j], pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidFilter, c, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// filterParser parses the tokens of a filter expression
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+// parseFilter parses a filter expression, an empty expression matches every message and returns a nil filter
+func parseFilter(expr string) (filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, p.unexpected(next)
+	}
+
+	return f, nil
+}
+
+// peek returns the next token without consuming it
+func (p *filterParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consumes the next token
+func (p *filterParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword reports whether the token is the specified keyword
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// isSymbol reports whether the token is the specified symbol
+func (t token) isSymbol(symbol string) bool {
+	return t.kind == tokenSymbol && t.text == symbol
+}
+
+// unexpected returns the error of an unexpected token
+func (p *filterParser) unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of expression", ErrInvalidFilter)
+	}
+	return fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidFilter, t.text, t.pos)
+}
+
+// parseOr parses conditions combined with OR
+func (p *filterParser) parseOr() (filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := orFilter{f}
+	for p.peek().isKeyword("OR") {
+		p.next()
+		f, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, f)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+// parseAnd parses conditions combined with AND
+func (p *filterParser) parseAnd() (filter, error) {
+	f, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := andFilter{f}
+	for p.peek().isKeyword("AND") {
+		p.next()
+		f, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, f)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+// parseCondition parses a condition on a header, or a parenthesized expression
+func (p *filterParser) parseCondition() (filter, error) {
+	if p.peek().isSymbol("(") {
+		p.next()
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); !t.isSymbol(")") {
+			return nil, p.unexpected(t)
+		}
+		return f, nil
+	}
+
+	key, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	switch {
+	case op.isSymbol("="), op.isSymbol("!="):
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return &equalFilter{key: key, value: value, negate: op.text == "!="}, nil
+	case op.isKeyword("PREFIX"):
+		prefix, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return &prefixFilter{key: key, prefix: prefix}, nil
+	case op.isKeyword("IN"):
+		return p.parseIn(key)
+	default:
+		return nil, p.unexpected(op)
+	}
+}
+
+// parseIn parses the parenthesized list of values of an IN condition
+func (p *filterParser) parseIn(key string) (filter, error) {
+	if t := p.next(); !t.isSymbol("(") {
+		return nil, p.unexpected(t)
+	}
+
+	f := &inFilter{key: key, values: make(map[string]struct{})}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		f.values[value] = struct{}{}
+
+		t := p.next()
+		if t.isSymbol(")") {
+			return f, nil
+		}
+		if !t.isSymbol(",") {
+			return nil, p.unexpected(t)
+		}
+	}
+}
+
+// parseValue parses a header key or value, either quoted or bare
+func (p *filterParser) parseValue() (string, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return "", p.unexpected(t)
+	}
+	return t.text, nil
+}
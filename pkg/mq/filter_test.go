@@ -0,0 +1,153 @@
+// pkg/mq/filter_test.go
+
+package mq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		isErr bool
+	}{
+		{
+			name: "empty expression",
+			expr: "  ",
+		},
+		{
+			name: "equality",
+			expr: "type = 'order.created'",
+		},
+		{
+			name: "bare values and keywords in any case",
+			expr: "type = order.created and region in (eu, us) Or source prefix web/",
+		},
+		{
+			name: "parentheses",
+			expr: `(type PREFIX "order." OR priority != low) AND tenant = acme`,
+		},
+		{
+			name:  "missing value",
+			expr:  "type =",
+			isErr: true,
+		},
+		{
+			name:  "unknown operator",
+			expr:  "type LIKE 'order.%'",
+			isErr: true,
+		},
+		{
+			name:  "unterminated string",
+			expr:  "type = 'order.created",
+			isErr: true,
+		},
+		{
+			name:  "unbalanced parentheses",
+			expr:  "(type = order.created",
+			isErr: true,
+		},
+		{
+			name:  "empty list",
+			expr:  "region IN ()",
+			isErr: true,
+		},
+		{
+			name:  "trailing tokens",
+			expr:  "type = order.created region = eu",
+			isErr: true,
+		},
+		{
+			name:  "invalid character",
+			expr:  "type == order.created",
+			isErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFilter(tt.expr)
+			if tt.isErr {
+				assert.ErrorIs(t, err, ErrInvalidFilter)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	headers := map[string]string{
+		"type":   "order.created",
+		"region": "eu",
+		"quote":  "it's",
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{
+			name: "equal",
+			expr: "type = 'order.created'",
+			want: true,
+		},
+		{
+			name: "not equal",
+			expr: "type = order.deleted",
+			want: false,
+		},
+		{
+			name: "missing header is never equal",
+			expr: "tenant = acme",
+			want: false,
+		},
+		{
+			name: "missing header is not equal",
+			expr: "tenant != acme",
+			want: true,
+		},
+		{
+			name: "in",
+			expr: "region IN ('us', 'eu')",
+			want: true,
+		},
+		{
+			name: "not in",
+			expr: "region IN (us, apac)",
+			want: false,
+		},
+		{
+			name: "prefix",
+			expr: "type PREFIX order.",
+			want: true,
+		},
+		{
+			name: "escaped quote",
+			expr: `quote = 'it\'s'`,
+			want: true,
+		},
+		{
+			name: "and binds tighter than or",
+			expr: "region = us AND type = order.deleted OR type = order.created",
+			want: true,
+		},
+		{
+			name: "parentheses",
+			expr: "region = us AND (type = order.deleted OR type = order.created)",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseFilter(tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, f.match(headers))
+		})
+	}
+}
@@ -71,6 +71,46 @@ func (gRPC *GrpcServer) CreateChannel(
 	return gRPC.server.CreateChannel(ctx, req)
 }
 
+// DeleteChannel gRPC endpoint
+func (gRPC *GrpcServer) DeleteChannel(
+	ctx context.Context,
+	req *pb.DeleteChannelRequest,
+) (*pb.DeleteChannelResponse, error) {
+	return gRPC.server.DeleteChannel(ctx, req)
+}
+
+// PurgeChannel gRPC endpoint
+func (gRPC *GrpcServer) PurgeChannel(
+	ctx context.Context,
+	req *pb.PurgeChannelRequest,
+) (*pb.PurgeChannelResponse, error) {
+	return gRPC.server.PurgeChannel(ctx, req)
+}
+
+// ListChannels gRPC endpoint
+func (gRPC *GrpcServer) ListChannels(
+	ctx context.Context,
+	req *pb.ListChannelsRequest,
+) (*pb.ListChannelsResponse, error) {
+	return gRPC.server.ListChannels(ctx, req)
+}
+
+// DescribeChannel gRPC endpoint
+func (gRPC *GrpcServer) DescribeChannel(
+	ctx context.Context,
+	req *pb.DescribeChannelRequest,
+) (*pb.DescribeChannelResponse, error) {
+	return gRPC.server.DescribeChannel(ctx, req)
+}
+
+// UpdateChannelConfig gRPC endpoint
+func (gRPC *GrpcServer) UpdateChannelConfig(
+	ctx context.Context,
+	req *pb.UpdateChannelConfigRequest,
+) (*pb.UpdateChannelConfigResponse, error) {
+	return gRPC.server.UpdateChannelConfig(ctx, req)
+}
+
 // Publish gRPC endpoint
 func (gRPC *GrpcServer) Publish(
 	ctx context.Context,
@@ -79,6 +119,21 @@ func (gRPC *GrpcServer) Publish(
 	return gRPC.server.Publish(ctx, req)
 }
 
+// PublishBatch gRPC endpoint
+func (gRPC *GrpcServer) PublishBatch(
+	ctx context.Context,
+	req *pb.PublishBatchRequest,
+) (*pb.PublishBatchResponse, error) {
+	return gRPC.server.PublishBatch(ctx, req)
+}
+
+// PublishStream gRPC endpoint
+func (gRPC *GrpcServer) PublishStream(
+	stream pb.MQService_PublishStreamServer,
+) error {
+	return gRPC.server.PublishStream(stream)
+}
+
 // Subscribe gRPC endpoint
 func (gRPC *GrpcServer) Subscribe(
 	req *pb.SubscribeRequest,
@@ -86,3 +141,27 @@ func (gRPC *GrpcServer) Subscribe(
 ) error {
 	return gRPC.server.Subscribe(req, stream)
 }
+
+// Ack gRPC endpoint
+func (gRPC *GrpcServer) Ack(
+	ctx context.Context,
+	req *pb.AckRequest,
+) (*pb.AckResponse, error) {
+	return gRPC.server.Ack(ctx, req)
+}
+
+// Nack gRPC endpoint
+func (gRPC *GrpcServer) Nack(
+	ctx context.Context,
+	req *pb.NackRequest,
+) (*pb.NackResponse, error) {
+	return gRPC.server.Nack(ctx, req)
+}
+
+// Reject gRPC endpoint
+func (gRPC *GrpcServer) Reject(
+	ctx context.Context,
+	req *pb.RejectRequest,
+) (*pb.RejectResponse, error) {
+	return gRPC.server.Reject(ctx, req)
+}
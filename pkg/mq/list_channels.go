@@ -0,0 +1,86 @@
+// pkg/mq/list_channels.go
+
+package mq
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+const (
+	// defaultListPageSize is the number of channels listed per page when the page size is not specified
+	defaultListPageSize uint32 = 100
+
+	// maxListPageSize is the maximum number of channels listed per page
+	maxListPageSize uint32 = 1000
+)
+
+// ListChannels returns the names of the channels starting with the prefix, in order, a page of at most
+// pageSize channels at a time, along with the token of the next page, empty on the last page.
+// The token of a page is the name of the last channel of the previous one, the channels
+// created or deleted in between pages are listed or left out according to their names.
+func (s *Service) ListChannels(
+	ctx context.Context,
+	prefix string,
+	pageSize uint32,
+	pageToken string,
+) ([]string, string, error) {
+	if pageSize == 0 {
+		pageSize = defaultListPageSize
+	}
+	pageSize = min(pageSize, maxListPageSize)
+
+	channels := make([]string, 0)
+	for _, channel := range s.storage.GetChannels() {
+		if !strings.HasPrefix(channel, prefix) || channel <= pageToken {
+			continue
+		}
+
+		// There is a next page, it starts after the last channel of this one
+		if uint32(len(channels)) == pageSize {
+			return channels, channels[len(channels)-1], nil
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, "", nil
+}
+
+type listChannelsInput struct {
+	Prefix    string
+	PageSize  uint32 `validate:"lte=1000"`
+	PageToken string
+}
+
+// gRPC implementation of the ListChannels method
+func (s *Server) ListChannels(
+	ctx context.Context,
+	req *pb.ListChannelsRequest,
+) (*pb.ListChannelsResponse, error) {
+	input := &listChannelsInput{
+		Prefix:    req.GetPrefix(),
+		PageSize:  req.GetPageSize(),
+		PageToken: req.GetPageToken(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// List the channels
+	channels, nextPageToken, err := s.srv.ListChannels(ctx, input.Prefix, input.PageSize, input.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListChannelsResponse{
+		Channels:      channels,
+		NextPageToken: nextPageToken,
+	}, nil
+}
@@ -0,0 +1,175 @@
+// pkg/mq/list_channels_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+func TestListChannelsService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockStorage.EXPECT().
+		GetChannels().
+		Return([]string{"invoices", "orders.created", "orders.deleted", "orders.updated", "payments"}).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		prefix        string
+		pageSize      uint32
+		pageToken     string
+		channels      []string
+		nextPageToken string
+	}{
+		{
+			name:          "success: every channel listed with the default page size",
+			prefix:        "",
+			pageSize:      0,
+			pageToken:     "",
+			channels:      []string{"invoices", "orders.created", "orders.deleted", "orders.updated", "payments"},
+			nextPageToken: "",
+		},
+		{
+			name:          "success: channels filtered by prefix",
+			prefix:        "orders.",
+			pageSize:      0,
+			pageToken:     "",
+			channels:      []string{"orders.created", "orders.deleted", "orders.updated"},
+			nextPageToken: "",
+		},
+		{
+			name:          "success: first page",
+			prefix:        "orders.",
+			pageSize:      2,
+			pageToken:     "",
+			channels:      []string{"orders.created", "orders.deleted"},
+			nextPageToken: "orders.deleted",
+		},
+		{
+			name:          "success: last page",
+			prefix:        "orders.",
+			pageSize:      2,
+			pageToken:     "orders.deleted",
+			channels:      []string{"orders.updated"},
+			nextPageToken: "",
+		},
+		{
+			name:          "success: page ending with the last channel",
+			prefix:        "",
+			pageSize:      2,
+			pageToken:     "orders.deleted",
+			channels:      []string{"orders.updated", "payments"},
+			nextPageToken: "",
+		},
+		{
+			name:          "success: no channel matches the prefix",
+			prefix:        "refunds",
+			pageSize:      0,
+			pageToken:     "",
+			channels:      []string{},
+			nextPageToken: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channels, nextPageToken, err := service.ListChannels(ctx, tt.prefix, tt.pageSize, tt.pageToken)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.channels, channels)
+			assert.Equal(t, tt.nextPageToken, nextPageToken)
+		})
+	}
+}
+
+func TestListChannelsServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		req   *pb.ListChannelsRequest
+		setup func()
+		res   *pb.ListChannelsResponse
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.ListChannelsRequest{
+				Prefix:    "",
+				PageSize:  5000,
+				PageToken: "",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			res: nil,
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "success: channels listed",
+			req: &pb.ListChannelsRequest{
+				Prefix:    "orders.",
+				PageSize:  1,
+				PageToken: "",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					ListChannels(ctx, "orders.", uint32(1), "").
+					Return([]string{"orders.created"}, "orders.created", nil)
+			},
+			res: &pb.ListChannelsResponse{
+				Channels:      []string{"orders.created"},
+				NextPageToken: "orders.created",
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			res, err := server.ListChannels(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+		})
+	}
+}
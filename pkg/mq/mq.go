@@ -7,6 +7,8 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
 	"github.com/hitesh22rana/mq/pkg/storage"
@@ -17,37 +19,129 @@ var (
 	// ErrFailedToSaveMessage is returned when the mq fails to save a message
 	ErrFailedToSaveMessage = errors.New("error: failed to save message")
 
+	// ErrStaleSequence is returned when a producer publishes a message with a sequence number that is not above the last one it published
+	ErrStaleSequence = errors.New("error: sequence number not above the last one published by the producer")
+
 	// ErrUnableToCreateChannel is returned when the mq fails to create a channel
 	ErrUnableToCreateChannel = errors.New("error: unable to create channel")
 
+	// ErrUnableToDeleteChannel is returned when the mq fails to delete a channel
+	ErrUnableToDeleteChannel = errors.New("error: unable to delete channel")
+
+	// ErrUnableToPurgeChannel is returned when the mq fails to purge a channel
+	ErrUnableToPurgeChannel = errors.New("error: unable to purge channel")
+
+	// ErrUnableToUpdateChannelConfig is returned when the mq fails to update the configuration of a channel
+	ErrUnableToUpdateChannelConfig = errors.New("error: unable to update channel config")
+
+	// ErrUnableToDescribeChannel is returned when the mq fails to read the messages of a channel to describe it
+	ErrUnableToDescribeChannel = errors.New("error: unable to describe channel")
+
+	// ErrUnableToLookUpOffset is returned when the mq fails to look up the offset a subscriber starts reading a channel from by time
+	ErrUnableToLookUpOffset = errors.New("error: unable to look up offset by time")
+
 	// ErrChannelDoesNotExist is returned when the mq tries to publish a message to a non-existent channel
 	ErrChannelDoesNotExist = errors.New("error: channel does not exist")
 
+	// ErrChannelDeleted is returned to the subscribers of a channel when the channel is deleted
+	ErrChannelDeleted = errors.New("error: channel has been deleted")
+
 	// ErrChannelAlreadyExists is returned when the mq tries to create a channel that already exists
 	ErrChannelAlreadyExists = errors.New("error: channel already exists")
 
 	// ErrSubscriberDoesNotExist is returned when the mq tries to unsubscribe a subscriber from a channel in which the subscriber does not exist
 	ErrSubscriberDoesNotExist = errors.New("error: subscriber is not subscribed to the channel")
+
+	// ErrInvalidAckID is returned when the mq is given an ack id it did not issue
+	ErrInvalidAckID = errors.New("error: invalid ack id")
+
+	// ErrSubscriptionDoesNotExist is returned when the mq tries to acknowledge messages of a subscription that has ended
+	ErrSubscriptionDoesNotExist = errors.New("error: subscription does not exist")
+
+	// ErrSubscriptionOptionsMismatch is returned when a subscriber joins a subscription its connected members share with a different ack deadline or filter
+	ErrSubscriptionOptionsMismatch = errors.New("error: the subscription has a different ack deadline or filter")
+
+	// ErrInvalidFilter is returned when the mq is given a filter expression it cannot parse
+	ErrInvalidFilter = errors.New("error: invalid filter")
+
+	// ErrInvalidChannelPattern is returned when the mq is given a channel pattern with misplaced wildcards
+	ErrInvalidChannelPattern = errors.New("error: invalid channel pattern")
+
+	// ErrInvalidChannelName is returned when the mq tries to create a channel whose name contains wildcards or the partition separator
+	ErrInvalidChannelName = errors.New("error: invalid channel name")
+
+	// ErrMissingKey is returned when the mq tries to publish a message without a key to a compacted channel
+	ErrMissingKey = errors.New("error: compacted channel requires a message key")
+
+	// ErrMessageTooLarge is returned when the mq tries to publish a message larger than the max message size of its channel
+	ErrMessageTooLarge = errors.New("error: message exceeds the max message size of the channel")
+
+	// ErrTooManySubscribers is returned when a subscriber tries to subscribe to a channel that has as many subscribers as it allows
+	ErrTooManySubscribers = errors.New("error: channel has reached its max number of subscribers")
+
+	// ErrPartitionsChanged is returned when the mq tries to change the number of partitions of an existing channel
+	ErrPartitionsChanged = errors.New("error: the number of partitions of a channel cannot be changed")
+
+	// ErrCompactionChanged is returned when the mq tries to turn the compaction of an existing channel on or off
+	ErrCompactionChanged = errors.New("error: the compaction of a channel cannot be changed")
+
+	// ErrServiceClosed is returned when a message is published to the mq once it is shutting down
+	ErrServiceClosed = errors.New("error: the mq is shutting down")
+
+	// ErrOffsetOutOfRange is returned when the messages a subscriber has to read next have been evicted by the retention of the channel
+	ErrOffsetOutOfRange = errors.New("error: offset out of range, the messages have been evicted by the retention of the channel")
 )
 
 // MQ defines the interface for the mq
 type MQ interface {
-	CreateChannel(context.Context, string) error
-	Publish(context.Context, string, *pb.Message) error
-	Subscribe(context.Context, *pb.Subscriber, pb.Offset, uint64, string, chan<- *pb.Message) error
+	CreateChannel(context.Context, string, *pb.ChannelConfig) error
+	DeleteChannel(context.Context, string) error
+	PurgeChannel(context.Context, string) error
+	ListChannels(context.Context, string, uint32, string) ([]string, string, error)
+	DescribeChannel(context.Context, string) (*pb.DescribeChannelResponse, error)
+	UpdateChannelConfig(context.Context, string, *pb.ChannelConfig) error
+	Publish(context.Context, string, *pb.Message) (uint64, error)
+	PublishBatch(context.Context, []*pb.WalEntry) ([]uint64, error)
+	PublishAsync(context.Context, string, *pb.Message, func(uint64, error))
+	Subscribe(context.Context, *pb.Subscriber, string, *pb.SubscribeOptions, chan<- *pb.Message, chan<- error) error
 	UnSubscribe(context.Context, *pb.Subscriber, string) error
+	Ack(context.Context, string, []string) error
+	Nack(context.Context, string, []string) error
+	Reject(context.Context, string, []string, string) error
 }
 
 // Service is the implementation of the MQ interface
 type Service struct {
 	mu                   sync.RWMutex
 	storage              storage.Storage
-	channelToSubscribers map[string]map[*pb.Subscriber]struct{}
+	generator            utils.Generator
+	channelToSubscribers map[string]map[*pb.Subscriber]time.Time
+	subscriptions        map[subscriptionKey]*subscription
+	wildcards            map[*pb.Subscriber]*wildcardSubscription
+	partitionGroups      map[subscriptionKey]*partitionGroup
+	nextPartition        atomic.Uint64
+	queueMu              sync.RWMutex
+	queueClosed          bool
+	publishQueue         chan *pendingPublish
+	committerOnce        sync.Once
+	committerDone        chan struct{}
+	autoCreateChannels   bool
+	autoCreateNamespaces map[string]bool
 }
 
 // ServiceOptions represents the options for the mq service
 type ServiceOptions struct {
 	Storage storage.Storage
+
+	// Generator gives the time the default TTL of a channel starts from, the server publishing through the service shares it
+	Generator utils.Generator
+
+	// AutoCreateChannels creates the channels that do not exist when a message is published to them
+	AutoCreateChannels bool
+
+	// AutoCreateNamespaces overrides AutoCreateChannels for the channels of a namespace (orders for orders.created),
+	// the longest namespace a channel belongs to wins
+	AutoCreateNamespaces map[string]bool
 }
 
 // NewService returns a new mq service
@@ -57,7 +151,31 @@ func NewService(
 	return &Service{
 		mu:                   sync.RWMutex{},
 		storage:              options.Storage,
-		channelToSubscribers: make(map[string]map[*pb.Subscriber]struct{}),
+		generator:            options.Generator,
+		channelToSubscribers: make(map[string]map[*pb.Subscriber]time.Time),
+		subscriptions:        make(map[subscriptionKey]*subscription),
+		wildcards:            make(map[*pb.Subscriber]*wildcardSubscription),
+		partitionGroups:      make(map[subscriptionKey]*partitionGroup),
+		queueMu:              sync.RWMutex{},
+		queueClosed:          false,
+		publishQueue:         make(chan *pendingPublish, maxGroupCommitSize),
+		committerOnce:        sync.Once{},
+		committerDone:        make(chan struct{}),
+		autoCreateChannels:   options.AutoCreateChannels,
+		autoCreateNamespaces: options.AutoCreateNamespaces,
+	}
+}
+
+// Close stops the service once the server has stopped, the messages still queued to be published are saved or refused,
+// and the offsets consumed by the durable subscriptions are committed so that they resume exactly where they left off
+func (s *Service) Close() {
+	s.closePublishQueue()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, subscription := range s.subscriptions {
+		subscription.flush()
 	}
 }
 
@@ -0,0 +1,18 @@
+// pkg/mq/mq_test.go
+
+package mq
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// expectSinglePartition makes every channel of the mocked storage have the default configuration, with a single partition
+func expectSinglePartition(mockStorage *mocks.MockStorage) {
+	mockStorage.EXPECT().
+		GetChannelConfig(gomock.Any()).
+		Return(&pb.ChannelConfig{}, nil).
+		AnyTimes()
+}
@@ -0,0 +1,66 @@
+// pkg/mq/nack.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// Nack hands back the messages delivered with the specified ack ids, so that they are redelivered right away
+// (or dead-lettered, once they exceed the max delivery attempts of the channel).
+// Messages that are no longer in flight (already acknowledged, or redelivered) are ignored.
+func (s *Service) Nack(
+	ctx context.Context,
+	channel string,
+	ackIDs []string,
+) error {
+	deliveries, err := s.resolveAckIDs(channel, ackIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		d.subscription.fail(d.messageID, reasonNacked)
+	}
+
+	slog.Info(
+		"messages handed back",
+		slog.String("channel", channel),
+		slog.Int("count", len(deliveries)),
+	)
+	return nil
+}
+
+type nackInput struct {
+	Channel string   `validate:"required"`
+	AckIDs  []string `validate:"required,min=1,dive,required"`
+}
+
+// gRPC implementation of the Nack method
+func (s *Server) Nack(
+	ctx context.Context,
+	req *pb.NackRequest,
+) (*pb.NackResponse, error) {
+	input := &nackInput{
+		Channel: req.GetChannel(),
+		AckIDs:  req.GetAckIds(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// Hand the messages back
+	if err := s.srv.Nack(ctx, input.Channel, input.AckIDs); err != nil {
+		return nil, err
+	}
+
+	return &pb.NackResponse{}, nil
+}
@@ -0,0 +1,168 @@
+// pkg/mq/nack_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+func TestNackService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: "unique-subscriber-id",
+	}
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+	}
+
+	subscription := newSubscription(key, time.Minute, nil, nil, nil)
+	service.subscriptions[key] = subscription
+	delivery := subscription.deliver(msg)
+
+	tests := []struct {
+		name   string
+		ackIDs []string
+		err    error
+	}{
+		{
+			name:   "error: invalid ack id",
+			ackIDs: []string{"invalid-ack-id"},
+			err:    status.Error(codes.InvalidArgument, ErrInvalidAckID.Error()),
+		},
+		{
+			name:   "error: subscription does not exist",
+			ackIDs: []string{ackID("unknown-subscriber-id", msg.GetId())},
+			err:    status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error()),
+		},
+		{
+			name:   "success: message handed back",
+			ackIDs: []string{delivery.GetAckId()},
+			err:    nil,
+		},
+		{
+			name:   "success: message already handed back",
+			ackIDs: []string{delivery.GetAckId()},
+			err:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Nack(ctx, channel, tt.ackIDs)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	// The message handed back is redelivered once, and its delivery attempt is kept
+	assert.Empty(t, subscription.inflight)
+	assert.Equal(t, []*pb.Message{msg}, subscription.takePending())
+	assert.Equal(t, uint32(2), subscription.deliver(msg).GetDeliveryAttempt())
+}
+
+func TestNackServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	ackIDs := []string{ackID("unique-subscriber-id", "unique-message-id")}
+
+	tests := []struct {
+		name  string
+		req   *pb.NackRequest
+		setup func()
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.NackRequest{
+				Channel: channel,
+				AckIds:  nil,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: subscription does not exist",
+			req: &pb.NackRequest{
+				Channel: channel,
+				AckIds:  ackIDs,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					Nack(ctx, channel, ackIDs).
+					Return(status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error()))
+			},
+			err: status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error()),
+		},
+		{
+			name: "success: messages handed back",
+			req: &pb.NackRequest{
+				Channel: channel,
+				AckIds:  ackIDs,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					Nack(ctx, channel, ackIDs).
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			_, err := server.Nack(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
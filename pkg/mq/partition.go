@@ -0,0 +1,207 @@
+// pkg/mq/partition.go
+
+package mq
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+// partitions returns the number of partitions of the channel, a channel has at least one partition
+func (s *Service) partitions(channel string) uint32 {
+	config, err := s.storage.GetChannelConfig(channel)
+	if err != nil || config.GetPartitions() <= 1 {
+		return 1
+	}
+	return config.GetPartitions()
+}
+
+// route assigns the message to a partition of the channel, and returns the name the partition is stored under.
+// Messages with the same key (or without a key, the same idempotency key) are assigned to the same partition,
+// so that they are consumed in order and their duplicates are found, the others are spread over the partitions in turns.
+func (s *Service) route(channel string, msg *pb.Message) string {
+	n := s.partitions(channel)
+	if n == 1 {
+		msg.Partition = 0
+		return channel
+	}
+
+	key := msg.GetKey()
+	if key == "" {
+		key = msg.GetIdempotencyKey()
+	}
+
+	if key != "" {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		msg.Partition = h.Sum32() % n
+	} else {
+		msg.Partition = uint32(s.nextPartition.Add(1) % uint64(n))
+	}
+
+	return storage.PartitionChannel(channel, msg.GetPartition())
+}
+
+// channelOf returns the channel the name of a partition belongs to
+func channelOf(name string) string {
+	channel, _ := storage.ParsePartitionChannel(name)
+	return channel
+}
+
+// validChannelName reports whether the channel can be created, wildcards are reserved for subscribing
+// to several channels at once, and the partition separator for naming the partitions of a channel
+func validChannelName(channel string) bool {
+	return !isWildcard(channel) && !strings.Contains(channel, storage.PartitionSeparator)
+}
+
+// subscribePartitions subscribes the subscriber to every partition of the channel, the caller must hold the lock of the service.
+// The partitions of a subscription shared by several subscribers (a consumer group or a durable subscription) are
+// assigned to the subscribers, each partition is read by a single subscriber so that its messages are consumed in order.
+func (s *Service) subscribePartitions(
+	ctx context.Context,
+	sub *pb.Subscriber,
+	channel string,
+	opts subscribeOptions,
+	msgChan chan<- *pb.Message,
+	failed failFunc,
+) error {
+	n := s.partitions(channel)
+
+	subscriptions := make([]*subscription, 0, n)
+	for p := uint32(0); p < n; p++ {
+		partitionChannel := storage.PartitionChannel(channel, p)
+		subscription, err := s.subscribeChannel(sub, partitionChannel, opts)
+		if err != nil {
+			// Leave the partitions subscribed to so far
+			for q := uint32(0); q < p; q++ {
+				s.unsubscribeChannel(sub, storage.PartitionChannel(channel, q))
+			}
+			return err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if n == 1 || !keepsCursor(sub) {
+		for _, subscription := range subscriptions {
+			go forwardMessages(ctx, subscription, msgChan, failed, opts.tagChannel)
+		}
+		return nil
+	}
+
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: cursorID(sub),
+	}
+	group, exists := s.partitionGroups[key]
+	if !exists {
+		group = &partitionGroup{
+			subscriptions: subscriptions,
+		}
+		s.partitionGroups[key] = group
+	}
+
+	group.members = append(group.members, &groupMember{
+		sub:        sub,
+		ctx:        ctx,
+		msgChan:    msgChan,
+		failed:     failed,
+		tagChannel: opts.tagChannel,
+	})
+	group.rebalance()
+
+	return nil
+}
+
+// unsubscribePartitions removes the subscriber from every partition of the channel, the caller must hold the lock of the service
+func (s *Service) unsubscribePartitions(
+	sub *pb.Subscriber,
+	channel string,
+) {
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: cursorID(sub),
+	}
+	if group, exists := s.partitionGroups[key]; exists {
+		group.leave(sub)
+		if len(group.members) == 0 {
+			delete(s.partitionGroups, key)
+		}
+	}
+
+	n := s.partitions(channel)
+	for p := uint32(0); p < n; p++ {
+		s.unsubscribeChannel(sub, storage.PartitionChannel(channel, p))
+	}
+}
+
+// groupMember is a subscriber sharing the subscriptions to the partitions of a channel
+type groupMember struct {
+	sub        *pb.Subscriber
+	ctx        context.Context
+	msgChan    chan<- *pb.Message
+	failed     failFunc
+	tagChannel bool
+	cancel     context.CancelFunc
+	forwarding sync.WaitGroup
+}
+
+// stop stops forwarding messages to the member, and waits until none is being forwarded
+func (m *groupMember) stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.forwarding.Wait()
+}
+
+// partitionGroup assigns the subscriptions to the partitions of a channel to the subscribers sharing them
+type partitionGroup struct {
+	subscriptions []*subscription
+	members       []*groupMember
+}
+
+// rebalance reassigns the partitions to the members, partition p is assigned to the member p modulo the number of members.
+// No partition is forwarded to two members at once, every member is stopped before the partitions are reassigned.
+func (g *partitionGroup) rebalance() {
+	for _, member := range g.members {
+		member.stop()
+	}
+
+	if len(g.members) == 0 {
+		return
+	}
+
+	contexts := make([]context.Context, len(g.members))
+	for i, member := range g.members {
+		contexts[i], member.cancel = context.WithCancel(member.ctx)
+	}
+
+	for p, subscription := range g.subscriptions {
+		i := p % len(g.members)
+		member := g.members[i]
+		member.forwarding.Add(1)
+		go func(ctx context.Context) {
+			defer member.forwarding.Done()
+			forwardMessages(ctx, subscription, member.msgChan, member.failed, member.tagChannel)
+		}(contexts[i])
+	}
+}
+
+// leave removes the subscriber from the group, and reassigns its partitions to the remaining members
+func (g *partitionGroup) leave(sub *pb.Subscriber) {
+	i := slices.IndexFunc(g.members, func(m *groupMember) bool {
+		return m.sub == sub
+	})
+	if i < 0 {
+		return
+	}
+
+	g.members[i].stop()
+	g.members = slices.Delete(g.members, i, i+1)
+	g.rebalance()
+}
@@ -0,0 +1,71 @@
+// pkg/mq/partition_test.go
+
+package mq
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	channel := "test-channel"
+	mockStorage.EXPECT().
+		GetChannelConfig(channel).
+		Return(&pb.ChannelConfig{Partitions: 4}, nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetChannelConfig("single-partition-channel").
+		Return(&pb.ChannelConfig{}, nil).
+		AnyTimes()
+
+	t.Run("single partition", func(t *testing.T) {
+		msg := &pb.Message{Key: "test-key"}
+		assert.Equal(t, "single-partition-channel", service.route("single-partition-channel", msg))
+		assert.Equal(t, uint32(0), msg.GetPartition())
+	})
+
+	t.Run("same key, same partition", func(t *testing.T) {
+		first := &pb.Message{Key: "test-key"}
+		name := service.route(channel, first)
+		for i := 0; i < 10; i++ {
+			msg := &pb.Message{Key: "test-key"}
+			assert.Equal(t, name, service.route(channel, msg))
+			assert.Equal(t, first.GetPartition(), msg.GetPartition())
+		}
+		assert.Equal(t, storage.PartitionChannel(channel, first.GetPartition()), name)
+	})
+
+	t.Run("idempotency key when there is no key", func(t *testing.T) {
+		first := &pb.Message{IdempotencyKey: "test-idempotency-key"}
+		msg := &pb.Message{IdempotencyKey: "test-idempotency-key"}
+		assert.Equal(t, service.route(channel, first), service.route(channel, msg))
+	})
+
+	t.Run("messages without a key are spread over the partitions", func(t *testing.T) {
+		seen := make(map[uint32]struct{})
+		for i := 0; i < 4; i++ {
+			msg := &pb.Message{}
+			service.route(channel, msg)
+			assert.Less(t, msg.GetPartition(), uint32(4))
+			seen[msg.GetPartition()] = struct{}{}
+		}
+		assert.Len(t, seen, 4)
+	})
+}
@@ -4,51 +4,168 @@ package mq
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
 )
 
-// Publish publishes a message to the specified channel
+// Publish publishes a message to the specified channel, and returns the offset of the message in its partition of the channel
 func (s *Service) Publish(
 	ctx context.Context,
 	channel string,
 	msg *pb.Message,
-) error {
+) (uint64, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if !s.storage.ChannelExists(channel) {
-		slog.Error(
-			"cannot publish to non-existent channel",
-			slog.String("channel", channel),
-		)
-		return status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error())
+	if err := s.ensureChannel(channel); err != nil {
+		return 0, err
 	}
 
-	// Store the message in the storage layer
-	if _, err := s.storage.SaveMessage(channel, msg); err != nil {
+	if err := s.checkMessage(channel, msg); err != nil {
+		return 0, err
+	}
+
+	// Store the message in the storage layer, in the partition of the channel it is routed to
+	s.expire(channel, msg)
+	offset, err := s.storage.SaveMessage(s.route(channel, msg), msg)
+	if err != nil {
 		slog.Error(
 			"failed to save message",
 			slog.String("channel", channel),
 			slog.Any("error", err),
 		)
-		return status.Error(codes.Internal, ErrFailedToSaveMessage.Error())
+		return 0, saveError(err)
 	}
 
 	slog.Info(
 		"message published",
 		slog.String("channel", channel),
+		slog.Uint64("partition", uint64(msg.GetPartition())),
+		slog.Uint64("offset", offset),
 	)
+	return offset, nil
+}
+
+// saveError returns the error returned to the publisher of a message the storage failed to save
+func saveError(err error) error {
+	if errors.Is(err, storage.ErrStaleSequence) {
+		return status.Error(codes.FailedPrecondition, ErrStaleSequence.Error())
+	}
+	return status.Error(codes.Internal, ErrFailedToSaveMessage.Error())
+}
+
+// checkMessage checks the message against the configuration of the channel it is published to. The messages of a
+// compacted channel are compacted by their key (a message with empty content deletes its key), so they must have one,
+// and the content of a message must fit in the max message size of the channel, if it has one.
+func (s *Service) checkMessage(channel string, msg *pb.Message) error {
+	config, err := s.storage.GetChannelConfig(channel)
+	if err != nil {
+		return nil
+	}
+
+	if config.GetCompacted() && msg.GetKey() == "" {
+		slog.Error(
+			"cannot publish to compacted channel without a key",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.InvalidArgument, ErrMissingKey.Error())
+	}
+
+	if maxMessageBytes := config.GetMaxMessageBytes(); maxMessageBytes > 0 && uint64(len(msg.GetContent())) > maxMessageBytes {
+		slog.Error(
+			"cannot publish message larger than the max message size of the channel",
+			slog.String("channel", channel),
+			slog.Int("size", len(msg.GetContent())),
+			slog.Uint64("max_message_bytes", maxMessageBytes),
+		)
+		return status.Error(codes.InvalidArgument, ErrMessageTooLarge.Error())
+	}
+
 	return nil
 }
 
+// expire sets the expiry of a message published without a TTL from the default TTL of the channel, if it has one
+func (s *Service) expire(channel string, msg *pb.Message) {
+	if msg.GetExpiresAt() > 0 {
+		return
+	}
+
+	config, err := s.storage.GetChannelConfig(channel)
+	if err != nil || config.GetDefaultTtlMs() == 0 {
+		return
+	}
+
+	// The TTL of a delayed message starts once it is due
+	msg.ExpiresAt = max(s.generator.GetCurrentTimeMillis(), msg.GetDeliverAt()) + int64(config.GetDefaultTtlMs())
+}
+
 type publishInput struct {
-	Channel string `validate:"required"`
-	Content []byte `validate:"required"`
+	Channel        string            `validate:"required"`
+	Content        []byte            `validate:"required_without=Key"`
+	Key            string            `validate:"max=256"`
+	ProducerID     string            `validate:"max=256"`
+	Sequence       uint64            `validate:"gte=0"`
+	IdempotencyKey string            `validate:"max=256,excluded_with=ProducerID,idempotency_key"`
+	Headers        map[string]string `validate:"headers"`
+	DeliverAt      int64             `validate:"gte=0"`
+	DelayMs        uint64            `validate:"excluded_with=DeliverAt"`
+	TtlMs          uint64            `validate:"gte=0"`
+}
+
+// newPublishInput returns the input of a publish request
+func newPublishInput(req *pb.PublishRequest) *publishInput {
+	return &publishInput{
+		Channel:        req.GetChannel(),
+		Content:        req.GetContent(),
+		Key:            req.GetKey(),
+		ProducerID:     req.GetProducerId(),
+		Sequence:       req.GetSequence(),
+		IdempotencyKey: req.GetIdempotencyKey(),
+		Headers:        req.GetHeaders(),
+		DeliverAt:      req.GetDeliverAt(),
+		DelayMs:        req.GetDelayMs(),
+		TtlMs:          req.GetTtlMs(),
+	}
+}
+
+// idempotencyKey returns the key the message is deduplicated on, either the key supplied by the
+// publisher or the key derived from its producer ID and sequence number, or an empty string if it is not deduplicated
+func (input *publishInput) idempotencyKey() string {
+	if input.IdempotencyKey != "" {
+		return input.IdempotencyKey
+	}
+	if input.ProducerID != "" {
+		return storage.ProducerKey(input.ProducerID, input.Sequence)
+	}
+	return ""
+}
+
+// schedule returns the time to deliver the message of the input at and the time it expires at (in milliseconds
+// since the epoch), or 0 to deliver it right away and 0 to leave its expiry to the default TTL of the channel.
+// The TTL of a delayed message starts once it is due.
+func (s *Server) schedule(input *publishInput) (int64, int64) {
+	if input.DelayMs == 0 && input.TtlMs == 0 {
+		return input.DeliverAt, 0
+	}
+
+	now := s.generator.GetCurrentTimeMillis()
+	deliverAt := input.DeliverAt
+	if input.DelayMs > 0 {
+		deliverAt = now + int64(input.DelayMs)
+	}
+
+	var expiresAt int64
+	if input.TtlMs > 0 {
+		expiresAt = max(now, deliverAt) + int64(input.TtlMs)
+	}
+
+	return deliverAt, expiresAt
 }
 
 // gRPC implementation of the Publish method
@@ -56,27 +173,36 @@ func (s *Server) Publish(
 	ctx context.Context,
 	req *pb.PublishRequest,
 ) (*pb.PublishResponse, error) {
-	input := &publishInput{
-		Channel: req.GetChannel(),
-		Content: []byte(req.Content),
-	}
+	input := newPublishInput(req)
 
 	// Validate the input request
 	if err := s.validator.ValidateStruct(input); err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid input")
 	}
 
-	// Publish the message
-	if err := s.srv.Publish(
-		ctx,
-		input.Channel,
-		&pb.Message{
-			Id:        s.generator.GetUniqueMessageID(),
-			Content:   input.Content,
-			CreatedAt: s.generator.GetCurrentTimestamp(),
-		}); err != nil {
+	// Publish the message, a duplicate is given the ID, timestamp and offset of the original message
+	deliverAt, expiresAt := s.schedule(input)
+	msg := &pb.Message{
+		Id:             s.generator.GetUniqueMessageID(),
+		Content:        input.Content,
+		CreatedAt:      s.generator.GetCurrentTimestamp(),
+		Key:            input.Key,
+		IdempotencyKey: input.idempotencyKey(),
+		Headers:        input.Headers,
+		DeliverAt:      deliverAt,
+		ExpiresAt:      expiresAt,
+	}
+	offset, err := s.srv.Publish(ctx, input.Channel, msg)
+	if err != nil {
 		return nil, err
 	}
 
-	return &pb.PublishResponse{}, nil
+	return &pb.PublishResponse{
+		Id:        msg.GetId(),
+		Offset:    offset,
+		CreatedAt: msg.GetCreatedAt(),
+		Partition: msg.GetPartition(),
+		DeliverAt: msg.GetDeliverAt(),
+		ExpiresAt: msg.GetExpiresAt(),
+	}, nil
 }
@@ -0,0 +1,133 @@
+// pkg/mq/publish_batch.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// PublishBatch publishes messages to their channels at once, and returns the offsets of the messages in their partitions.
+// Either all the messages are published or none is.
+func (s *Service) PublishBatch(
+	ctx context.Context,
+	entries []*pb.WalEntry,
+) ([]uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Check every message before creating any channel, so that a batch refused leaves no channel behind
+	missing := make([]string, 0)
+	for _, entry := range entries {
+		create, err := s.checkChannel(entry.GetChannel())
+		if err != nil {
+			return nil, err
+		}
+		if create && !slices.Contains(missing, entry.GetChannel()) {
+			missing = append(missing, entry.GetChannel())
+		}
+
+		if err := s.checkMessage(entry.GetChannel(), entry.GetMessage()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, channel := range missing {
+		if err := s.autoCreateChannel(channel); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the expiry of the messages, and route them to their partitions
+	for _, entry := range entries {
+		s.expire(entry.GetChannel(), entry.GetMessage())
+		entry.Channel = s.route(entry.GetChannel(), entry.GetMessage())
+	}
+
+	// Store the messages in the storage layer
+	offsets, err := s.storage.SaveMessages(entries)
+	if err != nil {
+		slog.Error(
+			"failed to save messages",
+			slog.Int("count", len(entries)),
+			slog.Any("error", err),
+		)
+		return nil, saveError(err)
+	}
+
+	slog.Info(
+		"messages published",
+		slog.Int("count", len(entries)),
+	)
+	return offsets, nil
+}
+
+type publishBatchInput struct {
+	Messages []*publishInput `validate:"required,min=1,dive,required"`
+}
+
+// gRPC implementation of the PublishBatch method
+func (s *Server) PublishBatch(
+	ctx context.Context,
+	req *pb.PublishBatchRequest,
+) (*pb.PublishBatchResponse, error) {
+	input := &publishBatchInput{
+		Messages: make([]*publishInput, 0, len(req.GetMessages())),
+	}
+	for _, msg := range req.GetMessages() {
+		input.Messages = append(input.Messages, newPublishInput(msg))
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// The messages of a batch share the same timestamp
+	createdAt := s.generator.GetCurrentTimestamp()
+	entries := make([]*pb.WalEntry, 0, len(input.Messages))
+	for _, msg := range input.Messages {
+		deliverAt, expiresAt := s.schedule(msg)
+		entries = append(entries, &pb.WalEntry{
+			Channel: msg.Channel,
+			Message: &pb.Message{
+				Id:             s.generator.GetUniqueMessageID(),
+				Content:        msg.Content,
+				CreatedAt:      createdAt,
+				Key:            msg.Key,
+				IdempotencyKey: msg.idempotencyKey(),
+				Headers:        msg.Headers,
+				DeliverAt:      deliverAt,
+				ExpiresAt:      expiresAt,
+			},
+		})
+	}
+
+	// Publish the messages
+	offsets, err := s.srv.PublishBatch(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.PublishResponse, 0, len(entries))
+	for i, entry := range entries {
+		results = append(results, &pb.PublishResponse{
+			Id:        entry.GetMessage().GetId(),
+			Offset:    offsets[i],
+			CreatedAt: entry.GetMessage().GetCreatedAt(),
+			Partition: entry.GetMessage().GetPartition(),
+			DeliverAt: entry.GetMessage().GetDeliverAt(),
+			ExpiresAt: entry.GetMessage().GetExpiresAt(),
+		})
+	}
+
+	return &pb.PublishBatchResponse{
+		Results: results,
+	}, nil
+}
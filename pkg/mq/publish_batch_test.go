@@ -0,0 +1,247 @@
+// pkg/mq/publish_batch_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestPublishBatchService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	entries := []*pb.WalEntry{
+		{
+			Channel: "test-channel-1",
+			Message: &pb.Message{
+				Id:        "unique-message-id-1",
+				Content:   []byte("test-content-1"),
+				CreatedAt: int64(1234567890),
+			},
+		},
+		{
+			Channel: "test-channel-2",
+			Message: &pb.Message{
+				Id:        "unique-message-id-2",
+				Content:   []byte("test-content-2"),
+				CreatedAt: int64(1234567890),
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		setup   func()
+		offsets []uint64
+		err     error
+	}{
+		{
+			name: "error: channel does not exist",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel-1").
+					Return(true)
+				mockStorage.EXPECT().
+					ChannelExists("test-channel-2").
+					Return(false)
+			},
+			offsets: nil,
+			err:     status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name: "error: failed to save messages",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(gomock.Any()).
+					Return(true).
+					Times(2)
+				mockStorage.EXPECT().
+					SaveMessages(entries).
+					Return(nil, storage.ErrInternal)
+			},
+			offsets: nil,
+			err:     status.Error(codes.Internal, ErrFailedToSaveMessage.Error()),
+		},
+		{
+			name: "success: messages saved",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(gomock.Any()).
+					Return(true).
+					Times(2)
+				mockStorage.EXPECT().
+					SaveMessages(entries).
+					Return([]uint64{3, 0}, nil)
+			},
+			offsets: []uint64{3, 0},
+			err:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			offsets, err := service.PublishBatch(ctx, entries)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.offsets, offsets)
+		})
+	}
+}
+
+func TestPublishBatchServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	timestamp := int64(1234567890)
+	req := &pb.PublishBatchRequest{
+		Messages: []*pb.PublishRequest{
+			{
+				Channel: "test-channel-1",
+				Content: []byte("test-content-1"),
+			},
+			{
+				Channel: "test-channel-2",
+				Content: []byte("test-content-2"),
+			},
+		},
+	}
+	entries := []*pb.WalEntry{
+		{
+			Channel: "test-channel-1",
+			Message: &pb.Message{
+				Id:        "unique-message-id-1",
+				Content:   []byte("test-content-1"),
+				CreatedAt: timestamp,
+			},
+		},
+		{
+			Channel: "test-channel-2",
+			Message: &pb.Message{
+				Id:        "unique-message-id-2",
+				Content:   []byte("test-content-2"),
+				CreatedAt: timestamp,
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		req   *pb.PublishBatchRequest
+		setup func()
+		res   *pb.PublishBatchResponse
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req:  &pb.PublishBatchRequest{},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			res: nil,
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: channel does not exist",
+			req:  req,
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				mockGenerator.EXPECT().
+					GetUniqueMessageID().
+					Return("unique-message-id").
+					Times(2)
+				mockService.EXPECT().
+					PublishBatch(ctx, gomock.Any()).
+					Return(nil, status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()))
+			},
+			res: nil,
+			err: status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name: "success: successfully published",
+			req:  req,
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				gomock.InOrder(
+					mockGenerator.EXPECT().
+						GetUniqueMessageID().
+						Return("unique-message-id-1"),
+					mockGenerator.EXPECT().
+						GetUniqueMessageID().
+						Return("unique-message-id-2"),
+				)
+				mockService.EXPECT().
+					PublishBatch(ctx, entries).
+					Return([]uint64{3, 0}, nil)
+			},
+			res: &pb.PublishBatchResponse{
+				Results: []*pb.PublishResponse{
+					{
+						Id:        "unique-message-id-1",
+						Offset:    3,
+						CreatedAt: timestamp,
+					},
+					{
+						Id:        "unique-message-id-2",
+						Offset:    0,
+						CreatedAt: timestamp,
+					},
+				},
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			res, err := server.PublishBatch(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// pkg/mq/publish_stream.go
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+const (
+	// maxGroupCommitSize is the maximum number of queued messages saved as a single WAL batch
+	maxGroupCommitSize = 1024
+
+	// maxInFlightPublishes is the maximum number of messages of a publish stream waiting to be acknowledged
+	maxInFlightPublishes = 1024
+)
+
+// pendingPublish is a message queued to be published, along with the function called once it is saved
+type pendingPublish struct {
+	channel string
+	msg     *pb.Message
+	done    func(uint64, error)
+}
+
+// PublishAsync queues a message to be published to the specified channel, done is called with the offset of
+// the message once it has been saved. The messages queued while a batch is being saved are saved together
+// as the next batch, so that a single WAL write (and fsync) is shared by all of them.
+func (s *Service) PublishAsync(
+	ctx context.Context,
+	channel string,
+	msg *pb.Message,
+	done func(uint64, error),
+) {
+	// The queue is not closed while a message is being queued
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+	if s.queueClosed {
+		done(0, status.Error(codes.Unavailable, ErrServiceClosed.Error()))
+		return
+	}
+
+	s.mu.RLock()
+	err := s.ensureChannel(channel)
+	s.mu.RUnlock()
+	if err != nil {
+		done(0, err)
+		return
+	}
+	if err := s.checkMessage(channel, msg); err != nil {
+		done(0, err)
+		return
+	}
+	s.expire(channel, msg)
+	channel = s.route(channel, msg)
+
+	// Start saving the queued messages on the first publish
+	s.committerOnce.Do(func() {
+		go func() {
+			defer close(s.committerDone)
+			s.commitPublishes()
+		}()
+	})
+
+	select {
+	case s.publishQueue <- &pendingPublish{
+		channel: channel,
+		msg:     msg,
+		done:    done,
+	}:
+	case <-ctx.Done():
+		done(0, status.Error(codes.Canceled, ctx.Err().Error()))
+	}
+}
+
+// closePublishQueue stops queueing the published messages, the batch being saved is saved while the messages still queued
+// are refused, and waits for the queued messages to be saved or refused
+func (s *Service) closePublishQueue() {
+	s.queueMu.Lock()
+	if s.queueClosed {
+		s.queueMu.Unlock()
+		<-s.committerDone
+		return
+	}
+	s.queueClosed = true
+	close(s.publishQueue)
+	s.queueMu.Unlock()
+
+	// The committer is never started once the queue is closed
+	s.committerOnce.Do(func() {
+		close(s.committerDone)
+	})
+
+	for pending := range s.publishQueue {
+		pending.done(0, status.Error(codes.Unavailable, ErrServiceClosed.Error()))
+	}
+	<-s.committerDone
+}
+
+// commitPublishes saves the queued messages in batches, until the service is closed
+func (s *Service) commitPublishes() {
+	for first := range s.publishQueue {
+		// Group the messages queued while the previous batch was being saved
+		batch := []*pendingPublish{first}
+	group:
+		for len(batch) < maxGroupCommitSize {
+			select {
+			case pending, ok := <-s.publishQueue:
+				if !ok {
+					break group
+				}
+				batch = append(batch, pending)
+			default:
+				break group
+			}
+		}
+
+		// The messages queued to a channel deleted since are not saved, the storage would refuse the whole batch
+		s.mu.RLock()
+		entries := make([]*pb.WalEntry, 0, len(batch))
+		saved := make([]*pendingPublish, 0, len(batch))
+		deleted := make([]*pendingPublish, 0)
+		for _, pending := range batch {
+			if !s.storage.ChannelExists(pending.channel) {
+				deleted = append(deleted, pending)
+				continue
+			}
+			entries = append(entries, &pb.WalEntry{
+				Channel: pending.channel,
+				Message: pending.msg,
+			})
+			saved = append(saved, pending)
+		}
+
+		offsets := make([]uint64, len(saved))
+		errs := make([]error, len(saved))
+		if len(entries) > 0 {
+			batchOffsets, err := s.storage.SaveMessages(entries)
+			switch {
+			case errors.Is(err, storage.ErrStaleSequence):
+				// A stale message of a producer refuses the whole batch, the messages are saved one by one
+				// instead so that the messages of the other publishers are saved
+				for i, entry := range entries {
+					offsets[i], errs[i] = s.storage.SaveMessage(entry.GetChannel(), entry.GetMessage())
+				}
+			case err != nil:
+				for i := range errs {
+					errs[i] = err
+				}
+			default:
+				copy(offsets, batchOffsets)
+			}
+		}
+		s.mu.RUnlock()
+
+		for _, pending := range deleted {
+			pending.done(0, status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()))
+		}
+
+		for i, pending := range saved {
+			if errs[i] != nil {
+				slog.Error(
+					"failed to save message",
+					slog.String("channel", pending.channel),
+					slog.Any("error", errs[i]),
+				)
+				pending.done(0, saveError(errs[i]))
+				continue
+			}
+
+			pending.done(offsets[i], nil)
+		}
+	}
+}
+
+// gRPC implementation of the PublishStream method
+func (s *Server) PublishStream(
+	stream pb.MQService_PublishStreamServer,
+) error {
+	ctx := stream.Context()
+
+	// Acknowledge the requests in the order they were received, as soon as they are saved
+	acks := make(chan chan *pb.PublishStreamResponse, maxInFlightPublishes)
+	sendErr := make(chan error, 1)
+	go func() {
+		var err error
+		for ack := range acks {
+			res := <-ack
+
+			// Keep draining the acknowledgements once the stream is broken
+			if err == nil {
+				err = stream.Send(res)
+			}
+		}
+		sendErr <- err
+	}()
+
+	var recvErr error
+	for sequence := uint64(0); ; sequence++ {
+		req, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				recvErr = err
+			}
+			break
+		}
+
+		// Reserve the place of the acknowledgement, this blocks once too many requests are in flight
+		ack := make(chan *pb.PublishStreamResponse, 1)
+		acks <- ack
+
+		input := newPublishInput(req)
+
+		// Validate the input request, an invalid request is acknowledged with an error
+		if err := s.validator.ValidateStruct(input); err != nil {
+			ack <- &pb.PublishStreamResponse{
+				Sequence: sequence,
+				Error:    "invalid input",
+			}
+			continue
+		}
+
+		// Publish the message
+		deliverAt, expiresAt := s.schedule(input)
+		msg := &pb.Message{
+			Id:             s.generator.GetUniqueMessageID(),
+			Content:        input.Content,
+			CreatedAt:      s.generator.GetCurrentTimestamp(),
+			Key:            input.Key,
+			IdempotencyKey: input.idempotencyKey(),
+			Headers:        input.Headers,
+			DeliverAt:      deliverAt,
+			ExpiresAt:      expiresAt,
+		}
+		s.srv.PublishAsync(ctx, input.Channel, msg, func(offset uint64, err error) {
+			if err != nil {
+				ack <- &pb.PublishStreamResponse{
+					Sequence: sequence,
+					Error:    status.Convert(err).Message(),
+				}
+				return
+			}
+
+			ack <- &pb.PublishStreamResponse{
+				Sequence: sequence,
+				Result: &pb.PublishResponse{
+					Id:        msg.GetId(),
+					Offset:    offset,
+					CreatedAt: msg.GetCreatedAt(),
+					Partition: msg.GetPartition(),
+					DeliverAt: msg.GetDeliverAt(),
+					ExpiresAt: msg.GetExpiresAt(),
+				},
+			}
+		})
+	}
+
+	// Wait for the requests in flight to be acknowledged
+	close(acks)
+	if err := <-sendErr; err != nil {
+		slog.Error(
+			"failed to send acknowledgement",
+			slog.Any("error", err),
+		)
+		return status.Error(codes.Unavailable, "failed to send acknowledgement")
+	}
+
+	return recvErr
+}
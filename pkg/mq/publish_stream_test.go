@@ -0,0 +1,388 @@
+// pkg/mq/publish_stream_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+type publishResult struct {
+	offset uint64
+	err    error
+}
+
+func TestPublishAsyncService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+	}
+	entries := []*pb.WalEntry{
+		{
+			Channel: channel,
+			Message: msg,
+		},
+	}
+
+	tests := []struct {
+		name  string
+		setup func()
+		want  publishResult
+	}{
+		{
+			name: "error: channel does not exist",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(false)
+			},
+			want: publishResult{
+				offset: 0,
+				err:    status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+			},
+		},
+		{
+			name: "error: channel deleted while the message was queued",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(true)
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(false)
+			},
+			want: publishResult{
+				offset: 0,
+				err:    status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
+			},
+		},
+		{
+			name: "error: failed to save message",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(true).
+					Times(2)
+				mockStorage.EXPECT().
+					SaveMessages(entries).
+					Return(nil, storage.ErrInternal)
+			},
+			want: publishResult{
+				offset: 0,
+				err:    status.Error(codes.Internal, ErrFailedToSaveMessage.Error()),
+			},
+		},
+		{
+			name: "error: stale sequence number",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(true).
+					Times(2)
+				mockStorage.EXPECT().
+					SaveMessages(entries).
+					Return(nil, storage.ErrStaleSequence)
+				mockStorage.EXPECT().
+					SaveMessage(channel, msg).
+					Return(uint64(0), storage.ErrStaleSequence)
+			},
+			want: publishResult{
+				offset: 0,
+				err:    status.Error(codes.FailedPrecondition, ErrStaleSequence.Error()),
+			},
+		},
+		{
+			name: "success: message saved",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(true).
+					Times(2)
+				mockStorage.EXPECT().
+					SaveMessages(entries).
+					Return([]uint64{7}, nil)
+			},
+			want: publishResult{
+				offset: 7,
+				err:    nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			results := make(chan publishResult, 1)
+			service.PublishAsync(ctx, channel, msg, func(offset uint64, err error) {
+				results <- publishResult{offset: offset, err: err}
+			})
+
+			select {
+			case got := <-results:
+				assert.Equal(t, tt.want, got)
+			case <-time.After(time.Second):
+				t.Fatal("publish was not acknowledged")
+			}
+		})
+	}
+}
+
+func TestPublishAsyncGroupCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+
+	// The first batch is held until the other messages are queued, they are then saved together
+	saving := make(chan struct{})
+	release := make(chan struct{})
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true).
+		Times(8)
+	gomock.InOrder(
+		mockStorage.EXPECT().
+			SaveMessages(gomock.Len(1)).
+			DoAndReturn(func(entries []*pb.WalEntry) ([]uint64, error) {
+				close(saving)
+				<-release
+				return []uint64{0}, nil
+			}),
+		mockStorage.EXPECT().
+			SaveMessages(gomock.Len(3)).
+			Return([]uint64{1, 2, 3}, nil),
+	)
+
+	results := make(chan uint64, 4)
+	done := func(offset uint64, err error) {
+		assert.NoError(t, err)
+		results <- offset
+	}
+
+	service.PublishAsync(ctx, channel, &pb.Message{Id: "unique-message-id-0"}, done)
+	<-saving
+	for i := 1; i < 4; i++ {
+		service.PublishAsync(ctx, channel, &pb.Message{Id: "unique-message-id"}, done)
+	}
+	close(release)
+
+	for want := uint64(0); want < 4; want++ {
+		select {
+		case got := <-results:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatal("publish was not acknowledged")
+		}
+	}
+}
+
+func TestPublishStreamServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	messageID := "unique-message-id"
+	timestamp := int64(1234567890)
+
+	stream := mocks.NewPublishStreamMock(
+		ctx,
+		[]*pb.PublishRequest{
+			{
+				Channel: "",
+				Content: []byte("test-content"),
+			},
+			{
+				Channel: "non-existent-channel",
+				Content: []byte("test-content"),
+			},
+			{
+				Channel: channel,
+				Content: []byte("test-content"),
+			},
+		},
+	)
+
+	gomock.InOrder(
+		mockValidator.EXPECT().
+			ValidateStruct(gomock.Any()).
+			Return(status.Error(codes.InvalidArgument, "invalid input")),
+		mockValidator.EXPECT().
+			ValidateStruct(gomock.Any()).
+			Return(nil).
+			Times(2),
+	)
+	mockGenerator.EXPECT().
+		GetUniqueMessageID().
+		Return(messageID).
+		Times(2)
+	mockGenerator.EXPECT().
+		GetCurrentTimestamp().
+		Return(timestamp).
+		Times(2)
+	mockService.EXPECT().
+		PublishAsync(ctx, "non-existent-channel", gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ string, _ *pb.Message, done func(uint64, error)) {
+			done(0, status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()))
+		})
+	mockService.EXPECT().
+		PublishAsync(ctx, channel, gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ string, _ *pb.Message, done func(uint64, error)) {
+			// Acknowledged asynchronously, after the stream has ended
+			go done(7, nil)
+		})
+
+	err := server.PublishStream(stream)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]*pb.PublishStreamResponse{
+			{
+				Sequence: 0,
+				Error:    "invalid input",
+			},
+			{
+				Sequence: 1,
+				Error:    ErrChannelDoesNotExist.Error(),
+			},
+			{
+				Sequence: 2,
+				Result: &pb.PublishResponse{
+					Id:        messageID,
+					Offset:    7,
+					CreatedAt: timestamp,
+				},
+			},
+		},
+		stream.Sent(),
+	)
+}
+
+func TestPublishAsyncClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+
+	// The batch being saved when the service is closed is saved, the messages queued behind it are refused
+	saving := make(chan struct{})
+	release := make(chan struct{})
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true).
+		Times(4)
+	mockStorage.EXPECT().
+		SaveMessages(gomock.Len(1)).
+		DoAndReturn(func(entries []*pb.WalEntry) ([]uint64, error) {
+			close(saving)
+			<-release
+			return []uint64{0}, nil
+		})
+
+	saved := make(chan uint64, 1)
+	refused := make(chan error, 3)
+	service.PublishAsync(ctx, channel, &pb.Message{Id: "unique-message-id-0"}, func(offset uint64, err error) {
+		assert.NoError(t, err)
+		saved <- offset
+	})
+	<-saving
+	for i := 1; i < 3; i++ {
+		service.PublishAsync(ctx, channel, &pb.Message{Id: "unique-message-id"}, func(offset uint64, err error) {
+			refused <- err
+		})
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		service.Close()
+		close(closed)
+	}()
+
+	for i := 1; i < 3; i++ {
+		select {
+		case err := <-refused:
+			assert.Equal(t, status.Error(codes.Unavailable, ErrServiceClosed.Error()), err)
+		case <-time.After(time.Second):
+			t.Fatal("queued publish was not refused")
+		}
+	}
+
+	// Close waits for the batch being saved
+	select {
+	case <-closed:
+		t.Fatal("service closed before the batch was saved")
+	default:
+	}
+	close(release)
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("service was not closed")
+	}
+	assert.Equal(t, uint64(0), <-saved)
+
+	// The messages published once the service is closed are refused
+	service.PublishAsync(ctx, channel, &pb.Message{Id: "unique-message-id"}, func(offset uint64, err error) {
+		refused <- err
+	})
+	assert.Equal(t, status.Error(codes.Unavailable, ErrServiceClosed.Error()), <-refused)
+	service.Close()
+}
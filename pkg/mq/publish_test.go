@@ -13,6 +13,7 @@ import (
 
 	"github.com/hitesh22rana/mq/pkg/mocks"
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
 )
 
 func TestPublishService(t *testing.T) {
@@ -20,6 +21,7 @@ func TestPublishService(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
 
 	service := NewService(
 		&ServiceOptions{
@@ -34,9 +36,10 @@ func TestPublishService(t *testing.T) {
 	timestamp := int64(1234567890)
 
 	tests := []struct {
-		name  string
-		setup func()
-		err   error
+		name   string
+		setup  func()
+		offset uint64
+		err    error
 	}{
 		{
 			name: "error: channel does not exist",
@@ -59,6 +62,18 @@ func TestPublishService(t *testing.T) {
 			},
 			err: status.Error(codes.Internal, ErrFailedToSaveMessage.Error()),
 		},
+		{
+			name: "error: stale sequence number",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(true)
+				mockStorage.EXPECT().
+					SaveMessage(channel, gomock.Any()).
+					Return(uint64(0), storage.ErrStaleSequence)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrStaleSequence.Error()),
+		},
 		{
 			name: "success: message saved",
 			setup: func() {
@@ -71,21 +86,211 @@ func TestPublishService(t *testing.T) {
 						Content:   content,
 						CreatedAt: timestamp,
 					}).
-					Return(uint64(0), nil)
+					Return(uint64(7), nil)
 			},
-			err: nil,
+			offset: 7,
+			err:    nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setup()
-			err := service.Publish(ctx, channel, &pb.Message{
+			offset, err := service.Publish(ctx, channel, &pb.Message{
 				Id:        messageID,
 				Content:   content,
 				CreatedAt: timestamp,
 			})
 			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.offset, offset)
+		})
+	}
+}
+
+func TestPublishCompactedChannel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	// The channel has a single partition, and is compacted
+	mockStorage.EXPECT().
+		GetChannelConfig(gomock.Any()).
+		Return(&pb.ChannelConfig{Compacted: true}, nil).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-compacted-channel"
+
+	t.Run("error: message without a key", func(t *testing.T) {
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true)
+
+		offset, err := service.Publish(ctx, channel, &pb.Message{
+			Content: []byte("test-content"),
+		})
+		assert.Equal(t, status.Error(codes.InvalidArgument, ErrMissingKey.Error()), err)
+		assert.Equal(t, uint64(0), offset)
+	})
+
+	t.Run("success: tombstone saved", func(t *testing.T) {
+		tombstone := &pb.Message{
+			Key: "test-key",
+		}
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true)
+		mockStorage.EXPECT().
+			SaveMessage(channel, tombstone).
+			Return(uint64(3), nil)
+
+		offset, err := service.Publish(ctx, channel, tombstone)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), offset)
+	})
+}
+
+func TestPublishMaxMessageBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	// The channel has a single partition, and takes messages of at most 8 bytes
+	mockStorage.EXPECT().
+		GetChannelConfig(gomock.Any()).
+		Return(&pb.ChannelConfig{MaxMessageBytes: 8}, nil).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+
+	t.Run("error: message too large", func(t *testing.T) {
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true)
+
+		offset, err := service.Publish(ctx, channel, &pb.Message{
+			Content: []byte("test-content"),
+		})
+		assert.Equal(t, status.Error(codes.InvalidArgument, ErrMessageTooLarge.Error()), err)
+		assert.Equal(t, uint64(0), offset)
+	})
+
+	t.Run("error: message too large in a batch", func(t *testing.T) {
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true).
+			Times(2)
+
+		offsets, err := service.PublishBatch(ctx, []*pb.WalEntry{
+			{Channel: channel, Message: &pb.Message{Content: []byte("content")}},
+			{Channel: channel, Message: &pb.Message{Content: []byte("test-content")}},
+		})
+		assert.Equal(t, status.Error(codes.InvalidArgument, ErrMessageTooLarge.Error()), err)
+		assert.Nil(t, offsets)
+	})
+
+	t.Run("success: message at the max message size saved", func(t *testing.T) {
+		msg := &pb.Message{
+			Content: []byte("12345678"),
+		}
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true)
+		mockStorage.EXPECT().
+			SaveMessage(channel, msg).
+			Return(uint64(0), nil)
+
+		_, err := service.Publish(ctx, channel, msg)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPublishDefaultTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+
+	// The channel has a single partition, and its messages expire after a minute
+	mockStorage.EXPECT().
+		GetChannelConfig(gomock.Any()).
+		Return(&pb.ChannelConfig{DefaultTtlMs: 60000}, nil).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage:   mockStorage,
+			Generator: mockGenerator,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	now := int64(1234567890000)
+
+	tests := []struct {
+		name      string
+		msg       *pb.Message
+		setup     func()
+		expiresAt int64
+	}{
+		{
+			name: "success: expiry set from the time of the generator",
+			msg:  &pb.Message{Content: []byte("test-content")},
+			setup: func() {
+				mockGenerator.EXPECT().
+					GetCurrentTimeMillis().
+					Return(now)
+			},
+			expiresAt: now + 60000,
+		},
+		{
+			name: "success: expiry of a delayed message set from the time it is due",
+			msg:  &pb.Message{Content: []byte("test-content"), DeliverAt: now + 30000},
+			setup: func() {
+				mockGenerator.EXPECT().
+					GetCurrentTimeMillis().
+					Return(now)
+			},
+			expiresAt: now + 90000,
+		},
+		{
+			name:      "success: expiry of the message kept",
+			msg:       &pb.Message{Content: []byte("test-content"), ExpiresAt: now + 1000},
+			setup:     func() {},
+			expiresAt: now + 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			mockStorage.EXPECT().
+				ChannelExists(channel).
+				Return(true)
+			mockStorage.EXPECT().
+				SaveMessage(channel, tt.msg).
+				Return(uint64(0), nil)
+
+			_, err := service.Publish(ctx, channel, tt.msg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expiresAt, tt.msg.GetExpiresAt())
 		})
 	}
 }
@@ -116,6 +321,7 @@ func TestPublishServer(t *testing.T) {
 		name  string
 		req   *pb.PublishRequest
 		setup func()
+		res   *pb.PublishResponse
 		err   error
 	}{
 		{
@@ -149,7 +355,7 @@ func TestPublishServer(t *testing.T) {
 					Return(timestamp)
 				mockService.EXPECT().
 					Publish(ctx, channel, gomock.Any()).
-					Return(status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()))
+					Return(uint64(0), status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()))
 			},
 			err: status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()),
 		},
@@ -171,7 +377,7 @@ func TestPublishServer(t *testing.T) {
 					Return(timestamp)
 				mockService.EXPECT().
 					Publish(ctx, channel, gomock.Any()).
-					Return(status.Error(codes.Internal, ErrFailedToSaveMessage.Error()))
+					Return(uint64(0), status.Error(codes.Internal, ErrFailedToSaveMessage.Error()))
 			},
 			err: status.Error(codes.Internal, ErrFailedToSaveMessage.Error()),
 		},
@@ -197,7 +403,190 @@ func TestPublishServer(t *testing.T) {
 						Content:   content,
 						CreatedAt: timestamp,
 					}).
+					Return(uint64(7), nil)
+			},
+			res: &pb.PublishResponse{
+				Id:        messageID,
+				Offset:    7,
+				CreatedAt: timestamp,
+			},
+			err: nil,
+		},
+		{
+			name: "success: published with a delay",
+			req: &pb.PublishRequest{
+				Channel: channel,
+				Content: content,
+				DelayMs: 30000,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
 					Return(nil)
+				mockGenerator.EXPECT().
+					GetUniqueMessageID().
+					Return(messageID)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				mockGenerator.EXPECT().
+					GetCurrentTimeMillis().
+					Return(timestamp * 1000)
+				mockService.EXPECT().
+					Publish(ctx, channel, &pb.Message{
+						Id:        messageID,
+						Content:   content,
+						CreatedAt: timestamp,
+						DeliverAt: timestamp*1000 + 30000,
+					}).
+					Return(uint64(0), nil)
+			},
+			res: &pb.PublishResponse{
+				Id:        messageID,
+				Offset:    0,
+				CreatedAt: timestamp,
+				DeliverAt: timestamp*1000 + 30000,
+			},
+			err: nil,
+		},
+		{
+			name: "success: published with a TTL",
+			req: &pb.PublishRequest{
+				Channel: channel,
+				Content: content,
+				TtlMs:   60000,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockGenerator.EXPECT().
+					GetUniqueMessageID().
+					Return(messageID)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				mockGenerator.EXPECT().
+					GetCurrentTimeMillis().
+					Return(timestamp * 1000)
+				mockService.EXPECT().
+					Publish(ctx, channel, &pb.Message{
+						Id:        messageID,
+						Content:   content,
+						CreatedAt: timestamp,
+						ExpiresAt: timestamp*1000 + 60000,
+					}).
+					Return(uint64(7), nil)
+			},
+			res: &pb.PublishResponse{
+				Id:        messageID,
+				Offset:    7,
+				CreatedAt: timestamp,
+				ExpiresAt: timestamp*1000 + 60000,
+			},
+			err: nil,
+		},
+		{
+			name: "success: published with headers",
+			req: &pb.PublishRequest{
+				Channel: channel,
+				Content: content,
+				Headers: map[string]string{"content-type": "application/json"},
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockGenerator.EXPECT().
+					GetUniqueMessageID().
+					Return(messageID)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				mockService.EXPECT().
+					Publish(ctx, channel, &pb.Message{
+						Id:        messageID,
+						Content:   content,
+						CreatedAt: timestamp,
+						Headers:   map[string]string{"content-type": "application/json"},
+					}).
+					Return(uint64(7), nil)
+			},
+			res: &pb.PublishResponse{
+				Id:        messageID,
+				Offset:    7,
+				CreatedAt: timestamp,
+			},
+			err: nil,
+		},
+		{
+			name: "success: deduplicated on producer ID and sequence",
+			req: &pb.PublishRequest{
+				Channel:    channel,
+				Content:    content,
+				ProducerId: "test-producer",
+				Sequence:   3,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockGenerator.EXPECT().
+					GetUniqueMessageID().
+					Return(messageID)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				mockService.EXPECT().
+					Publish(ctx, channel, &pb.Message{
+						Id:             messageID,
+						Content:        content,
+						CreatedAt:      timestamp,
+						IdempotencyKey: "\x00prod:test-producer:3",
+					}).
+					Return(uint64(7), nil)
+			},
+			res: &pb.PublishResponse{
+				Id:        messageID,
+				Offset:    7,
+				CreatedAt: timestamp,
+			},
+			err: nil,
+		},
+		{
+			name: "success: duplicate of a message published earlier",
+			req: &pb.PublishRequest{
+				Channel:        channel,
+				Content:        content,
+				IdempotencyKey: "test-key",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockGenerator.EXPECT().
+					GetUniqueMessageID().
+					Return(messageID)
+				mockGenerator.EXPECT().
+					GetCurrentTimestamp().
+					Return(timestamp)
+				mockService.EXPECT().
+					Publish(ctx, channel, &pb.Message{
+						Id:             messageID,
+						Content:        content,
+						CreatedAt:      timestamp,
+						IdempotencyKey: "test-key",
+					}).
+					DoAndReturn(func(_ context.Context, _ string, msg *pb.Message) (uint64, error) {
+						msg.Id = "original-message-id"
+						msg.CreatedAt = timestamp - 10
+						return uint64(3), nil
+					})
+			},
+			res: &pb.PublishResponse{
+				Id:        "original-message-id",
+				Offset:    3,
+				CreatedAt: timestamp - 10,
 			},
 			err: nil,
 		},
@@ -206,8 +595,9 @@ func TestPublishServer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setup()
-			_, err := server.Publish(ctx, tt.req)
+			res, err := server.Publish(ctx, tt.req)
 			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
 		})
 	}
 }
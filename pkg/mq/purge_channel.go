@@ -0,0 +1,93 @@
+// pkg/mq/purge_channel.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+// PurgeChannel drops the messages of the channel and of its partitions, the channel keeps its configuration and
+// its subscribers, which receive the messages published afterwards. The messages handed back to the subscriptions
+// of the channel, or in flight, are dropped as well.
+func (s *Service) PurgeChannel(
+	ctx context.Context,
+	channel string,
+) error {
+	if !validChannelName(channel) {
+		slog.Error(
+			"cannot purge channel with invalid name",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.storage.ChannelExists(channel) {
+		slog.Error(
+			"cannot purge non-existent channel",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.NotFound, ErrChannelDoesNotExist.Error())
+	}
+
+	if err := s.storage.PurgeChannel(channel); err != nil {
+		slog.Error(
+			"failed to purge channel",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return status.Error(codes.Unavailable, ErrUnableToPurgeChannel.Error())
+	}
+
+	n := s.partitions(channel)
+	for p := uint32(0); p < n; p++ {
+		partitionChannel := storage.PartitionChannel(channel, p)
+		for key, subscription := range s.subscriptions {
+			if key.channel == partitionChannel {
+				subscription.purge()
+			}
+		}
+	}
+
+	slog.Info(
+		"channel purged",
+		slog.String("channel", channel),
+	)
+
+	return nil
+}
+
+type purgeChannelInput struct {
+	Channel string `validate:"required"`
+}
+
+// gRPC implementation of the PurgeChannel method
+func (s *Server) PurgeChannel(
+	ctx context.Context,
+	req *pb.PurgeChannelRequest,
+) (*pb.PurgeChannelResponse, error) {
+	input := &purgeChannelInput{
+		Channel: req.GetChannel(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// Purge the channel
+	if err := s.srv.PurgeChannel(ctx, input.Channel); err != nil {
+		return nil, err
+	}
+
+	return &pb.PurgeChannelResponse{}, nil
+}
@@ -0,0 +1,169 @@
+// pkg/mq/purge_channel_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestPurgeChannelService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		channel string
+		setup   func()
+		err     error
+	}{
+		{
+			name:    "error: channel name with wildcards",
+			channel: "test-channel.*",
+			setup:   func() {},
+			err:     status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
+		{
+			name:    "error: channel does not exist",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(false)
+			},
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:    "error: purge channel storage error",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(true)
+				mockStorage.EXPECT().
+					PurgeChannel("test-channel").
+					Return(storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToPurgeChannel.Error()),
+		},
+		{
+			name:    "success: channel purged",
+			channel: "test-channel",
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(true)
+				mockStorage.EXPECT().
+					PurgeChannel("test-channel").
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			err := service.PurgeChannel(ctx, tt.channel)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestPurgeChannelServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+
+	tests := []struct {
+		name  string
+		req   *pb.PurgeChannelRequest
+		setup func()
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.PurgeChannelRequest{
+				Channel: "",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: purge channel storage error",
+			req: &pb.PurgeChannelRequest{
+				Channel: channel,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					PurgeChannel(ctx, channel).
+					Return(status.Error(codes.Unavailable, ErrUnableToPurgeChannel.Error()))
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToPurgeChannel.Error()),
+		},
+		{
+			name: "success: channel purged",
+			req: &pb.PurgeChannelRequest{
+				Channel: channel,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					PurgeChannel(ctx, channel).
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			_, err := server.PurgeChannel(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// pkg/mq/reject.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// Reject reports the messages delivered with the specified ack ids as impossible to process, they are
+// redelivered until they exceed the max delivery attempts of the channel and are then dead-lettered.
+// Messages that are no longer in flight (already acknowledged, or redelivered) are ignored.
+func (s *Service) Reject(
+	ctx context.Context,
+	channel string,
+	ackIDs []string,
+	reason string,
+) error {
+	deliveries, err := s.resolveAckIDs(channel, ackIDs)
+	if err != nil {
+		return err
+	}
+
+	if reason == "" {
+		reason = reasonRejected
+	}
+
+	for _, d := range deliveries {
+		d.subscription.fail(d.messageID, reason)
+	}
+
+	slog.Info(
+		"messages rejected",
+		slog.String("channel", channel),
+		slog.Int("count", len(deliveries)),
+		slog.String("reason", reason),
+	)
+	return nil
+}
+
+type rejectInput struct {
+	Channel string   `validate:"required"`
+	AckIDs  []string `validate:"required,min=1,dive,required"`
+	Reason  string
+}
+
+// gRPC implementation of the Reject method
+func (s *Server) Reject(
+	ctx context.Context,
+	req *pb.RejectRequest,
+) (*pb.RejectResponse, error) {
+	input := &rejectInput{
+		Channel: req.GetChannel(),
+		AckIDs:  req.GetAckIds(),
+		Reason:  req.GetReason(),
+	}
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// Reject the messages
+	if err := s.srv.Reject(ctx, input.Channel, input.AckIDs, input.Reason); err != nil {
+		return nil, err
+	}
+
+	return &pb.RejectResponse{}, nil
+}
@@ -0,0 +1,170 @@
+// pkg/mq/reject_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+func TestRejectService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: "unique-subscriber-id",
+	}
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+	}
+
+	subscription := newSubscription(key, time.Minute, nil, nil, nil)
+	service.subscriptions[key] = subscription
+	delivery := subscription.deliver(msg)
+
+	tests := []struct {
+		name   string
+		ackIDs []string
+		err    error
+	}{
+		{
+			name:   "error: invalid ack id",
+			ackIDs: []string{"invalid-ack-id"},
+			err:    status.Error(codes.InvalidArgument, ErrInvalidAckID.Error()),
+		},
+		{
+			name:   "error: subscription does not exist",
+			ackIDs: []string{ackID("unknown-subscriber-id", msg.GetId())},
+			err:    status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error()),
+		},
+		{
+			name:   "success: message rejected",
+			ackIDs: []string{delivery.GetAckId()},
+			err:    nil,
+		},
+		{
+			name:   "success: message already rejected",
+			ackIDs: []string{delivery.GetAckId()},
+			err:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Reject(ctx, channel, tt.ackIDs, "test-reason")
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	// The message rejected is redelivered once, and its delivery attempt is kept
+	assert.Empty(t, subscription.inflight)
+	assert.Equal(t, []*pb.Message{msg}, subscription.takePending())
+	assert.Equal(t, uint32(2), subscription.deliver(msg).GetDeliveryAttempt())
+}
+
+func TestRejectServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	ackIDs := []string{ackID("unique-subscriber-id", "unique-message-id")}
+
+	tests := []struct {
+		name  string
+		req   *pb.RejectRequest
+		setup func()
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.RejectRequest{
+				Channel: channel,
+				AckIds:  nil,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: subscription does not exist",
+			req: &pb.RejectRequest{
+				Channel: channel,
+				AckIds:  ackIDs,
+				Reason:  "test-reason",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					Reject(ctx, channel, ackIDs, "test-reason").
+					Return(status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error()))
+			},
+			err: status.Error(codes.NotFound, ErrSubscriptionDoesNotExist.Error()),
+		},
+		{
+			name: "success: messages rejected",
+			req: &pb.RejectRequest{
+				Channel: channel,
+				AckIds:  ackIDs,
+				Reason:  "test-reason",
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					Reject(ctx, channel, ackIDs, "test-reason").
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			_, err := server.Reject(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -5,7 +5,6 @@ package mq
 import (
 	"context"
 	"log/slog"
-	"sync"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -21,20 +20,94 @@ const (
 
 	// OffsetLatest is the offset to start reading messages from the latest
 	OffsetLatest uint64 = ^uint64(0)
+
+	// groupCursorPrefix is the prefix of the cursor shared by the members of a consumer group
+	groupCursorPrefix = "grp"
+
+	// durableCursorPrefix is the prefix of the cursor of a durable subscription
+	durableCursorPrefix = "dur"
 )
 
-// Subscribe add the subscriber to the specified channel
+// cursorID returns the ID under which the storage layer tracks the position of the subscriber
+// in a channel, members of a consumer group share the cursor of their group and subscribers
+// of a durable subscription share the cursor of their subscription
+func cursorID(sub *pb.Subscriber) string {
+	if sub.GetGroup() != "" {
+		return groupCursorPrefix + sub.GetGroup()
+	}
+
+	if sub.GetDurableName() != "" {
+		return durableCursorPrefix + sub.GetDurableName()
+	}
+
+	return sub.GetId()
+}
+
+// commitOffset returns the function persisting the committed offset of a durable subscription
+func (s *Service) commitOffset(key subscriptionKey) commitFunc {
+	return func(offset uint64) error {
+		return s.storage.CommitOffset(key.channel, key.cursorID, offset)
+	}
+}
+
+// keepsCursor reports whether the cursor of the subscriber outlives it, so that the next
+// subscriber of the same consumer group or durable subscription resumes from it
+func keepsCursor(sub *pb.Subscriber) bool {
+	return sub.GetGroup() != "" || sub.GetDurableName() != ""
+}
+
+// subscribeOptions are the options a subscriber subscribes to a channel with, once its filter is parsed
+type subscribeOptions struct {
+	offset       pb.Offset
+	startOffset  uint64
+	startTime    int64
+	pullInterval uint64
+	ackDeadline  uint64
+	filter       filter
+	tagChannel   bool
+}
+
+// Subscribe add the subscriber to the specified channel, or to every channel matching the specified pattern.
+// The messages are sent to msgChan, and the error the subscription to a channel ends with, if any, to errChan.
+// The subscription to a single channel matching a pattern ends on its own, without an error.
 func (s *Service) Subscribe(
 	ctx context.Context,
 	sub *pb.Subscriber,
-	offset pb.Offset,
-	pullInterval uint64,
 	channel string,
+	options *pb.SubscribeOptions,
 	msgChan chan<- *pb.Message,
+	errChan chan<- error,
 ) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Parse the filter of the subscriber
+	filter, err := parseFilter(options.GetFilter())
+	if err != nil {
+		slog.Error(
+			"invalid filter",
+			slog.String("channel", channel),
+			slog.String("filter", options.GetFilter()),
+			slog.Any("error", err),
+		)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	opts := subscribeOptions{
+		offset:       options.GetOffset(),
+		startOffset:  options.GetStartOffset(),
+		startTime:    options.GetStartTime(),
+		pullInterval: options.GetPullInterval(),
+		ackDeadline:  options.GetAckDeadline(),
+		filter:       filter,
+		tagChannel:   false,
+	}
+
+	// Subscribe to every matching channel, the channels created later on included
+	if isWildcard(channel) {
+		return s.subscribeWildcard(ctx, sub, channel, opts, msgChan)
+	}
+
 	// Check if the channel exists
 	if !s.storage.ChannelExists(channel) {
 		slog.Error(
@@ -44,64 +117,139 @@ func (s *Service) Subscribe(
 		return status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error())
 	}
 
+	// Check if the channel allows another subscriber
+	if s.subscriberLimitReached(channel) {
+		slog.Error(
+			"cannot subscribe to channel at its max subscribers",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.ResourceExhausted, ErrTooManySubscribers.Error())
+	}
+
+	return s.subscribePartitions(ctx, sub, channel, opts, msgChan, reportTo(errChan))
+}
+
+// subscriberLimitReached reports whether the channel has as many subscribers connected as it allows, every subscriber
+// is subscribed to the first partition of the channel. The caller must hold the lock of the service.
+func (s *Service) subscriberLimitReached(channel string) bool {
+	config, err := s.storage.GetChannelConfig(channel)
+	if err != nil || config.GetMaxSubscribers() == 0 {
+		return false
+	}
+
+	return uint32(len(s.channelToSubscribers[channel])) >= config.GetMaxSubscribers()
+}
+
+// subscribeChannel adds the subscriber to the channel (or to a partition of a channel) and returns the
+// subscription it reads the channel through, the caller must hold the lock of the service and forward
+// the messages of the subscription to the subscriber
+func (s *Service) subscribeChannel(
+	sub *pb.Subscriber,
+	channel string,
+	opts subscribeOptions,
+) (*subscription, error) {
+	// Read messages from the storage layer and send them to the subscriber
+	var currentOffset uint64 = 0
+	switch opts.offset {
+	case pb.Offset_OFFSET_BEGINNING:
+		currentOffset = OffsetBeginning
+	case pb.Offset_OFFSET_LATEST:
+		currentOffset = OffsetLatest
+	case pb.Offset_OFFSET_START_OFFSET:
+		currentOffset = opts.startOffset
+	case pb.Offset_OFFSET_START_TIME:
+		// Start from the first message created at or after the start time
+		timeOffset, err := s.storage.GetOffsetByTime(channel, opts.startTime)
+		if err != nil {
+			slog.Error(
+				"failed to look up offset by time",
+				slog.String("channel", channel),
+				slog.Int64("start_time", opts.startTime),
+				slog.Any("error", err),
+			)
+			return nil, status.Error(codes.Unavailable, ErrUnableToLookUpOffset.Error())
+		}
+		currentOffset = timeOffset
+	default:
+		return nil, status.Error(codes.InvalidArgument, "invalid offset")
+	}
+
+	// Members of a consumer group share the subscription of their group, so every
+	// message of the channel is delivered to only one of them. The members connected
+	// at once share the ack deadline and the filter of the subscription as well, the
+	// first member to reconnect to a subscription kept without members sets them.
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: cursorID(sub),
+	}
+	subscription, exists := s.subscriptions[key]
+	if exists && subscription.members == 0 {
+		subscription.setOptions(opts)
+	}
+	if exists && subscription.members > 0 && !subscription.sameOptions(opts) {
+		slog.Error(
+			"cannot join subscription with a different ack deadline or filter",
+			slog.String("channel", channel),
+			slog.String("group", sub.GetGroup()),
+			slog.String("durable_name", sub.GetDurableName()),
+		)
+		return nil, status.Error(codes.FailedPrecondition, ErrSubscriptionOptionsMismatch.Error())
+	}
+
 	// Initialize the channel to subscribers map, if the channel does not exist
 	if _, exists := s.channelToSubscribers[channel]; !exists {
-		s.channelToSubscribers[channel] = make(map[*pb.Subscriber]struct{}, 0)
+		s.channelToSubscribers[channel] = make(map[*pb.Subscriber]time.Time, 0)
 	}
 
-	// Add the subscriber to the channel
-	s.channelToSubscribers[channel][sub] = struct{}{}
+	// Add the subscriber to the channel, along with the time it subscribed at
+	s.channelToSubscribers[channel][sub] = time.Now()
 	slog.Info(
 		"subscriber added",
 		slog.String("id", sub.GetId()),
 		slog.String("ip", sub.GetIp()),
+		slog.String("group", sub.GetGroup()),
+		slog.String("durable_name", sub.GetDurableName()),
 		slog.String("channel", channel),
 	)
 
-	// Read messages from the storage layer and send them to the subscriber
-	var currentOffset uint64 = 0
-	switch offset {
-	case pb.Offset_OFFSET_BEGINNING:
-		currentOffset = OffsetBeginning
-	case pb.Offset_OFFSET_LATEST:
-		currentOffset = OffsetLatest
-	default:
-		return status.Error(codes.InvalidArgument, "invalid offset")
-	}
-
-	var subID string = sub.GetId()
-	go func() {
-		// Read messages from the storage layer endlessly at the specified interval
-		ticker := time.NewTicker(time.Duration(pullInterval) * time.Millisecond)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				messages, nextOffset, err := s.storage.GetMessages(
-					channel,
-					subID,
-					currentOffset,
-				)
-				if err == nil {
-					currentOffset = nextOffset + 1
-
-					for _, msg := range messages {
-						msgChan <- msg
-					}
-				}
-			}
+	if !exists {
+		// Only durable subscriptions commit the offset they resume from
+		var commit commitFunc
+		if sub.GetDurableName() != "" {
+			commit = s.commitOffset(key)
 		}
-	}()
 
-	return nil
+		subscription = newSubscription(
+			key,
+			time.Duration(opts.ackDeadline)*time.Millisecond,
+			opts.filter,
+			s.deadLetter(channelOf(channel)),
+			commit,
+		)
+		s.subscriptions[key] = subscription
+	}
+
+	// Start reading the channel when the first member joins
+	if subscription.members == 0 {
+		readCtx, cancel := context.WithCancel(context.Background())
+		subscription.cancel = cancel
+		go s.readChannel(readCtx, subscription, currentOffset, opts.pullInterval)
+	}
+	subscription.members++
+
+	return subscription, nil
 }
 
 type subscribeInput struct {
 	Channel      string    `validate:"required"`
 	Offset       pb.Offset `validate:"required,offset"`
-	PullInterval uint64    `validate:"required,gte=0"`
+	StartOffset  uint64
+	StartTime    int64  `validate:"gte=0"`
+	PullInterval uint64 `validate:"gte=0"`
+	Group        string
+	AckDeadline  uint64 `validate:"gte=0"`
+	DurableName  string `validate:"excluded_with=Group"`
+	Filter       string `validate:"max=4096"`
 }
 
 // gRPC implementation of the Subscribe method
@@ -112,7 +260,13 @@ func (s *Server) Subscribe(
 	input := &subscribeInput{
 		Channel:      req.GetChannel(),
 		Offset:       req.GetOffset(),
+		StartOffset:  req.GetStartOffset(),
+		StartTime:    req.GetStartTime(),
 		PullInterval: req.GetPullInterval(),
+		Group:        req.GetGroup(),
+		AckDeadline:  req.GetAckDeadline(),
+		DurableName:  req.GetDurableName(),
+		Filter:       req.GetFilter(),
 	}
 
 	// Validate the input request
@@ -133,17 +287,19 @@ func (s *Server) Subscribe(
 
 	// Create a new subscriber
 	sub := &pb.Subscriber{
-		Id: s.generator.GetUniqueSubscriberID(),
-		Ip: ip,
+		Id:          s.generator.GetUniqueSubscriberID(),
+		Ip:          ip,
+		Group:       input.Group,
+		DurableName: input.DurableName,
 	}
 
-	// Create a new message channel
+	// Create a new message channel, it is owned and written to by the mq service, along
+	// with a channel for the error the subscription ends with
 	msgChan := make(chan *pb.Message)
-	var closeOnce sync.Once
+	errChan := make(chan error, 1)
 
-	// Defer the unsubscription and closing of the channel
+	// Unsubscribe when the stream ends
 	defer func() {
-		// Unsubscribe when the stream ends
 		slog.Warn(
 			"warn: unsubscribing client",
 			slog.String("id", sub.GetId()),
@@ -151,19 +307,23 @@ func (s *Server) Subscribe(
 			slog.String("channel", input.Channel),
 		)
 		_ = s.srv.UnSubscribe(stream.Context(), sub, input.Channel)
-		closeOnce.Do(func() {
-			close(msgChan)
-		})
 	}()
 
 	// Subscribe the client to the channel
 	if err := s.srv.Subscribe(
 		stream.Context(),
 		sub,
-		input.Offset,
-		input.PullInterval,
 		input.Channel,
+		&pb.SubscribeOptions{
+			Offset:       input.Offset,
+			StartOffset:  input.StartOffset,
+			StartTime:    input.StartTime,
+			PullInterval: input.PullInterval,
+			AckDeadline:  input.AckDeadline,
+			Filter:       input.Filter,
+		},
 		msgChan,
+		errChan,
 	); err != nil {
 		slog.Error(
 			"failed to subscribe",
@@ -175,24 +335,16 @@ func (s *Server) Subscribe(
 		return err
 	}
 
-	// Stream the messages
+	// Stream the messages as soon as they are pushed by the mq service
 	for {
 		select {
-		case msg, ok := <-msgChan:
-			if !ok {
-				return nil
-			}
-
-			// convert message to proto message
+		case msg := <-msgChan:
 			if err := stream.Send(msg); err != nil {
 				return status.Error(codes.Unavailable, "failed to send message")
 			}
+		case err := <-errChan:
+			return err
 		case <-stream.Context().Done():
-			// Unsubscribe and close the channel only if it hasn't been closed yet
-			closeOnce.Do(func() {
-				_ = s.srv.UnSubscribe(stream.Context(), sub, input.Channel)
-				close(msgChan)
-			})
 			return nil
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/hitesh22rana/mq/pkg/mocks"
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
 )
 
 func TestSubscribeService(t *testing.T) {
@@ -22,6 +24,7 @@ func TestSubscribeService(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
 
 	service := NewService(
 		&ServiceOptions{
@@ -35,26 +38,29 @@ func TestSubscribeService(t *testing.T) {
 		Ip: "ip-address",
 	}
 	pullInterval := uint64(1000)
+	startTime := int64(1234567890)
 	channel := "test-channel"
 	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	type inputs struct {
+		offset       pb.Offset
+		startOffset  uint64
+		startTime    int64
+		pullInterval uint64
+		filter       string
+		channel      string
+	}
 
 	tests := []struct {
 		name   string
-		inputs struct {
-			offset       pb.Offset
-			pullInterval uint64
-			channel      string
-		}
-		setup func()
-		err   error
+		inputs inputs
+		setup  func()
+		err    error
 	}{
 		{
 			name: "error: channel does not exist",
-			inputs: struct {
-				offset       pb.Offset
-				pullInterval uint64
-				channel      string
-			}{
+			inputs: inputs{
 				offset:       pb.Offset_OFFSET_BEGINNING,
 				pullInterval: pullInterval,
 				channel:      channel,
@@ -68,11 +74,7 @@ func TestSubscribeService(t *testing.T) {
 		},
 		{
 			name: "error: invalid offset",
-			inputs: struct {
-				offset       pb.Offset
-				pullInterval uint64
-				channel      string
-			}{
+			inputs: inputs{
 				offset:       pb.Offset_OFFSET_UNKNOWN,
 				pullInterval: pullInterval,
 				channel:      channel,
@@ -84,6 +86,35 @@ func TestSubscribeService(t *testing.T) {
 			},
 			err: status.Error(codes.InvalidArgument, "invalid offset"),
 		},
+		{
+			name: "error: invalid filter",
+			inputs: inputs{
+				offset:       pb.Offset_OFFSET_BEGINNING,
+				pullInterval: pullInterval,
+				filter:       "type = ",
+				channel:      channel,
+			},
+			setup: func() {},
+			err:   status.Error(codes.InvalidArgument, ErrInvalidFilter.Error()+": unexpected end of expression"),
+		},
+		{
+			name: "error: start time lookup failed",
+			inputs: inputs{
+				offset:       pb.Offset_OFFSET_START_TIME,
+				startTime:    startTime,
+				pullInterval: pullInterval,
+				channel:      channel,
+			},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists(channel).
+					Return(true)
+				mockStorage.EXPECT().
+					GetOffsetByTime(channel, startTime).
+					Return(uint64(0), storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToLookUpOffset.Error()),
+		},
 	}
 
 	for _, tt := range tests {
@@ -92,16 +123,167 @@ func TestSubscribeService(t *testing.T) {
 			err := service.Subscribe(
 				ctx,
 				sub,
-				tt.inputs.offset,
-				tt.inputs.pullInterval,
 				tt.inputs.channel,
+				&pb.SubscribeOptions{
+					Offset:       tt.inputs.offset,
+					StartOffset:  tt.inputs.startOffset,
+					StartTime:    tt.inputs.startTime,
+					PullInterval: tt.inputs.pullInterval,
+					AckDeadline:  0,
+					Filter:       tt.inputs.filter,
+				},
 				msgChan,
+				errChan,
 			)
 			assert.Equal(t, tt.err, err)
 		})
 	}
 }
 
+func TestSubscribeServicePush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := &pb.Subscriber{
+		Id: "unique-subscriber-id",
+		Ip: "ip-address",
+	}
+	channel := "test-channel"
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+	}
+
+	notify := make(chan struct{})
+	idle := make(chan struct{})
+
+	gomock.InOrder(
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true),
+		// Nothing to read yet, the subscriber waits for a notification
+		mockStorage.EXPECT().
+			WatchChannel(channel).
+			Return((<-chan struct{})(notify), nil),
+		mockStorage.EXPECT().
+			GetMessages(channel, sub.GetId(), OffsetBeginning).
+			Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset),
+		// Woken up by the notification, the message is read and pushed
+		mockStorage.EXPECT().
+			WatchChannel(channel).
+			Return((<-chan struct{})(idle), nil),
+		mockStorage.EXPECT().
+			GetMessages(channel, sub.GetId(), OffsetBeginning).
+			Return([]*pb.Message{msg}, uint64(0), nil),
+		// Caught up with the channel, the subscriber goes back to waiting
+		mockStorage.EXPECT().
+			WatchChannel(channel).
+			Return((<-chan struct{})(idle), nil).
+			AnyTimes(),
+		mockStorage.EXPECT().
+			GetMessages(channel, sub.GetId(), uint64(1)).
+			Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+			AnyTimes(),
+	)
+
+	err := service.Subscribe(
+		ctx,
+		sub,
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		errChan,
+	)
+	assert.NoError(t, err)
+
+	// No message must be pushed before the channel is notified
+	select {
+	case <-msgChan:
+		t.Fatal("message pushed before the channel was notified")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(notify)
+
+	select {
+	case got := <-msgChan:
+		assert.Equal(t, msg, got)
+	case <-time.After(time.Second):
+		t.Fatal("message was not pushed after the channel was notified")
+	}
+
+	// The channel is no longer read once the subscriber leaves
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true)
+	mockStorage.EXPECT().
+		RemoveChannelFromSubscriberMap(channel, sub.GetId()).
+		Return()
+	cancel()
+	assert.NoError(t, service.UnSubscribe(ctx, sub, channel))
+	assert.Empty(t, service.subscriptions)
+}
+
+func TestCursorID(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  *pb.Subscriber
+		want string
+	}{
+		{
+			name: "subscriber without group",
+			sub: &pb.Subscriber{
+				Id: "unique-subscriber-id",
+			},
+			want: "unique-subscriber-id",
+		},
+		{
+			name: "members of a group share the group cursor",
+			sub: &pb.Subscriber{
+				Id:    "unique-subscriber-id",
+				Group: "test-group",
+			},
+			want: groupCursorPrefix + "test-group",
+		},
+		{
+			name: "subscribers of a durable subscription share its cursor",
+			sub: &pb.Subscriber{
+				Id:          "unique-subscriber-id",
+				DurableName: "test-durable-name",
+			},
+			want: durableCursorPrefix + "test-durable-name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cursorID(tt.sub))
+		})
+	}
+}
+
 func TestSubscribeServer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -198,9 +380,16 @@ func TestSubscribeServer(t *testing.T) {
 					Subscribe(
 						ctx,
 						gomock.Any(),
-						pb.Offset_OFFSET_BEGINNING,
-						uint64(1000),
 						channel,
+						&pb.SubscribeOptions{
+							Offset:       pb.Offset_OFFSET_BEGINNING,
+							StartOffset:  0,
+							StartTime:    0,
+							PullInterval: 1000,
+							AckDeadline:  0,
+							Filter:       "",
+						},
+						gomock.Any(),
 						gomock.Any(),
 					).
 					Return(status.Error(codes.FailedPrecondition, ErrChannelDoesNotExist.Error()))
@@ -220,3 +409,317 @@ func TestSubscribeServer(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscribeOffsetOutOfRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	sub := &pb.Subscriber{
+		Id:    "unique-subscriber-id",
+		Ip:    "ip-address",
+		Group: "test-group",
+	}
+	channel := "test-channel"
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	// The messages the group had to read next have been evicted
+	gomock.InOrder(
+		mockStorage.EXPECT().
+			ChannelExists(channel).
+			Return(true),
+		mockStorage.EXPECT().
+			WatchChannel(channel).
+			Return((<-chan struct{})(make(chan struct{})), nil),
+		mockStorage.EXPECT().
+			GetMessages(channel, cursorID(sub), OffsetBeginning).
+			Return([]*pb.Message(nil), uint64(0), storage.ErrOffsetOutOfRange),
+	)
+
+	err := service.Subscribe(
+		ctx,
+		sub,
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		errChan,
+	)
+	assert.NoError(t, err)
+
+	select {
+	case err := <-errChan:
+		assert.Equal(t, status.Error(codes.OutOfRange, ErrOffsetOutOfRange.Error()), err)
+	case <-time.After(time.Second):
+		t.Fatal("the failure of the subscription was not reported")
+	}
+
+	// The subscription of the group is removed once it has failed, so that the group starts over
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true)
+	assert.NoError(t, service.UnSubscribe(ctx, sub, channel))
+	assert.Empty(t, service.subscriptions)
+}
+
+func TestSubscribeGroupOptionsMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	// The channels have a single partition
+	mockStorage.EXPECT().
+		GetChannelConfig(gomock.Any()).
+		Return(&pb.ChannelConfig{}, nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		ChannelExists(gomock.Any()).
+		Return(true).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	channel := "test-channel"
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	// A member of the group is connected, with an ack deadline and a filter
+	filter, err := parseFilter("type = created")
+	assert.NoError(t, err)
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: cursorID(&pb.Subscriber{Group: "test-group"}),
+	}
+	subscription := newSubscription(key, time.Second, filter, nil, nil)
+	subscription.members = 1
+	service.subscriptions[key] = subscription
+
+	tests := []struct {
+		name        string
+		ackDeadline uint64
+		filter      string
+		err         error
+	}{
+		{
+			name:        "error: different ack deadline",
+			ackDeadline: 2000,
+			filter:      "type = created",
+			err:         status.Error(codes.FailedPrecondition, ErrSubscriptionOptionsMismatch.Error()),
+		},
+		{
+			name:        "error: different filter",
+			ackDeadline: 1000,
+			filter:      "type = deleted",
+			err:         status.Error(codes.FailedPrecondition, ErrSubscriptionOptionsMismatch.Error()),
+		},
+		{
+			name:        "error: no filter",
+			ackDeadline: 1000,
+			filter:      "",
+			err:         status.Error(codes.FailedPrecondition, ErrSubscriptionOptionsMismatch.Error()),
+		},
+		{
+			name:        "success: same options, written differently",
+			ackDeadline: 1000,
+			filter:      "type  =  'created'",
+			err:         nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &pb.Subscriber{
+				Id:    "unique-subscriber-id",
+				Ip:    "ip-address",
+				Group: "test-group",
+			}
+			err := service.Subscribe(
+				ctx,
+				sub,
+				channel,
+				&pb.SubscribeOptions{
+					Offset:       pb.Offset_OFFSET_BEGINNING,
+					StartOffset:  0,
+					StartTime:    0,
+					PullInterval: 0,
+					AckDeadline:  tt.ackDeadline,
+					Filter:       tt.filter,
+				},
+				msgChan,
+				errChan,
+			)
+			assert.Equal(t, tt.err, err)
+
+			// A subscriber refused does not join the channel
+			_, subscribed := service.channelToSubscribers[channel][sub]
+			assert.Equal(t, tt.err == nil, subscribed)
+		})
+	}
+}
+
+func TestSubscribeDurableOptionsReplaced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	channel := "test-channel"
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+	newSubscriber := func(id string) *pb.Subscriber {
+		return &pb.Subscriber{
+			Id:          id,
+			Ip:          "ip-address",
+			DurableName: "test-durable",
+		}
+	}
+
+	// The durable subscription is kept with its filter, nobody is connected to it
+	filter, err := parseFilter("type = created")
+	assert.NoError(t, err)
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: cursorID(newSubscriber("")),
+	}
+	subscription := newSubscription(key, 0, filter, nil, nil)
+	service.subscriptions[key] = subscription
+
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true).
+		AnyTimes()
+	mockStorage.EXPECT().
+		WatchChannel(channel).
+		Return((<-chan struct{})(make(chan struct{})), nil).
+		AnyTimes()
+	mockStorage.EXPECT().
+		GetMessages(channel, key.cursorID, OffsetBeginning).
+		Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+		AnyTimes()
+
+	subscribe := func(sub *pb.Subscriber, filter string) error {
+		return service.Subscribe(
+			ctx,
+			sub,
+			channel,
+			&pb.SubscribeOptions{
+				Offset:       pb.Offset_OFFSET_BEGINNING,
+				StartOffset:  0,
+				StartTime:    0,
+				PullInterval: 0,
+				AckDeadline:  1000,
+				Filter:       filter,
+			},
+			msgChan,
+			errChan,
+		)
+	}
+
+	// The subscriber reconnecting with another filter and ack deadline replaces them
+	assert.NoError(t, subscribe(newSubscriber("first-subscriber-id"), "type = deleted"))
+	assert.Same(t, subscription, service.subscriptions[key])
+	assert.True(t, subscription.sameOptions(subscribeOptions{
+		offset:       pb.Offset_OFFSET_BEGINNING,
+		startOffset:  0,
+		startTime:    0,
+		pullInterval: 0,
+		ackDeadline:  1000,
+		filter:       &equalFilter{key: "type", value: "deleted", negate: false},
+		tagChannel:   false,
+	}))
+
+	// The subscribers connected afterwards share them
+	assert.Equal(
+		t,
+		status.Error(codes.FailedPrecondition, ErrSubscriptionOptionsMismatch.Error()),
+		subscribe(newSubscriber("second-subscriber-id"), "type = created"),
+	)
+}
+
+func TestSubscribeMaxSubscribers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	// The channel has a single partition, and allows a single subscriber
+	mockStorage.EXPECT().
+		GetChannelConfig(gomock.Any()).
+		Return(&pb.ChannelConfig{MaxSubscribers: 1}, nil).
+		AnyTimes()
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	// The channel already has a subscriber connected
+	service.channelToSubscribers[channel] = map[*pb.Subscriber]time.Time{
+		{Id: "connected-subscriber-id"}: time.Now(),
+	}
+	assert.True(t, service.subscriberLimitReached(channel))
+
+	mockStorage.EXPECT().
+		ChannelExists(channel).
+		Return(true)
+	err := service.Subscribe(
+		ctx,
+		&pb.Subscriber{Id: "unique-subscriber-id"},
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		errChan,
+	)
+	assert.Equal(t, status.Error(codes.ResourceExhausted, ErrTooManySubscribers.Error()), err)
+
+	// The channel allows another subscriber once the connected one leaves
+	service.channelToSubscribers[channel] = map[*pb.Subscriber]time.Time{}
+	assert.False(t, service.subscriberLimitReached(channel))
+}
@@ -0,0 +1,681 @@
+// pkg/mq/subscription.go
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+const (
+	// ackIDSeparator separates the cursor of the subscription from the message ID in an ack id
+	ackIDSeparator = ":"
+
+	// reasonAckDeadlineExceeded is the failure reason of a message not acknowledged in time
+	reasonAckDeadlineExceeded = "ack deadline exceeded"
+
+	// reasonNacked is the failure reason of a message handed back by a subscriber
+	reasonNacked = "message nacked"
+
+	// reasonRejected is the failure reason of a message rejected by a subscriber without a reason
+	reasonRejected = "message rejected"
+
+	// commitInterval is the longest a durable subscription waits before committing the offset it has consumed up to,
+	// the messages consumed meanwhile are committed at once
+	commitInterval = 100 * time.Millisecond
+)
+
+// deadLetterFunc moves a message that failed to be processed to a dead-letter channel,
+// it returns false if the message has to be redelivered instead
+type deadLetterFunc func(msg *pb.Message, attempts uint32, reason string) bool
+
+// commitFunc persists the offset a durable subscription resumes its channel from
+type commitFunc func(offset uint64) error
+
+// failFunc is called with the subscription a member was forwarded the messages of once it fails
+type failFunc func(sub *subscription)
+
+// reportTo returns the function reporting the failure of a subscription to errChan,
+// unless a failure has been reported to it already
+func reportTo(errChan chan<- error) failFunc {
+	return func(sub *subscription) {
+		select {
+		case errChan <- sub.err:
+		default:
+		}
+	}
+}
+
+// ackID returns the ack id of a message delivered through the subscription with the given cursor
+func ackID(cursorID string, messageID string) string {
+	return cursorID + ackIDSeparator + messageID
+}
+
+// parseAckID returns the cursor of the subscription and the message ID of an ack id
+func parseAckID(id string) (string, string, error) {
+	// Message IDs never contain the separator, while group names might
+	i := strings.LastIndex(id, ackIDSeparator)
+	if i <= 0 || i == len(id)-len(ackIDSeparator) {
+		return "", "", ErrInvalidAckID
+	}
+
+	return id[:i], id[i+len(ackIDSeparator):], nil
+}
+
+// subscriptionKey identifies a subscription by its channel and cursor
+type subscriptionKey struct {
+	channel  string
+	cursorID string
+}
+
+// subscription reads a channel through a single storage cursor and hands its messages out
+// to the members of the subscription in turns. A subscriber has a subscription of its own,
+// while the members of a consumer group share the subscription of their group.
+//
+// When the subscription has an ack deadline, every message delivered is kept in flight until
+// it is acknowledged, and is delivered again if it is not acknowledged in time, is nacked or is
+// rejected. A message that keeps failing is moved to the dead-letter channel of its channel.
+//
+// A subscription with a filter only delivers the messages the filter matches, the other messages
+// are consumed as soon as they are read.
+//
+// A durable subscription commits the offset right after the messages it has consumed, so that
+// it resumes from there after a restart. The messages read but not consumed yet are kept in
+// outstanding, in the order they were read in, and the committed offset never moves past the
+// first of them. The offset is committed at most once every commit interval, and right away once
+// the subscription is stopped or the service is closed.
+//
+// A subscription that falls behind the retention of its channel fails, the failure is reported
+// to its members and the subscription is removed once they have all left. The members subscribed
+// through a pattern leave the channel instead.
+type subscription struct {
+	mu          sync.Mutex
+	key         subscriptionKey
+	messages    chan *pb.Message
+	pending     []*pb.Message
+	wake        chan struct{}
+	members     int
+	cancel      context.CancelFunc
+	ackDeadline time.Duration
+	filter      filter
+	inflight    map[string]*inflightMessage
+	attempts    map[string]uint32
+	deadLetter  deadLetterFunc
+	commit      commitFunc
+	outstanding map[string]uint64
+	readOrder   []string
+	readOffset  uint64
+	consumed    uint64
+	committed   uint64
+	commitTimer *time.Timer
+	position    uint64
+	failed      chan struct{}
+	err         error
+}
+
+// inflightMessage is a message delivered to a member, waiting to be acknowledged
+type inflightMessage struct {
+	msg   *pb.Message
+	timer *time.Timer
+}
+
+// newSubscription returns a new subscription, the channel is not read until it is started
+func newSubscription(
+	key subscriptionKey,
+	ackDeadline time.Duration,
+	filter filter,
+	deadLetter deadLetterFunc,
+	commit commitFunc,
+) *subscription {
+	return &subscription{
+		mu:          sync.Mutex{},
+		key:         key,
+		messages:    make(chan *pb.Message),
+		pending:     make([]*pb.Message, 0),
+		wake:        make(chan struct{}, 1),
+		members:     0,
+		cancel:      nil,
+		ackDeadline: ackDeadline,
+		filter:      filter,
+		inflight:    make(map[string]*inflightMessage),
+		attempts:    make(map[string]uint32),
+		deadLetter:  deadLetter,
+		commit:      commit,
+		outstanding: make(map[string]uint64),
+		readOrder:   make([]string, 0),
+		readOffset:  0,
+		consumed:    0,
+		committed:   0,
+		commitTimer: nil,
+		position:    0,
+		failed:      make(chan struct{}),
+		err:         nil,
+	}
+}
+
+// sameOptions reports whether the subscription has the ack deadline and the filter of the options
+func (sub *subscription) sameOptions(opts subscribeOptions) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	return sub.ackDeadline == time.Duration(opts.ackDeadline)*time.Millisecond && reflect.DeepEqual(sub.filter, opts.filter)
+}
+
+// setOptions replaces the ack deadline and the filter of the subscription with the ones of the options,
+// once no member is connected to it
+func (sub *subscription) setOptions(opts subscribeOptions) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	sub.ackDeadline = time.Duration(opts.ackDeadline) * time.Millisecond
+	sub.filter = opts.filter
+}
+
+// requeue hands back messages that could not be delivered, they are delivered again before any new message
+func (sub *subscription) requeue(msgs ...*pb.Message) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	sub.requeueLocked(msgs...)
+}
+
+// requeueLocked requeues the messages, the caller must hold the lock of the subscription
+func (sub *subscription) requeueLocked(msgs ...*pb.Message) {
+	sub.pending = append(sub.pending, msgs...)
+
+	// Wake up the reader if it is waiting for new messages
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver returns the message to send to a member, a message of a subscription with an
+// ack deadline is tracked until it is acknowledged, and is redelivered once the deadline passes
+func (sub *subscription) deliver(msg *pb.Message) *pb.Message {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.ackDeadline == 0 {
+		return msg
+	}
+
+	id := msg.GetId()
+	sub.attempts[id]++
+
+	// Stored messages are shared between subscriptions, so the delivery is made on a copy
+	delivery := proto.Clone(msg).(*pb.Message)
+	delivery.DeliveryAttempt = sub.attempts[id]
+	delivery.AckId = ackID(sub.key.cursorID, id)
+
+	inflight := &inflightMessage{msg: msg}
+	inflight.timer = time.AfterFunc(sub.ackDeadline, func() {
+		sub.expire(id, inflight)
+	})
+	sub.inflight[id] = inflight
+
+	return delivery
+}
+
+// expire redelivers a message that was not acknowledged before the deadline
+func (sub *subscription) expire(id string, inflight *inflightMessage) {
+	sub.mu.Lock()
+
+	// The message was acknowledged, or handed back, in the meantime
+	if sub.inflight[id] != inflight {
+		sub.mu.Unlock()
+		return
+	}
+
+	delete(sub.inflight, id)
+	attempts := sub.attempts[id]
+	sub.mu.Unlock()
+
+	slog.Warn(
+		"ack deadline exceeded",
+		slog.String("cursor", sub.key.cursorID),
+		slog.String("channel", sub.key.channel),
+		slog.String("message", id),
+		slog.Uint64("attempts", uint64(attempts)),
+	)
+	sub.retry(inflight.msg, attempts, reasonAckDeadlineExceeded)
+}
+
+// ack stops tracking an acknowledged message
+func (sub *subscription) ack(id string) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	inflight, exists := sub.inflight[id]
+	if !exists {
+		return
+	}
+
+	inflight.timer.Stop()
+	delete(sub.inflight, id)
+	delete(sub.attempts, id)
+	sub.consumedLocked(id)
+}
+
+// fail redelivers a message that a member failed to process, or dead-letters it
+func (sub *subscription) fail(id string, reason string) {
+	sub.mu.Lock()
+
+	inflight, exists := sub.inflight[id]
+	if !exists {
+		sub.mu.Unlock()
+		return
+	}
+
+	inflight.timer.Stop()
+	delete(sub.inflight, id)
+	attempts := sub.attempts[id]
+	sub.mu.Unlock()
+
+	sub.retry(inflight.msg, attempts, reason)
+}
+
+// retry requeues a message that failed to be processed, unless it has been moved to the dead-letter channel
+func (sub *subscription) retry(msg *pb.Message, attempts uint32, reason string) {
+	if sub.deadLetter != nil && sub.deadLetter(msg, attempts, reason) {
+		sub.mu.Lock()
+		delete(sub.attempts, msg.GetId())
+		sub.consumedLocked(msg.GetId())
+		sub.mu.Unlock()
+		return
+	}
+
+	sub.requeue(msg)
+}
+
+// handBack requeues a message that could not be sent to a member, the delivery is not counted as an attempt
+func (sub *subscription) handBack(msg *pb.Message) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	id := msg.GetId()
+	if inflight, exists := sub.inflight[id]; exists {
+		inflight.timer.Stop()
+		delete(sub.inflight, id)
+		if sub.attempts[id]--; sub.attempts[id] == 0 {
+			delete(sub.attempts, id)
+		}
+	}
+
+	sub.requeueLocked(msg)
+}
+
+// stop stops tracking the messages in flight once the subscription is removed, the offset consumed up to is committed
+// right away instead of once the commit is due
+func (sub *subscription) stop() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	for id, inflight := range sub.inflight {
+		inflight.timer.Stop()
+		delete(sub.inflight, id)
+	}
+	sub.flushLocked()
+}
+
+// flush commits the offset the subscription has consumed up to right away, instead of once the commit is due
+func (sub *subscription) flush() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	sub.flushLocked()
+}
+
+// flushLocked commits the offset consumed up to right away, unless the subscription has failed (when its channel was deleted,
+// or it fell behind the retention of its channel). The caller must hold the lock of the subscription.
+func (sub *subscription) flushLocked() {
+	if sub.commitTimer == nil {
+		return
+	}
+	sub.commitTimer.Stop()
+	sub.commitTimer = nil
+
+	if !sub.hasFailed() {
+		sub.commitLocked()
+	}
+}
+
+// purge drops the messages handed back to the subscription and the messages in flight, once its channel is purged.
+// The messages read so far are taken as consumed, without committing an offset before the ones purged.
+func (sub *subscription) purge() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	for id, inflight := range sub.inflight {
+		inflight.timer.Stop()
+		delete(sub.inflight, id)
+	}
+	sub.pending = make([]*pb.Message, 0)
+	clear(sub.attempts)
+	clear(sub.outstanding)
+	sub.readOrder = make([]string, 0)
+	sub.committed = max(sub.committed, sub.readOffset)
+	sub.consumed = sub.committed
+}
+
+// failWith ends the subscription with an error, the error is reported to its members
+func (sub *subscription) failWith(err error) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.err != nil {
+		return
+	}
+
+	sub.err = err
+	close(sub.failed)
+}
+
+// hasFailed reports whether the subscription has ended with an error
+func (sub *subscription) hasFailed() bool {
+	select {
+	case <-sub.failed:
+		return true
+	default:
+		return false
+	}
+}
+
+// track records the messages read from the channel, followed by the specified offset, until they are consumed
+func (sub *subscription) track(msgs []*pb.Message, nextOffset uint64) {
+	if sub.commit == nil || len(msgs) == 0 {
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	// The subscription starts from the offset of the first message it reads
+	if sub.readOffset == 0 {
+		sub.committed = msgs[0].GetOffset()
+		sub.consumed = sub.committed
+	}
+
+	// The offsets of the messages are not contiguous when messages expired in between
+	for _, msg := range msgs {
+		sub.outstanding[msg.GetId()] = msg.GetOffset()
+		sub.readOrder = append(sub.readOrder, msg.GetId())
+	}
+	sub.readOffset = nextOffset
+}
+
+// moveTo records the offset the subscription reads its channel from next
+func (sub *subscription) moveTo(offset uint64) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	sub.position = offset
+}
+
+// nextOffset returns the offset the subscription reads its channel from next
+func (sub *subscription) nextOffset() uint64 {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	return sub.position
+}
+
+// filterMessages returns the messages matched by the filter of the subscription,
+// the other messages are marked as consumed
+func (sub *subscription) filterMessages(msgs []*pb.Message) []*pb.Message {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.filter == nil {
+		return msgs
+	}
+
+	matched := make([]*pb.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if sub.filter.match(msg.GetHeaders()) {
+			matched = append(matched, msg)
+			continue
+		}
+		sub.consumedLocked(msg.GetId())
+	}
+
+	return matched
+}
+
+// isInflight reports whether the message is in flight, waiting to be acknowledged
+func (sub *subscription) isInflight(id string) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	_, exists := sub.inflight[id]
+	return exists
+}
+
+// sent marks a message as consumed once it is sent to a member, if the subscription does not wait for acks
+func (sub *subscription) sent(msg *pb.Message) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.ackDeadline != 0 || sub.commit == nil {
+		return
+	}
+
+	sub.consumedLocked(msg.GetId())
+}
+
+// consumedLocked marks a message as consumed and schedules the commit of the offset of the subscription,
+// once every message before it has been consumed. The caller must hold the lock of the subscription.
+func (sub *subscription) consumedLocked(id string) {
+	if sub.commit == nil {
+		return
+	}
+
+	delete(sub.outstanding, id)
+
+	// The subscription resumes from the first message not consumed yet, the messages are read in the order of their offsets
+	for len(sub.readOrder) > 0 {
+		if _, exists := sub.outstanding[sub.readOrder[0]]; exists {
+			break
+		}
+		sub.readOrder = sub.readOrder[1:]
+	}
+	consumed := sub.readOffset
+	if len(sub.readOrder) > 0 {
+		consumed = sub.outstanding[sub.readOrder[0]]
+	}
+	if consumed <= sub.consumed {
+		return
+	}
+	sub.consumed = consumed
+
+	// The messages consumed until the commit is due are committed along with this one
+	if sub.commitTimer == nil {
+		sub.commitTimer = time.AfterFunc(commitInterval, sub.flushCommit)
+	}
+}
+
+// flushCommit commits the offset the subscription has consumed up to, once the commit is due
+func (sub *subscription) flushCommit() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	// The offset was committed in the meantime
+	if sub.commitTimer == nil {
+		return
+	}
+	sub.commitTimer = nil
+	sub.commitLocked()
+}
+
+// commitLocked commits the offset the subscription has consumed up to, if it is past the offset committed.
+// The caller must hold the lock of the subscription.
+func (sub *subscription) commitLocked() {
+	if sub.consumed <= sub.committed {
+		return
+	}
+
+	if err := sub.commit(sub.consumed); err != nil {
+		slog.Error(
+			"failed to commit offset",
+			slog.String("cursor", sub.key.cursorID),
+			slog.String("channel", sub.key.channel),
+			slog.Uint64("offset", sub.consumed),
+			slog.Any("error", err),
+		)
+		return
+	}
+	sub.committed = sub.consumed
+}
+
+// takePending returns the messages handed back to the subscription
+func (sub *subscription) takePending() []*pb.Message {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	pending := sub.pending
+	sub.pending = make([]*pb.Message, 0)
+	return pending
+}
+
+// dispatch hands the messages out to the members, the messages left when the subscription stops are requeued
+func (sub *subscription) dispatch(ctx context.Context, msgs []*pb.Message) bool {
+	for i, msg := range msgs {
+		select {
+		case <-ctx.Done():
+			sub.requeue(msgs[i:]...)
+			return false
+		case sub.messages <- msg:
+		}
+	}
+
+	return true
+}
+
+// readChannel reads the channel of the subscription and dispatches its messages until the context is done
+func (s *Service) readChannel(
+	ctx context.Context,
+	sub *subscription,
+	offset uint64,
+	pullInterval uint64,
+) {
+	channel := sub.key.channel
+	for {
+		// Deliver the messages handed back by members that left first
+		if !sub.dispatch(ctx, sub.takePending()) {
+			return
+		}
+
+		// Start watching the channel before reading from it, so that a message
+		// saved in between the read and the wait is not missed
+		notify, err := s.storage.WatchChannel(channel)
+		if err != nil {
+			slog.Error(
+				"failed to watch channel",
+				slog.String("cursor", sub.key.cursorID),
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+			return
+		}
+
+		messages, nextOffset, err := s.storage.GetMessages(
+			channel,
+			sub.key.cursorID,
+			offset,
+		)
+		if errors.Is(err, storage.ErrOffsetOutOfRange) {
+			slog.Error(
+				"subscription has fallen behind the retention of the channel",
+				slog.String("cursor", sub.key.cursorID),
+				slog.String("channel", channel),
+			)
+			sub.failWith(status.Error(codes.OutOfRange, ErrOffsetOutOfRange.Error()))
+			return
+		}
+		if err == nil {
+			offset = nextOffset + 1
+			sub.track(messages, offset)
+			sub.moveTo(offset)
+
+			// The messages filtered out are skipped, the cursor has moved past them all the same
+			if !sub.dispatch(ctx, sub.filterMessages(messages)) {
+				return
+			}
+
+			// Keep reading until the subscription has caught up with the channel
+			if len(messages) > 0 {
+				continue
+			}
+		}
+
+		// Wait until a new message is saved to the channel, or a message is handed back
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.wake:
+			continue
+		case <-notify:
+		}
+
+		// Give the channel some time to accumulate a batch, if the subscriber asked for it
+		if pullInterval > 0 {
+			timer := time.NewTimer(time.Duration(pullInterval) * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// forwardMessages forwards the messages of the subscription to a member until the context is done or the
+// subscription fails, the messages are tagged with the channel of the subscription if the member asked for it.
+// The failure of the subscription is handed to failed.
+func forwardMessages(
+	ctx context.Context,
+	sub *subscription,
+	msgChan chan<- *pb.Message,
+	failed failFunc,
+	tagChannel bool,
+) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.failed:
+			failed(sub)
+			return
+		case msg := <-sub.messages:
+			delivery := sub.deliver(msg)
+			if tagChannel {
+				// Stored messages are shared between subscriptions, so the tag is set on a copy
+				if delivery == msg {
+					delivery = proto.Clone(msg).(*pb.Message)
+				}
+				delivery.Channel = channelOf(sub.key.channel)
+			}
+			select {
+			case <-ctx.Done():
+				// Hand the message back, so that it is delivered to another member
+				sub.handBack(msg)
+				return
+			case msgChan <- delivery:
+				sub.sent(msg)
+			}
+		}
+	}
+}
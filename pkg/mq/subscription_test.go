@@ -0,0 +1,471 @@
+// pkg/mq/subscription_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rosedblabs/wal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestParseAckID(t *testing.T) {
+	tests := []struct {
+		name      string
+		ackID     string
+		cursorID  string
+		messageID string
+		err       error
+	}{
+		{
+			name:      "subscriber cursor",
+			ackID:     ackID("unique-subscriber-id", "unique-message-id"),
+			cursorID:  "unique-subscriber-id",
+			messageID: "unique-message-id",
+			err:       nil,
+		},
+		{
+			name:      "group cursor containing the separator",
+			ackID:     ackID(groupCursorPrefix+"team:a", "unique-message-id"),
+			cursorID:  groupCursorPrefix + "team:a",
+			messageID: "unique-message-id",
+			err:       nil,
+		},
+		{
+			name:  "error: missing separator",
+			ackID: "unique-message-id",
+			err:   ErrInvalidAckID,
+		},
+		{
+			name:  "error: missing cursor",
+			ackID: ackIDSeparator + "unique-message-id",
+			err:   ErrInvalidAckID,
+		},
+		{
+			name:  "error: missing message id",
+			ackID: "unique-subscriber-id" + ackIDSeparator,
+			err:   ErrInvalidAckID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursorID, messageID, err := parseAckID(tt.ackID)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.cursorID, cursorID)
+			assert.Equal(t, tt.messageID, messageID)
+		})
+	}
+}
+
+func TestSubscriptionDeliver(t *testing.T) {
+	key := subscriptionKey{
+		channel:  "test-channel",
+		cursorID: "unique-subscriber-id",
+	}
+	msg := &pb.Message{
+		Id:        "unique-message-id",
+		Content:   []byte("test-content"),
+		CreatedAt: int64(1234567890),
+	}
+
+	t.Run("messages are not tracked without an ack deadline", func(t *testing.T) {
+		sub := newSubscription(key, 0, nil, nil, nil)
+		assert.Same(t, msg, sub.deliver(msg))
+		assert.Empty(t, sub.inflight)
+	})
+
+	t.Run("message is redelivered once the ack deadline passes", func(t *testing.T) {
+		sub := newSubscription(key, 10*time.Millisecond, nil, nil, nil)
+
+		delivery := sub.deliver(msg)
+		assert.Equal(t, uint32(1), delivery.GetDeliveryAttempt())
+		assert.Equal(t, ackID(key.cursorID, msg.GetId()), delivery.GetAckId())
+
+		// The stored message is left untouched
+		assert.Empty(t, msg.GetAckId())
+
+		select {
+		case <-sub.wake:
+		case <-time.After(time.Second):
+			t.Fatal("message was not redelivered after the ack deadline")
+		}
+		assert.Equal(t, []*pb.Message{msg}, sub.takePending())
+		assert.Equal(t, uint32(2), sub.deliver(msg).GetDeliveryAttempt())
+		sub.stop()
+	})
+
+	t.Run("message handed back is not counted as an attempt", func(t *testing.T) {
+		sub := newSubscription(key, time.Minute, nil, nil, nil)
+
+		sub.deliver(msg)
+		sub.handBack(msg)
+		assert.Empty(t, sub.inflight)
+		assert.Empty(t, sub.attempts)
+		assert.Equal(t, []*pb.Message{msg}, sub.takePending())
+		assert.Equal(t, uint32(1), sub.deliver(msg).GetDeliveryAttempt())
+		sub.stop()
+	})
+}
+
+func TestSubscriptionCommit(t *testing.T) {
+	key := subscriptionKey{
+		channel:  "test-channel",
+		cursorID: durableCursorPrefix + "test-durable-name",
+	}
+	msgs := []*pb.Message{
+		{Id: "unique-message-id-1", Offset: 0},
+		{Id: "unique-message-id-2", Offset: 1},
+		{Id: "unique-message-id-3", Offset: 2},
+	}
+
+	t.Run("offset is committed once the messages before it are acknowledged", func(t *testing.T) {
+		var commits []uint64
+		sub := newSubscription(
+			key,
+			time.Minute,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		later := []*pb.Message{
+			{Id: "unique-message-id-1", Offset: 5},
+			{Id: "unique-message-id-2", Offset: 6},
+			{Id: "unique-message-id-3", Offset: 7},
+		}
+		sub.track(later, 8)
+		for _, msg := range later {
+			sub.deliver(msg)
+		}
+
+		// The first message is still outstanding, nothing can be committed
+		sub.ack(later[1].GetId())
+		assert.Nil(t, sub.commitTimer)
+		assert.Empty(t, commits)
+
+		sub.ack(later[0].GetId())
+		sub.flushCommit()
+		sub.ack(later[2].GetId())
+		sub.flushCommit()
+		assert.Equal(t, []uint64{7, 8}, commits)
+	})
+
+	t.Run("offsets consumed before the commit is due are committed at once", func(t *testing.T) {
+		var commits []uint64
+		sub := newSubscription(
+			key,
+			time.Minute,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		sub.track(msgs, 3)
+		for _, msg := range msgs {
+			sub.deliver(msg)
+		}
+		for _, msg := range msgs {
+			sub.ack(msg.GetId())
+		}
+		assert.Empty(t, commits)
+
+		sub.flushCommit()
+		assert.Equal(t, []uint64{3}, commits)
+		assert.Empty(t, sub.readOrder)
+	})
+
+	t.Run("offset is committed once the commit interval has passed", func(t *testing.T) {
+		commits := make(chan uint64, 1)
+		sub := newSubscription(
+			key,
+			0,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits <- offset
+				return nil
+			},
+		)
+
+		sub.track(msgs, 3)
+		for _, msg := range msgs {
+			sub.sent(sub.deliver(msg))
+		}
+
+		select {
+		case offset := <-commits:
+			assert.Equal(t, uint64(3), offset)
+		case <-time.After(time.Second):
+			t.Fatal("offset was not committed")
+		}
+	})
+
+	t.Run("offset is committed right away once the subscription is stopped", func(t *testing.T) {
+		var commits []uint64
+		sub := newSubscription(
+			key,
+			0,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		sub.track(msgs, 3)
+		sub.sent(sub.deliver(msgs[0]))
+		sub.stop()
+		assert.Equal(t, []uint64{1}, commits)
+		assert.Nil(t, sub.commitTimer)
+
+		// The commit is not made twice
+		sub.flushCommit()
+		sub.stop()
+		assert.Equal(t, []uint64{1}, commits)
+	})
+
+	t.Run("nothing is committed once the subscription has failed", func(t *testing.T) {
+		var commits []uint64
+		sub := newSubscription(
+			key,
+			0,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		sub.track(msgs, 3)
+		sub.sent(sub.deliver(msgs[0]))
+		sub.failWith(status.Error(codes.NotFound, ErrChannelDeleted.Error()))
+		sub.stop()
+		sub.flushCommit()
+		assert.Empty(t, commits)
+	})
+
+	t.Run("offset is committed once the messages are sent without acks", func(t *testing.T) {
+		var commits []uint64
+		sub := newSubscription(
+			key,
+			0,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		sub.track(msgs, 3)
+		for _, msg := range msgs {
+			sub.sent(sub.deliver(msg))
+			sub.flushCommit()
+		}
+		assert.Equal(t, []uint64{1, 2, 3}, commits)
+	})
+
+	t.Run("offset is committed past the messages that expired", func(t *testing.T) {
+		var commits []uint64
+		sub := newSubscription(
+			key,
+			0,
+			nil,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		// The messages at offsets 1 to 3 expired before they were read
+		gapped := []*pb.Message{
+			{Id: "unique-message-id-1", Offset: 0},
+			{Id: "unique-message-id-2", Offset: 4},
+		}
+		sub.track(gapped, 5)
+		for _, msg := range gapped {
+			sub.sent(sub.deliver(msg))
+			sub.flushCommit()
+		}
+		assert.Equal(t, []uint64{4, 5}, commits)
+	})
+
+	t.Run("offset is committed past the messages filtered out", func(t *testing.T) {
+		var commits []uint64
+		f, err := parseFilter("type = order.created")
+		assert.NoError(t, err)
+		sub := newSubscription(
+			key,
+			time.Minute,
+			f,
+			nil,
+			func(offset uint64) error {
+				commits = append(commits, offset)
+				return nil
+			},
+		)
+
+		filtered := []*pb.Message{
+			{Id: "unique-message-id-1", Offset: 0, Headers: map[string]string{"type": "order.deleted"}},
+			{Id: "unique-message-id-2", Offset: 1, Headers: map[string]string{"type": "order.created"}},
+			{Id: "unique-message-id-3", Offset: 2},
+		}
+		sub.track(filtered, 3)
+		matched := sub.filterMessages(filtered)
+		assert.Equal(t, filtered[1:2], matched)
+		sub.flushCommit()
+		assert.Equal(t, []uint64{1}, commits)
+
+		sub.deliver(matched[0])
+		sub.ack(matched[0].GetId())
+		sub.flushCommit()
+		assert.Equal(t, []uint64{1, 3}, commits)
+	})
+
+	t.Run("nothing is committed for a subscription that is not durable", func(t *testing.T) {
+		sub := newSubscription(key, 0, nil, nil, nil)
+
+		sub.track(msgs, 3)
+		sub.sent(msgs[0])
+		assert.Empty(t, sub.outstanding)
+	})
+}
+
+func TestDurableSubscriptionRestart(t *testing.T) {
+	dir := t.TempDir()
+	open := func() (*Service, func()) {
+		log, err := wal.Open(wal.Options{
+			DirPath:        dir,
+			SegmentSize:    wal.DefaultOptions.SegmentSize,
+			SegmentFileExt: ".wal",
+			Sync:           false,
+			BytesPerSync:   0,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		memoryStorage, err := storage.NewMemoryStorage(&storage.MemoryStorageOptions{
+			Wal:               log,
+			WalDirPath:        dir,
+			WalSegmentFileExt: ".wal",
+			WalSync:           false,
+			WalRecoveryMode:   storage.WalRecoveryModeSkip,
+			BatchSize:         100,
+			SyncOnStartup:     true,
+			DedupWindow:       0,
+			SweepInterval:     0,
+			SnapshotDirPath:   "",
+			SnapshotInterval:  0,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		service := NewService(
+			&ServiceOptions{
+				Storage: memoryStorage,
+			},
+		)
+		return service, func() {
+			service.Close()
+			_ = memoryStorage.Close()
+			_ = log.Close()
+		}
+	}
+
+	sub := &pb.Subscriber{
+		Id:          "unique-subscriber-id",
+		Ip:          "ip-address",
+		DurableName: "test-durable-name",
+	}
+	channel := "test-channel"
+
+	service, closeService := open()
+	assert.NoError(t, service.storage.CreateChannel(channel))
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := service.storage.SaveMessage(channel, &pb.Message{Id: id})
+		assert.NoError(t, err)
+	}
+
+	// The subscriber consumes two messages, and the broker is restarted right after
+	ctx, cancel := context.WithCancel(context.Background())
+	msgChan := make(chan *pb.Message)
+	err := service.Subscribe(
+		ctx,
+		sub,
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		make(chan error, 1),
+	)
+	assert.NoError(t, err)
+	for _, id := range []string{"a", "b"} {
+		assert.Equal(t, id, (<-msgChan).GetId())
+	}
+
+	subscription := service.subscriptions[subscriptionKey{channel: channel, cursorID: cursorID(sub)}]
+	assert.Eventually(t, func() bool {
+		subscription.mu.Lock()
+		defer subscription.mu.Unlock()
+		return subscription.consumed == 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	assert.NoError(t, service.UnSubscribe(ctx, sub, channel))
+	closeService()
+
+	// The subscription resumes exactly where it left off
+	service, closeService = open()
+	defer closeService()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	err = service.Subscribe(
+		ctx,
+		sub,
+		channel,
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		make(chan error, 1),
+	)
+	assert.NoError(t, err)
+	select {
+	case msg := <-msgChan:
+		assert.Equal(t, "c", msg.GetId())
+	case <-time.After(time.Second):
+		t.Fatal("the subscription did not resume")
+	}
+}
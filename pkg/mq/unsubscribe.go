@@ -9,12 +9,17 @@ import (
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
 )
 
-// UnSubscribe removes subscriber from the specified channel
+// UnSubscribe removes subscriber from the specified channel, or from every channel matching the specified pattern
 func (s *Service) UnSubscribe(
 	ctx context.Context,
 	sub *pb.Subscriber,
 	channel string,
 ) error {
+	// Remove the subscriber from every channel it was subscribed to through the pattern
+	if isWildcard(channel) {
+		return s.unsubscribeWildcard(sub, channel)
+	}
+
 	// Check if the channel exists
 	if !s.storage.ChannelExists(channel) {
 		slog.Error(
@@ -26,14 +31,11 @@ func (s *Service) UnSubscribe(
 		return ErrChannelDoesNotExist
 	}
 
-	// Remove the channel from the subscriber's map
-	s.storage.RemoveChannelFromSubscriberMap(channel, sub.GetId())
-
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Remove the subscriber from the channel
-	delete(s.channelToSubscribers[channel], sub)
+	s.unsubscribePartitions(sub, channel)
+
 	slog.Info(
 		"subscriber removed",
 		slog.String("id", sub.GetId()),
@@ -43,3 +45,40 @@ func (s *Service) UnSubscribe(
 
 	return nil
 }
+
+// unsubscribeChannel removes the subscriber from the channel (or from a partition of a channel),
+// the caller must hold the lock of the service
+func (s *Service) unsubscribeChannel(
+	sub *pb.Subscriber,
+	channel string,
+) {
+	// Remove the subscriber from the channel
+	_, subscribed := s.channelToSubscribers[channel][sub]
+	delete(s.channelToSubscribers[channel], sub)
+
+	// Stop reading the channel once the last member of the subscription has left, a subscriber
+	// that never made it to the channel is not a member of the subscription
+	key := subscriptionKey{
+		channel:  channel,
+		cursorID: cursorID(sub),
+	}
+	if subscription, exists := s.subscriptions[key]; exists && subscribed {
+		subscription.members--
+		if subscription.members == 0 {
+			subscription.cancel()
+		}
+
+		// The subscription of a consumer group (or a durable subscription) is kept, so that it resumes
+		// where it left off (including the messages handed back) when its subscribers reconnect,
+		// unless it has fallen behind the retention of the channel
+		if subscription.members == 0 && (!keepsCursor(sub) || subscription.hasFailed()) {
+			subscription.stop()
+			delete(s.subscriptions, key)
+		}
+	}
+
+	// Remove the channel from the subscriber's map, the cursor of a consumer group (or a durable subscription) is kept as well
+	if !keepsCursor(sub) {
+		s.storage.RemoveChannelFromSubscriberMap(channel, sub.GetId())
+	}
+}
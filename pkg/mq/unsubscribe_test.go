@@ -18,6 +18,7 @@ func TestUnsubscribe(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
 
 	service := NewService(
 		&ServiceOptions{
@@ -29,10 +30,21 @@ func TestUnsubscribe(t *testing.T) {
 		Id: "unique-subscriber-id",
 		Ip: "ip-address",
 	}
+	groupSub := &pb.Subscriber{
+		Id:    "unique-subscriber-id",
+		Ip:    "ip-address",
+		Group: "test-group",
+	}
+	durableSub := &pb.Subscriber{
+		Id:          "unique-subscriber-id",
+		Ip:          "ip-address",
+		DurableName: "test-durable-name",
+	}
 
 	tests := []struct {
 		name   string
 		inputs struct {
+			sub     *pb.Subscriber
 			channel string
 		}
 		setup func()
@@ -41,8 +53,10 @@ func TestUnsubscribe(t *testing.T) {
 		{
 			name: "error: channel does not exist",
 			inputs: struct {
+				sub     *pb.Subscriber
 				channel string
 			}{
+				sub:     sub,
 				channel: "non-existent-channel",
 			},
 			setup: func() {
@@ -55,8 +69,10 @@ func TestUnsubscribe(t *testing.T) {
 		{
 			name: "success: subscriber removed",
 			inputs: struct {
+				sub     *pb.Subscriber
 				channel string
 			}{
+				sub:     sub,
 				channel: "test-channel",
 			},
 			setup: func() {
@@ -69,12 +85,44 @@ func TestUnsubscribe(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "success: group member removed, group cursor kept",
+			inputs: struct {
+				sub     *pb.Subscriber
+				channel string
+			}{
+				sub:     groupSub,
+				channel: "test-channel",
+			},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(true)
+			},
+			err: nil,
+		},
+		{
+			name: "success: durable subscriber removed, durable cursor kept",
+			inputs: struct {
+				sub     *pb.Subscriber
+				channel string
+			}{
+				sub:     durableSub,
+				channel: "test-channel",
+			},
+			setup: func() {
+				mockStorage.EXPECT().
+					ChannelExists("test-channel").
+					Return(true)
+			},
+			err: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setup()
-			err := service.UnSubscribe(ctx, sub, tt.inputs.channel)
+			err := service.UnSubscribe(ctx, tt.inputs.sub, tt.inputs.channel)
 			assert.Equal(t, tt.err, err)
 		})
 	}
@@ -0,0 +1,106 @@
+// pkg/mq/update_channel_config.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// UpdateChannelConfig replaces the configuration of an existing channel, except for its number of partitions
+// and whether it is compacted, the subscribers already connected are kept
+func (s *Service) UpdateChannelConfig(
+	ctx context.Context,
+	channel string,
+	config *pb.ChannelConfig,
+) error {
+	if !validChannelName(channel) || !validChannelName(config.GetDeadLetterChannel()) {
+		slog.Error(
+			"cannot update channel config with invalid name",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.storage.GetChannelConfig(channel)
+	if err != nil {
+		slog.Error(
+			"cannot update config of non-existent channel",
+			slog.String("channel", channel),
+		)
+		return status.Error(codes.NotFound, ErrChannelDoesNotExist.Error())
+	}
+
+	// A channel without partitions has a single one
+	if max(config.GetPartitions(), 1) != max(current.GetPartitions(), 1) {
+		slog.Error(
+			"cannot change the number of partitions of a channel",
+			slog.String("channel", channel),
+			slog.Uint64("partitions", uint64(current.GetPartitions())),
+		)
+		return status.Error(codes.FailedPrecondition, ErrPartitionsChanged.Error())
+	}
+
+	// Compacting a channel that was not would drop the messages published to it without a key
+	if config.GetCompacted() != current.GetCompacted() {
+		slog.Error(
+			"cannot change the compaction of a channel",
+			slog.String("channel", channel),
+			slog.Bool("compacted", current.GetCompacted()),
+		)
+		return status.Error(codes.FailedPrecondition, ErrCompactionChanged.Error())
+	}
+
+	// Persist the new configuration of the channel
+	if err := s.storage.SetChannelConfig(channel, config); err != nil {
+		slog.Error(
+			"failed to set channel config",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return status.Error(codes.Unavailable, ErrUnableToUpdateChannelConfig.Error())
+	}
+
+	slog.Info(
+		"channel config updated",
+		slog.String("channel", channel),
+	)
+
+	return nil
+}
+
+// gRPC implementation of the UpdateChannelConfig method
+func (s *Server) UpdateChannelConfig(
+	ctx context.Context,
+	req *pb.UpdateChannelConfigRequest,
+) (*pb.UpdateChannelConfigResponse, error) {
+	input := newChannelConfigInput(req.GetChannel(), req.GetConfig())
+
+	// Validate the input request
+	if err := s.validator.ValidateStruct(input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid input")
+	}
+
+	// The configuration of a channel is never nil
+	config := req.GetConfig()
+	if config == nil {
+		config = &pb.ChannelConfig{}
+	}
+
+	// Update the configuration of the channel
+	if err := s.srv.UpdateChannelConfig(ctx, input.Channel, config); err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateChannelConfigResponse{
+		Config: config,
+	}, nil
+}
@@ -0,0 +1,271 @@
+// pkg/mq/update_channel_config_test.go
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestUpdateChannelConfigService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx := context.Background()
+	config := &pb.ChannelConfig{
+		Partitions:      4,
+		RetentionMs:     60000,
+		MaxMessageBytes: 1024,
+		MaxSubscribers:  8,
+		BatchSize:       100,
+		Durability:      pb.Durability_DURABILITY_SYNC,
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		config  *pb.ChannelConfig
+		setup   func()
+		err     error
+	}{
+		{
+			name:    "error: channel name with wildcards",
+			channel: "test-channel.*",
+			config:  config,
+			setup:   func() {},
+			err:     status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
+		{
+			name:    "error: dead-letter channel name with wildcards",
+			channel: "test-channel",
+			config: &pb.ChannelConfig{
+				MaxDeliveryAttempts: 3,
+				DeadLetterChannel:   "test-dead-letter-channel.>",
+			},
+			setup: func() {},
+			err:   status.Error(codes.InvalidArgument, ErrInvalidChannelName.Error()),
+		},
+		{
+			name:    "error: channel does not exist",
+			channel: "test-channel",
+			config:  config,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(nil, errors.New("channel does not exist"))
+			},
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name:    "error: number of partitions changed",
+			channel: "test-channel",
+			config:  config,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{Partitions: 2}, nil)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrPartitionsChanged.Error()),
+		},
+		{
+			name:    "error: compaction turned on",
+			channel: "test-channel",
+			config:  &pb.ChannelConfig{Partitions: 4, Compacted: true},
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{Partitions: 4}, nil)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrCompactionChanged.Error()),
+		},
+		{
+			name:    "error: compaction turned off",
+			channel: "test-channel",
+			config:  config,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{Partitions: 4, Compacted: true}, nil)
+			},
+			err: status.Error(codes.FailedPrecondition, ErrCompactionChanged.Error()),
+		},
+		{
+			name:    "error: set channel config storage error",
+			channel: "test-channel",
+			config:  config,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{Partitions: 4}, nil)
+				mockStorage.EXPECT().
+					SetChannelConfig("test-channel", config).
+					Return(storage.ErrInternal)
+			},
+			err: status.Error(codes.Unavailable, ErrUnableToUpdateChannelConfig.Error()),
+		},
+		{
+			name:    "success: channel config updated",
+			channel: "test-channel",
+			config:  config,
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{Partitions: 4}, nil)
+				mockStorage.EXPECT().
+					SetChannelConfig("test-channel", config).
+					Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name:    "success: single partition left unset",
+			channel: "test-channel",
+			config:  &pb.ChannelConfig{Partitions: 1},
+			setup: func() {
+				mockStorage.EXPECT().
+					GetChannelConfig("test-channel").
+					Return(&pb.ChannelConfig{}, nil)
+				mockStorage.EXPECT().
+					SetChannelConfig("test-channel", &pb.ChannelConfig{Partitions: 1}).
+					Return(nil)
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			err := service.UpdateChannelConfig(ctx, tt.channel, tt.config)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestUpdateChannelConfigServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockValidator := mocks.NewMockValidator(ctrl)
+	mockGenerator := mocks.NewMockGenerator(ctrl)
+	mockService := mocks.NewMockMQ(ctrl)
+
+	server := NewServer(
+		&ServerOptions{
+			Validator: mockValidator,
+			Generator: mockGenerator,
+			Service:   mockService,
+		},
+	)
+
+	ctx := context.Background()
+	channel := "test-channel"
+	config := &pb.ChannelConfig{
+		MaxSubscribers: 8,
+	}
+
+	tests := []struct {
+		name  string
+		req   *pb.UpdateChannelConfigRequest
+		setup func()
+		res   *pb.UpdateChannelConfigResponse
+		err   error
+	}{
+		{
+			name: "error: invalid input",
+			req: &pb.UpdateChannelConfigRequest{
+				Channel: "",
+				Config:  config,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(status.Error(codes.InvalidArgument, "invalid input"))
+			},
+			res: nil,
+			err: status.Error(codes.InvalidArgument, "invalid input"),
+		},
+		{
+			name: "error: channel does not exist",
+			req: &pb.UpdateChannelConfigRequest{
+				Channel: channel,
+				Config:  config,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					UpdateChannelConfig(ctx, channel, config).
+					Return(status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()))
+			},
+			res: nil,
+			err: status.Error(codes.NotFound, ErrChannelDoesNotExist.Error()),
+		},
+		{
+			name: "success: channel config updated",
+			req: &pb.UpdateChannelConfigRequest{
+				Channel: channel,
+				Config:  config,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					UpdateChannelConfig(ctx, channel, config).
+					Return(nil)
+			},
+			res: &pb.UpdateChannelConfigResponse{
+				Config: config,
+			},
+			err: nil,
+		},
+		{
+			name: "success: channel config reset",
+			req: &pb.UpdateChannelConfigRequest{
+				Channel: channel,
+				Config:  nil,
+			},
+			setup: func() {
+				mockValidator.EXPECT().
+					ValidateStruct(gomock.Any()).
+					Return(nil)
+				mockService.EXPECT().
+					UpdateChannelConfig(ctx, channel, &pb.ChannelConfig{}).
+					Return(nil)
+			},
+			res: &pb.UpdateChannelConfigResponse{
+				Config: &pb.ChannelConfig{},
+			},
+			err: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			res, err := server.UpdateChannelConfig(ctx, tt.req)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// pkg/mq/wildcard.go
+
+package mq
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+const (
+	// channelTokenSeparator separates the tokens of a hierarchical channel name, such as orders.eu.created
+	channelTokenSeparator = "."
+
+	// wildcardToken matches exactly one token of a channel name
+	wildcardToken = "*"
+
+	// fullWildcardToken matches one or more trailing tokens of a channel name, it must be the last token of a pattern
+	fullWildcardToken = ">"
+)
+
+// isWildcard reports whether the channel is a pattern, that is whether any of its tokens is a wildcard
+func isWildcard(channel string) bool {
+	for _, token := range strings.Split(channel, channelTokenSeparator) {
+		if token == wildcardToken || token == fullWildcardToken {
+			return true
+		}
+	}
+	return false
+}
+
+// validPattern reports whether the pattern has no empty token, and no token after the full wildcard
+func validPattern(pattern string) bool {
+	tokens := strings.Split(pattern, channelTokenSeparator)
+	for i, token := range tokens {
+		if token == "" || (token == fullWildcardToken && i != len(tokens)-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchChannel reports whether the channel matches the pattern
+func matchChannel(pattern string, channel string) bool {
+	patternTokens := strings.Split(pattern, channelTokenSeparator)
+	channelTokens := strings.Split(channel, channelTokenSeparator)
+	for i, token := range patternTokens {
+		if token == fullWildcardToken {
+			return len(channelTokens) > i
+		}
+		if i >= len(channelTokens) {
+			return false
+		}
+		if token != wildcardToken && token != channelTokens[i] {
+			return false
+		}
+	}
+	return len(channelTokens) == len(patternTokens)
+}
+
+// wildcardSubscription is the subscription of a subscriber to every channel matching a pattern,
+// the subscriber has a subscription to each of the channels
+type wildcardSubscription struct {
+	pattern  string
+	opts     subscribeOptions
+	channels map[string]struct{}
+	cancel   context.CancelFunc
+}
+
+// subscribeWildcard subscribes the subscriber to every channel matching the pattern, the channels created
+// later on are subscribed to from the beginning. The caller must hold the lock of the service.
+func (s *Service) subscribeWildcard(
+	ctx context.Context,
+	sub *pb.Subscriber,
+	pattern string,
+	opts subscribeOptions,
+	msgChan chan<- *pb.Message,
+) error {
+	if !validPattern(pattern) {
+		slog.Error(
+			"invalid channel pattern",
+			slog.String("pattern", pattern),
+		)
+		return status.Error(codes.InvalidArgument, ErrInvalidChannelPattern.Error())
+	}
+
+	// The messages are tagged with the channel they come from
+	opts.tagChannel = true
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	ws := &wildcardSubscription{
+		pattern:  pattern,
+		opts:     opts,
+		channels: make(map[string]struct{}),
+		cancel:   cancel,
+	}
+	s.wildcards[sub] = ws
+
+	if err := s.subscribeMatching(ctx, sub, ws, opts, msgChan); err != nil {
+		return err
+	}
+
+	go s.watchChannels(watchCtx, sub, ws, msgChan)
+
+	return nil
+}
+
+// subscribeMatching subscribes the subscriber to the channels matching the pattern it is not subscribed to yet,
+// the caller must hold the lock of the service
+func (s *Service) subscribeMatching(
+	ctx context.Context,
+	sub *pb.Subscriber,
+	ws *wildcardSubscription,
+	opts subscribeOptions,
+	msgChan chan<- *pb.Message,
+) error {
+	for _, channel := range s.storage.GetChannels() {
+		if _, subscribed := ws.channels[channel]; subscribed || !matchChannel(ws.pattern, channel) {
+			continue
+		}
+
+		// A channel with as many subscribers as it allows is left out, until the next channel is created
+		if s.subscriberLimitReached(channel) {
+			slog.Warn(
+				"skipping matching channel at its max subscribers",
+				slog.String("id", sub.GetId()),
+				slog.String("pattern", ws.pattern),
+				slog.String("channel", channel),
+			)
+			continue
+		}
+
+		if err := s.subscribePartitions(ctx, sub, channel, opts, msgChan, s.leaveFailed(sub, ws, channel)); err != nil {
+			return err
+		}
+		ws.channels[channel] = struct{}{}
+	}
+
+	return nil
+}
+
+// leaveFailed returns the function leaving a channel matching the pattern once its subscription fails, when it falls
+// behind the retention of the channel, while the other matching channels are still forwarded to the subscriber.
+// The channel is subscribed to again, from the oldest message kept, along with the next channel created.
+func (s *Service) leaveFailed(
+	sub *pb.Subscriber,
+	ws *wildcardSubscription,
+	channel string,
+) failFunc {
+	return func(failed *subscription) {
+		// The members of a partition group are waited for while the channel is left, so it is left by another goroutine
+		go func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			// The channel was deleted, or the subscriber has left it, in the meantime
+			if _, subscribed := ws.channels[channel]; !subscribed || s.wildcards[sub] != ws || s.subscriptions[failed.key] != failed {
+				return
+			}
+
+			slog.Warn(
+				"leaving matching channel after its subscription failed",
+				slog.String("id", sub.GetId()),
+				slog.String("pattern", ws.pattern),
+				slog.String("channel", channel),
+				slog.Any("error", failed.err),
+			)
+			s.unsubscribePartitions(sub, channel)
+			delete(ws.channels, channel)
+		}()
+	}
+}
+
+// watchChannels subscribes the subscriber to the channels matching the pattern as they are created,
+// until the context is done or the subscriber is unsubscribed
+func (s *Service) watchChannels(
+	ctx context.Context,
+	sub *pb.Subscriber,
+	ws *wildcardSubscription,
+	msgChan chan<- *pb.Message,
+) {
+	// A new channel is read from the beginning, so that the message it was created with is not missed
+	opts := ws.opts
+	opts.offset = pb.Offset_OFFSET_BEGINNING
+
+	for {
+		// Start watching before looking for new channels, so that a channel created in between is not missed
+		notify := s.storage.WatchChannels()
+
+		s.mu.Lock()
+		if s.wildcards[sub] != ws {
+			s.mu.Unlock()
+			return
+		}
+		err := s.subscribeMatching(ctx, sub, ws, opts, msgChan)
+		s.mu.Unlock()
+
+		if err != nil {
+			slog.Error(
+				"failed to subscribe to new channel",
+				slog.String("id", sub.GetId()),
+				slog.String("pattern", ws.pattern),
+				slog.Any("error", err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-notify:
+		}
+	}
+}
+
+// unsubscribeWildcard removes the subscriber from every channel it was subscribed to through the pattern
+func (s *Service) unsubscribeWildcard(
+	sub *pb.Subscriber,
+	pattern string,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ws, exists := s.wildcards[sub]
+	if !exists || ws.pattern != pattern {
+		slog.Error(
+			"error: cannot unsubscribe from pattern not subscribed to",
+			slog.String("id", sub.GetId()),
+			slog.String("ip", sub.GetIp()),
+			slog.String("pattern", pattern),
+		)
+		return ErrSubscriberDoesNotExist
+	}
+
+	// Stop watching for new channels
+	ws.cancel()
+	delete(s.wildcards, sub)
+
+	for channel := range ws.channels {
+		s.unsubscribePartitions(sub, channel)
+	}
+
+	slog.Info(
+		"subscriber removed",
+		slog.String("id", sub.GetId()),
+		slog.String("ip", sub.GetIp()),
+		slog.String("pattern", pattern),
+	)
+
+	return nil
+}
@@ -0,0 +1,380 @@
+// pkg/mq/wildcard_test.go
+
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hitesh22rana/mq/pkg/mocks"
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+	"github.com/hitesh22rana/mq/pkg/storage"
+)
+
+func TestMatchChannel(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		channel string
+		want    bool
+	}{
+		{
+			name:    "wildcard matches one token",
+			pattern: "orders.*.created",
+			channel: "orders.eu.created",
+			want:    true,
+		},
+		{
+			name:    "wildcard does not match several tokens",
+			pattern: "orders.*.created",
+			channel: "orders.eu.west.created",
+			want:    false,
+		},
+		{
+			name:    "wildcard does not match a missing token",
+			pattern: "orders.*",
+			channel: "orders",
+			want:    false,
+		},
+		{
+			name:    "full wildcard matches one token",
+			pattern: "orders.>",
+			channel: "orders.eu",
+			want:    true,
+		},
+		{
+			name:    "full wildcard matches several tokens",
+			pattern: "orders.>",
+			channel: "orders.eu.created",
+			want:    true,
+		},
+		{
+			name:    "full wildcard does not match a missing token",
+			pattern: "orders.>",
+			channel: "orders",
+			want:    false,
+		},
+		{
+			name:    "literal tokens must be equal",
+			pattern: "orders.*",
+			channel: "users.eu",
+			want:    false,
+		},
+		{
+			name:    "pattern longer than the channel",
+			pattern: "orders.*.*",
+			channel: "orders.eu",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchChannel(tt.pattern, tt.channel))
+		})
+	}
+}
+
+func TestValidPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		wildcard bool
+		valid    bool
+	}{
+		{
+			name:     "plain channel",
+			pattern:  "orders.eu.created",
+			wildcard: false,
+			valid:    true,
+		},
+		{
+			name:     "wildcards only as whole tokens",
+			pattern:  "orders*.eu>",
+			wildcard: false,
+			valid:    true,
+		},
+		{
+			name:     "wildcard",
+			pattern:  "*.eu.*",
+			wildcard: true,
+			valid:    true,
+		},
+		{
+			name:     "full wildcard",
+			pattern:  ">",
+			wildcard: true,
+			valid:    true,
+		},
+		{
+			name:     "full wildcard before the last token",
+			pattern:  "orders.>.created",
+			wildcard: true,
+			valid:    false,
+		},
+		{
+			name:     "empty token",
+			pattern:  "orders..*",
+			wildcard: true,
+			valid:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wildcard, isWildcard(tt.pattern))
+			assert.Equal(t, tt.valid, validPattern(tt.pattern))
+		})
+	}
+}
+
+func TestSubscribeServiceWildcard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := &pb.Subscriber{
+		Id: "unique-subscriber-id",
+		Ip: "ip-address",
+	}
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	t.Run("error: invalid pattern", func(t *testing.T) {
+		err := service.Subscribe(
+			ctx,
+			sub,
+			"orders.>.created",
+			&pb.SubscribeOptions{
+				Offset:       pb.Offset_OFFSET_BEGINNING,
+				StartOffset:  0,
+				StartTime:    0,
+				PullInterval: 0,
+				AckDeadline:  0,
+				Filter:       "",
+			},
+			msgChan,
+			errChan,
+		)
+		assert.Equal(t, status.Error(codes.InvalidArgument, ErrInvalidChannelPattern.Error()), err)
+		assert.Equal(t, ErrSubscriberDoesNotExist, service.UnSubscribe(ctx, sub, "orders.>.created"))
+	})
+
+	t.Run("success: messages of existing and new matching channels are tagged with their channel", func(t *testing.T) {
+		msg := &pb.Message{
+			Id:      "unique-message-id",
+			Content: []byte("test-content"),
+		}
+		idle := make(chan struct{})
+		channelsNotify := make(chan struct{})
+
+		// The existing matching channel, and the channel created later on, each have a message
+		for _, channel := range []string{"orders.eu", "orders.us"} {
+			mockStorage.EXPECT().
+				WatchChannel(channel).
+				Return((<-chan struct{})(idle), nil).
+				AnyTimes()
+			first := mockStorage.EXPECT().
+				GetMessages(channel, sub.GetId(), OffsetBeginning).
+				Return([]*pb.Message{msg}, uint64(0), nil)
+			mockStorage.EXPECT().
+				GetMessages(channel, sub.GetId(), uint64(1)).
+				Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+				After(first).
+				AnyTimes()
+		}
+		gomock.InOrder(
+			mockStorage.EXPECT().
+				GetChannels().
+				Return([]string{"orders.eu", "users.eu"}).
+				Times(2),
+			mockStorage.EXPECT().
+				GetChannels().
+				Return([]string{"orders.eu", "orders.us", "users.eu"}),
+		)
+		gomock.InOrder(
+			mockStorage.EXPECT().
+				WatchChannels().
+				Return((<-chan struct{})(channelsNotify)),
+			mockStorage.EXPECT().
+				WatchChannels().
+				Return((<-chan struct{})(idle)).
+				AnyTimes(),
+		)
+
+		err := service.Subscribe(
+			ctx,
+			sub,
+			"orders.*",
+			&pb.SubscribeOptions{
+				Offset:       pb.Offset_OFFSET_BEGINNING,
+				StartOffset:  0,
+				StartTime:    0,
+				PullInterval: 0,
+				AckDeadline:  0,
+				Filter:       "",
+			},
+			msgChan,
+			errChan,
+		)
+		assert.NoError(t, err)
+
+		select {
+		case got := <-msgChan:
+			assert.Equal(t, "orders.eu", got.GetChannel())
+			assert.Equal(t, msg.GetContent(), got.GetContent())
+		case <-time.After(time.Second):
+			t.Fatal("message of the existing channel was not pushed")
+		}
+
+		// The stored message is left untouched
+		assert.Empty(t, msg.GetChannel())
+
+		close(channelsNotify)
+
+		select {
+		case got := <-msgChan:
+			assert.Equal(t, "orders.us", got.GetChannel())
+		case <-time.After(time.Second):
+			t.Fatal("message of the new channel was not pushed")
+		}
+
+		// The subscriber is removed from every matching channel
+		mockStorage.EXPECT().
+			RemoveChannelFromSubscriberMap("orders.eu", sub.GetId()).
+			Return()
+		mockStorage.EXPECT().
+			RemoveChannelFromSubscriberMap("orders.us", sub.GetId()).
+			Return()
+		cancel()
+		assert.NoError(t, service.UnSubscribe(ctx, sub, "orders.*"))
+		assert.Empty(t, service.subscriptions)
+		assert.Empty(t, service.wildcards)
+	})
+}
+
+func TestSubscribeServiceWildcardOutOfRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockStorage(ctrl)
+	expectSinglePartition(mockStorage)
+
+	service := NewService(
+		&ServiceOptions{
+			Storage: mockStorage,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := &pb.Subscriber{
+		Id: "unique-subscriber-id",
+		Ip: "ip-address",
+	}
+	msgChan := make(chan *pb.Message)
+	errChan := make(chan error, 1)
+
+	// The subscriber has fallen behind the retention of orders.eu, while orders.us has a message
+	idle := make(chan struct{})
+	mockStorage.EXPECT().
+		GetChannels().
+		Return([]string{"orders.eu", "orders.us"}).
+		AnyTimes()
+	mockStorage.EXPECT().
+		WatchChannels().
+		Return((<-chan struct{})(idle)).
+		AnyTimes()
+	mockStorage.EXPECT().
+		WatchChannel(gomock.Any()).
+		Return((<-chan struct{})(idle), nil).
+		AnyTimes()
+	fallenBehind := mockStorage.EXPECT().
+		GetMessages("orders.eu", sub.GetId(), OffsetBeginning).
+		Return([]*pb.Message(nil), uint64(0), storage.ErrOffsetOutOfRange)
+	mockStorage.EXPECT().
+		GetMessages("orders.eu", sub.GetId(), OffsetBeginning).
+		Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+		After(fallenBehind).
+		AnyTimes()
+	first := mockStorage.EXPECT().
+		GetMessages("orders.us", sub.GetId(), OffsetBeginning).
+		Return([]*pb.Message{{Id: "unique-message-id"}}, uint64(0), nil)
+	mockStorage.EXPECT().
+		GetMessages("orders.us", sub.GetId(), uint64(1)).
+		Return([]*pb.Message(nil), uint64(0), storage.ErrInvalidOffset).
+		After(first).
+		AnyTimes()
+
+	// The subscriber leaves orders.eu only
+	left := make(chan struct{})
+	leaving := mockStorage.EXPECT().
+		RemoveChannelFromSubscriberMap("orders.eu", sub.GetId()).
+		Do(func(channel string, subscriberID string) {
+			close(left)
+		})
+
+	// The channel is subscribed to again once the watcher of the pattern looks for new channels
+	mockStorage.EXPECT().
+		RemoveChannelFromSubscriberMap("orders.eu", sub.GetId()).
+		After(leaving).
+		AnyTimes()
+
+	err := service.Subscribe(
+		ctx,
+		sub,
+		"orders.*",
+		&pb.SubscribeOptions{
+			Offset:       pb.Offset_OFFSET_BEGINNING,
+			StartOffset:  0,
+			StartTime:    0,
+			PullInterval: 0,
+			AckDeadline:  0,
+			Filter:       "",
+		},
+		msgChan,
+		errChan,
+	)
+	assert.NoError(t, err)
+
+	select {
+	case <-left:
+	case <-time.After(time.Second):
+		t.Fatal("the channel fallen behind was not left")
+	}
+
+	select {
+	case got := <-msgChan:
+		assert.Equal(t, "orders.us", got.GetChannel())
+	case err := <-errChan:
+		t.Fatalf("the failure of a matching channel ended the subscription: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("message of the other matching channel was not pushed")
+	}
+
+	mockStorage.EXPECT().
+		RemoveChannelFromSubscriberMap("orders.us", sub.GetId()).
+		Return()
+	cancel()
+	assert.NoError(t, service.UnSubscribe(ctx, sub, "orders.*"))
+	assert.Empty(t, service.subscriptions)
+	assert.Empty(t, errChan)
+}
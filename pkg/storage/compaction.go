@@ -0,0 +1,174 @@
+// pkg/storage/compaction.go
+
+package storage
+
+import (
+	"expvar"
+	"log/slog"
+
+	"github.com/rosedblabs/wal"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// minObsoleteRatio is the share of obsolete messages a compacted channel is compacted at,
+// so that the messages kept are not written again to the WAL on every sweep
+const minObsoleteRatio = 0.5
+
+// compactedMessages counts the obsolete messages removed by the compaction of their channel, by channel
+var compactedMessages = expvar.NewMap("mq_compacted_messages")
+
+// isTombstone reports whether the message deletes its key from a compacted channel
+func isTombstone(message *pb.Message) bool {
+	return len(message.GetContent()) == 0
+}
+
+// obsolete returns the function reporting whether a chunk of the list is obsolete, along with the number of
+// obsolete chunks. A chunk is obsolete once a later chunk has the same key, or once it is a tombstone every
+// subscriber has read, so that the subscribers reading the list get to know the key was deleted.
+func (cl *chunkList) obsolete() (func(*chunk) bool, uint64) {
+	latest := make(map[string]*chunk)
+	for iterator := cl.head; iterator != nil; iterator = iterator.next {
+		latest[iterator.data.GetKey()] = iterator
+	}
+
+	isObsolete := func(chunk *chunk) bool {
+		if latest[chunk.data.GetKey()] != chunk {
+			return true
+		}
+		if !isTombstone(chunk.data) {
+			return false
+		}
+
+		for _, lastChunk := range cl.cursors {
+			if lastChunk == nil || lastChunk.offset < chunk.offset {
+				return false
+			}
+		}
+		return true
+	}
+
+	var count uint64
+	for iterator := cl.head; iterator != nil; iterator = iterator.next {
+		if isObsolete(iterator) {
+			count++
+		}
+	}
+
+	return isObsolete, count
+}
+
+// restore replaces the chunks of the list with the chunks kept by a compaction, at their offsets, next is the offset
+// of the next chunk of the list when it was compacted. The chunks appended after the compaction are kept, which happens
+// when the compaction is replayed after a snapshot taken once it was over.
+func (cl *chunkList) restore(chunks []*chunk, next uint64) {
+	appended := make([]*chunk, 0)
+	for iterator := cl.chunkAt(next); iterator != nil; iterator = iterator.next {
+		appended = append(appended, iterator)
+	}
+	next = max(next, cl.len)
+
+	cl.head = nil
+	cl.tail = nil
+	cl.count = 0
+	cl.bytes = 0
+	cl.index = cl.index[:0]
+	for _, chunk := range append(chunks, appended...) {
+		chunk.prev = nil
+		chunk.next = nil
+		cl.len = chunk.data.GetOffset()
+		cl.appendChunk(chunk)
+	}
+
+	cl.len = max(next, cl.len)
+}
+
+// compact compacts every compacted channel, the partitions of a channel are compacted on their own
+func (m *MemoryStorage) compact() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for channel, msgList := range m.data {
+		name, config := m.configOf(channel)
+		if !config.GetCompacted() {
+			continue
+		}
+
+		msgList.mu.Lock()
+		compacted, err := m.compactList(channel, msgList)
+		msgList.mu.Unlock()
+
+		if err != nil {
+			slog.Error(
+				"failed to write compacted channel to WAL",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+		}
+
+		if compacted == 0 {
+			continue
+		}
+
+		compactedMessages.Add(name, int64(compacted))
+		slog.Info(
+			"channel compacted",
+			slog.String("channel", channel),
+			slog.Uint64("count", compacted),
+		)
+	}
+}
+
+// compactList removes the obsolete chunks of the list, once there are enough of them, and returns how many were removed.
+// The messages kept are written again to the WAL, followed by the end of the compaction, so that the WAL segments holding
+// the messages removed are deleted. A compaction that is not written to the WAL in full is not replayed on startup, the
+// chunks are only removed, and the messages kept moved to the segments they were written again to, once it is written.
+func (m *MemoryStorage) compactList(channel string, cl *chunkList) (uint64, error) {
+	isObsolete, obsolete := cl.obsolete()
+	if obsolete == 0 || float64(obsolete) < minObsoleteRatio*float64(cl.count) {
+		return 0, nil
+	}
+
+	segments := make(map[*chunk]wal.SegmentID, cl.count-obsolete)
+	for iterator := cl.head; iterator != nil; iterator = iterator.next {
+		if isObsolete(iterator) {
+			continue
+		}
+
+		position, err := m.writeEntry(&pb.WalEntry{
+			Channel: channel,
+			Type:    pb.WalEntryType_WAL_ENTRY_TYPE_COMPACTED_MESSAGE,
+			Message: iterator.data,
+		})
+		if err != nil {
+			return 0, err
+		}
+		segments[iterator] = position.SegmentId
+	}
+
+	if _, err := m.writeEntry(&pb.WalEntry{
+		Channel: channel,
+		Type:    pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_COMPACTED,
+		Offset:  cl.len,
+	}); err != nil {
+		return 0, err
+	}
+
+	removed := cl.removeChunks(isObsolete)
+	for iterator := cl.head; iterator != nil; iterator = iterator.next {
+		iterator.segment = segments[iterator]
+	}
+
+	return removed, nil
+}
+
+// writeEntry writes the entry to the WAL, and returns its position
+func (m *MemoryStorage) writeEntry(entry *pb.WalEntry) (*wal.ChunkPosition, error) {
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.wal.Write(data)
+}
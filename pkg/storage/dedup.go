@@ -0,0 +1,129 @@
+// pkg/storage/dedup.go
+
+package storage
+
+import (
+	"strconv"
+	"strings"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// producerKeyPrefix starts the idempotency keys derived from a producer ID and a sequence number, the idempotency
+// keys supplied by the publishers cannot start with a NUL byte so that they never collide with them
+const producerKeyPrefix = "\x00prod:"
+
+// ProducerKey returns the idempotency key of the message published by the producer with the sequence number
+func ProducerKey(producerID string, sequence uint64) string {
+	return producerKeyPrefix + producerID + ":" + strconv.FormatUint(sequence, 10)
+}
+
+// ParseProducerKey returns the producer ID and the sequence number the idempotency key was derived from,
+// and whether it was derived from them
+func ParseProducerKey(key string) (string, uint64, bool) {
+	if !strings.HasPrefix(key, producerKeyPrefix) {
+		return "", 0, false
+	}
+
+	i := strings.LastIndex(key, ":")
+	sequence, err := strconv.ParseUint(key[i+1:], 10, 64)
+	if i < len(producerKeyPrefix) || err != nil {
+		return "", 0, false
+	}
+
+	return key[len(producerKeyPrefix):i], sequence, true
+}
+
+// idempotencyKeys holds the messages saved to a channel with an idempotency key within the deduplication window,
+// in the order they were saved in, and the highest sequence number saved by every producer. The sequence number of
+// a producer is forgotten along with the message it was saved with, once the producer has not saved any message
+// within the window, and the sequence numbers are rebuilt from the messages replayed on startup.
+type idempotencyKeys struct {
+	dedup      map[string]*pb.Message
+	dedupOrder []*pb.Message
+	dedupHead  int
+	sequences  map[string]producerSequence
+}
+
+// producerSequence is the highest sequence number saved by a producer, along with the message it was saved with
+type producerSequence struct {
+	sequence uint64
+	message  *pb.Message
+}
+
+// newIdempotencyKeys initializes a new, empty, set of idempotency keys
+func newIdempotencyKeys() *idempotencyKeys {
+	return &idempotencyKeys{
+		dedup:      make(map[string]*pb.Message),
+		dedupOrder: make([]*pb.Message, 0),
+		dedupHead:  0,
+		sequences:  make(map[string]producerSequence),
+	}
+}
+
+// duplicateOf returns the message saved with the same idempotency key as the message,
+// no older than window seconds before it, or nil if there is none
+func (k *idempotencyKeys) duplicateOf(message *pb.Message, window int64) *pb.Message {
+	k.forget(message.GetCreatedAt() - window)
+	return k.dedup[message.GetIdempotencyKey()]
+}
+
+// stale reports whether the message was published by a producer with a sequence number that is not above the
+// highest one saved by the producer, or staged by it earlier in the batch (by producer ID), which is not saved.
+// The sequence number of a message that is not stale is staged in the batch, if there is one.
+func (k *idempotencyKeys) stale(message *pb.Message, batch map[string]uint64) bool {
+	producerID, sequence, ok := ParseProducerKey(message.GetIdempotencyKey())
+	if !ok {
+		return false
+	}
+
+	if last, exists := k.sequences[producerID]; exists && sequence <= last.sequence {
+		return true
+	}
+	if last, exists := batch[producerID]; exists && sequence <= last {
+		return true
+	}
+
+	if batch != nil {
+		batch[producerID] = sequence
+	}
+	return false
+}
+
+// remember records the idempotency key of a saved message, and the sequence number of its producer
+func (k *idempotencyKeys) remember(message *pb.Message) {
+	k.dedup[message.GetIdempotencyKey()] = message
+	k.dedupOrder = append(k.dedupOrder, message)
+
+	if producerID, sequence, ok := ParseProducerKey(message.GetIdempotencyKey()); ok {
+		if last, exists := k.sequences[producerID]; !exists || sequence > last.sequence {
+			k.sequences[producerID] = producerSequence{
+				sequence: sequence,
+				message:  message,
+			}
+		}
+	}
+}
+
+// forget forgets the idempotency keys of the messages created before the cutoff, in the order they were saved,
+// along with the sequence numbers of the producers they are the last message of. The messages left are copied
+// once most of them are forgotten, so that the messages forgotten do not stay referenced by the slice.
+func (k *idempotencyKeys) forget(cutoff int64) {
+	for k.dedupHead < len(k.dedupOrder) && k.dedupOrder[k.dedupHead].GetCreatedAt() < cutoff {
+		expired := k.dedupOrder[k.dedupHead]
+		k.dedupOrder[k.dedupHead] = nil
+		k.dedupHead++
+
+		if k.dedup[expired.GetIdempotencyKey()] == expired {
+			delete(k.dedup, expired.GetIdempotencyKey())
+		}
+		if producerID, _, ok := ParseProducerKey(expired.GetIdempotencyKey()); ok && k.sequences[producerID].message == expired {
+			delete(k.sequences, producerID)
+		}
+	}
+
+	if k.dedupHead > len(k.dedupOrder)/2 {
+		k.dedupOrder = append(make([]*pb.Message, 0, len(k.dedupOrder)-k.dedupHead), k.dedupOrder[k.dedupHead:]...)
+		k.dedupHead = 0
+	}
+}
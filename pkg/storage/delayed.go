@@ -0,0 +1,314 @@
+// pkg/storage/delayed.go
+
+package storage
+
+import (
+	"container/heap"
+	"log/slog"
+	"time"
+
+	"github.com/rosedblabs/wal"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// delayedRetryInterval is the time after which appending a due message is retried, when writing it to the WAL failed
+const delayedRetryInterval = time.Second
+
+// delayedMessage is a message hidden from the subscribers of its channel until it is due,
+// along with the WAL segment it was written to
+type delayedMessage struct {
+	message *pb.Message
+	segment wal.SegmentID
+	seq     uint64
+}
+
+// delayedQueue is a min-heap of the delayed messages of a chunk list, ordered by the time they are due
+// at and then by the order they were saved in
+type delayedQueue []*delayedMessage
+
+func (q delayedQueue) Len() int { return len(q) }
+
+func (q delayedQueue) Less(i, j int) bool {
+	if q[i].message.GetDeliverAt() != q[j].message.GetDeliverAt() {
+		return q[i].message.GetDeliverAt() < q[j].message.GetDeliverAt()
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q delayedQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *delayedQueue) Push(x any) { *q = append(*q, x.(*delayedMessage)) }
+
+func (q *delayedQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
+
+// isDelayed reports whether the message is not due yet, now is in milliseconds since the epoch
+func isDelayed(message *pb.Message, now int64) bool {
+	return message.GetDeliverAt() > now
+}
+
+// visibleAt returns the timestamp the message became visible to the subscribers of its channel at,
+// which is when it was created, or when it was due for a delayed message
+func visibleAt(message *pb.Message) int64 {
+	return max(message.GetCreatedAt(), message.GetDeliverAt()/int64(time.Second/time.Millisecond))
+}
+
+// delay hides the message from the subscribers of the list until it is due
+func (cl *chunkList) delay(message *pb.Message, segment wal.SegmentID) {
+	cl.delayedSeq++
+	heap.Push(&cl.delayed, &delayedMessage{
+		message: message,
+		segment: segment,
+		seq:     cl.delayedSeq,
+	})
+}
+
+// undelay removes the delayed message with the specified ID from the list, and returns it along with
+// the WAL segment it was written to, or nil if there is none
+func (cl *chunkList) undelay(id string) (*pb.Message, wal.SegmentID) {
+	for i, item := range cl.delayed {
+		if item.message.GetId() == id {
+			heap.Remove(&cl.delayed, i)
+			return item.message, item.segment
+		}
+	}
+	return nil, 0
+}
+
+// hasDelayed reports whether the list has a delayed message with the specified ID
+func (cl *chunkList) hasDelayed(id string) bool {
+	for _, item := range cl.delayed {
+		if item.message.GetId() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// appendDelayed appends a delayed message that is due to the list, the message is assigned the next offset of the list
+func (cl *chunkList) appendDelayed(message *pb.Message, segment wal.SegmentID) {
+	message.Offset = cl.len
+	cl.appendChunk(
+		&chunk{
+			data:    message,
+			segment: segment,
+			prev:    nil,
+			next:    nil,
+		},
+	)
+}
+
+// scheduleDelayed makes sure the delayed messages are appended to their channels once due,
+// it is called whenever a delayed message is saved
+func (m *MemoryStorage) scheduleDelayed() {
+	m.delayedOnce.Do(func() {
+		m.runInBackground(m.deliverDelayed)
+	})
+
+	select {
+	case m.delayedNotify <- struct{}{}:
+	default:
+	}
+}
+
+// deliverDelayed appends the delayed messages to their channels as they become due, until the storage is closed
+func (m *MemoryStorage) deliverDelayed() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		if next := m.appendDue(time.Now().UnixMilli()); next > 0 {
+			timer.Reset(time.Until(time.UnixMilli(next)))
+		} else {
+			timer.Stop()
+		}
+
+		select {
+		case <-timer.C:
+		case <-m.delayedNotify:
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+// appendDue appends the delayed messages that are due to their channels, in the order they are due, and returns
+// the time the next delayed message is due at or 0 if there is none. A message is written to the WAL as due before
+// it is appended, along with the offset it is given, so that it is given the same offset when the WAL is replayed.
+func (m *MemoryStorage) appendDue(now int64) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var next int64
+	for channel, msgList := range m.data {
+		msgList.mu.Lock()
+
+		appended := false
+		for len(msgList.delayed) > 0 && !isDelayed(msgList.delayed[0].message, now) {
+			item := msgList.delayed[0]
+			if err := m.writeDue(channel, item.message, msgList.len); err != nil {
+				break
+			}
+
+			heap.Pop(&msgList.delayed)
+			msgList.appendDelayed(item.message, item.segment)
+			appended = true
+		}
+
+		// Notify the subscribers waiting for new messages in the channel
+		if appended {
+			msgList.broadcast()
+		}
+
+		if len(msgList.delayed) > 0 {
+			due := msgList.delayed[0].message.GetDeliverAt()
+
+			// Retry the messages that could not be appended a bit later
+			if !isDelayed(msgList.delayed[0].message, now) {
+				due = now + delayedRetryInterval.Milliseconds()
+			}
+			if next == 0 || due < next {
+				next = due
+			}
+		}
+
+		msgList.mu.Unlock()
+	}
+
+	return next
+}
+
+// writeDue writes to the WAL that the delayed message is due, and the offset it is given
+func (m *MemoryStorage) writeDue(channel string, message *pb.Message, offset uint64) error {
+	entry := &pb.WalEntry{
+		Channel: channel,
+		Type:    pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE_DUE,
+		Message: &pb.Message{
+			Id: message.GetId(),
+		},
+		Offset: offset,
+	}
+
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		slog.Error(
+			"failed to marshal data",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	if _, err = m.wal.Write(data); err != nil {
+		slog.Error(
+			"failed to write to WAL",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// scheduleDelayed makes sure the delayed messages are appended to their channels once due,
+// it is called whenever a delayed message is saved
+func (d *DiskStorage) scheduleDelayed() {
+	d.delayedOnce.Do(func() {
+		go d.deliverDelayed()
+	})
+
+	select {
+	case d.delayedNotify <- struct{}{}:
+	default:
+	}
+}
+
+// deliverDelayed appends the delayed messages to their channels as they become due, until the storage is closed
+func (d *DiskStorage) deliverDelayed() {
+	timer := time.NewTimer(0)
+	for {
+		if next := d.appendDue(time.Now().UnixMilli()); next > 0 {
+			timer.Reset(time.Until(time.UnixMilli(next)))
+		} else {
+			timer.Stop()
+		}
+
+		select {
+		case <-timer.C:
+		case <-d.delayedNotify:
+		case <-d.closed:
+			return
+		}
+	}
+}
+
+// appendDue appends the delayed messages that are due to their channels, in the order they are due, and returns
+// the time the next delayed message is due at or 0 if there is none. A message is written to the metadata of its
+// channel as due before it is appended, along with the offset it is given, so that it is appended at that offset
+// when the channel is opened if it was not appended to the log.
+func (d *DiskStorage) appendDue(now int64) int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var next int64
+	for channel, ch := range d.data {
+		ch.mu.Lock()
+
+		appended := false
+		for len(ch.delayed) > 0 && !isDelayed(ch.delayed[0].message, now) {
+			item := ch.delayed[0]
+			offset := ch.log.next()
+			err := ch.writeMeta(&pb.WalEntry{
+				Type: pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE_DUE,
+				Message: &pb.Message{
+					Id: item.message.GetId(),
+				},
+				Offset: offset,
+			})
+			if err == nil {
+				item.message.Offset = offset
+				err = d.appendMessages(ch, []*pb.Message{item.message}, d.sync)
+			}
+			if err != nil {
+				slog.Error(
+					"failed to append due message",
+					slog.String("channel", channel),
+					slog.Any("error", err),
+				)
+				break
+			}
+
+			heap.Pop(&ch.delayed)
+			appended = true
+		}
+
+		// Notify the subscribers waiting for new messages in the channel
+		if appended {
+			ch.broadcast()
+		}
+
+		if len(ch.delayed) > 0 {
+			due := ch.delayed[0].message.GetDeliverAt()
+
+			// Retry the messages that could not be appended a bit later
+			if !isDelayed(ch.delayed[0].message, now) {
+				due = now + delayedRetryInterval.Milliseconds()
+			}
+			if next == 0 || due < next {
+				next = due
+			}
+		}
+
+		ch.mu.Unlock()
+	}
+
+	return next
+}
@@ -0,0 +1,771 @@
+// pkg/storage/disk_log.go
+
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+const (
+	// recordHeaderSize is the size of the header of a record, the length of its data followed by its CRC-32 checksum
+	recordHeaderSize = 8
+
+	// maxRecordSize is the size past which the length of a record is taken to be corrupt
+	maxRecordSize = 1 << 28
+
+	// indexEntrySize is the size of an entry of the sparse index of a segment
+	indexEntrySize = 32
+
+	// indexIntervalBytes is the number of bytes of records between two entries of the sparse index of a segment
+	indexIntervalBytes = 4096
+
+	// segmentFileExt is the extension of the files holding the records of the segments
+	segmentFileExt = ".log"
+
+	// indexFileExt is the extension of the files holding the sparse indexes of the segments
+	indexFileExt = ".index"
+
+	// rewriteBatchSize is the number of messages appended at once when a segment is rewritten
+	rewriteBatchSize = 256
+
+	// cleanedFileExt is appended to the files a segment is compacted into, until they replace the segment
+	cleanedFileExt = ".cleaned"
+)
+
+// crcTable is the table the checksums of the records are computed with
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errCorruptRecord is returned when a record does not match its checksum
+var errCorruptRecord = errors.New("corrupt record")
+
+// appendRecord appends the record of the data, its header followed by the data, to the buffer
+func appendRecord(buf []byte, data []byte) []byte {
+	var header [recordHeaderSize]byte
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.Checksum(data, crcTable))
+
+	buf = append(buf, header[:]...)
+	return append(buf, data...)
+}
+
+// readRecord reads the data of the next record, it returns io.EOF at the end of the records
+// and io.ErrUnexpectedEOF or errCorruptRecord for a record that was not written in full
+func readRecord(r *bufio.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return nil, errCorruptRecord
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errCorruptRecord
+	}
+
+	return data, nil
+}
+
+// indexEntry is an entry of the sparse index of a segment, the position of the record of a message in the segment,
+// along with the time the message became visible at and the number of records before it in the segment
+type indexEntry struct {
+	offset    uint64
+	position  int64
+	timestamp int64
+	ordinal   uint64
+}
+
+// segment is a file of the log of a channel, holding the records of the messages from its base offset on,
+// along with the file of its sparse index. The next offset of the segment is the offset after its last message,
+// or its base offset while it is empty, and latest is the latest time one of its messages became visible at.
+type segment struct {
+	base      uint64
+	next      uint64
+	size      int64
+	count     uint64
+	latest    int64
+	index     []indexEntry
+	file      *os.File
+	indexFile *os.File
+}
+
+// segmentName returns the name of the file of the segment starting at the base offset, with the extension
+func segmentName(dir string, base uint64, ext string) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", base, ext))
+}
+
+// openSegment opens the segment starting at the base offset, creating it if it does not exist
+func openSegment(dir string, base uint64) (*segment, error) {
+	return openSegmentFiles(segmentName(dir, base, segmentFileExt), segmentName(dir, base, indexFileExt), base)
+}
+
+// openSegmentFiles opens the segment starting at the base offset from its files. The records after the last entry
+// of the index are read to find the end of the segment, a record that was not written in full is truncated.
+func openSegmentFiles(name string, indexName string, base uint64) (*segment, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	indexFile, err := os.OpenFile(indexName, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	seg := &segment{
+		base:      base,
+		next:      base,
+		size:      0,
+		count:     0,
+		latest:    0,
+		index:     make([]indexEntry, 0),
+		file:      file,
+		indexFile: indexFile,
+	}
+
+	if err := seg.recover(); err != nil {
+		seg.close()
+		return nil, err
+	}
+
+	return seg, nil
+}
+
+// recover loads the index of the segment and reads the records after its last entry, the index entries
+// past the end of the records and the records that were not written in full are truncated
+func (seg *segment) recover() error {
+	info, err := seg.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(seg.indexFile)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+indexEntrySize <= len(data); i += indexEntrySize {
+		entry := indexEntry{
+			offset:    binary.BigEndian.Uint64(data[i:]),
+			position:  int64(binary.BigEndian.Uint64(data[i+8:])),
+			timestamp: int64(binary.BigEndian.Uint64(data[i+16:])),
+			ordinal:   binary.BigEndian.Uint64(data[i+24:]),
+		}
+		if entry.position >= info.Size() {
+			break
+		}
+		seg.index = append(seg.index, entry)
+	}
+
+	// Read the records from the last indexed one on
+	position, ordinal := int64(0), uint64(0)
+	if len(seg.index) > 0 {
+		last := seg.index[len(seg.index)-1]
+		position, ordinal = last.position, last.ordinal
+		seg.next = last.offset
+		seg.latest = last.timestamp
+	}
+
+	indexed := position
+	if len(seg.index) == 0 {
+		indexed = -indexIntervalBytes
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(seg.file, position, info.Size()-position))
+	entries := make([]indexEntry, 0)
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF {
+			break
+		}
+
+		message := &pb.Message{}
+		if err == nil {
+			err = proto.Unmarshal(data, message)
+		}
+		if err != nil {
+			slog.Warn(
+				"truncating segment after a record that was not written in full",
+				slog.String("segment", seg.file.Name()),
+				slog.Int64("position", position),
+				slog.Any("error", err),
+			)
+			break
+		}
+
+		if position-indexed >= indexIntervalBytes {
+			entries = append(entries, indexEntry{
+				offset:    message.GetOffset(),
+				position:  position,
+				timestamp: visibleAt(message),
+				ordinal:   ordinal,
+			})
+			indexed = position
+		}
+
+		seg.next = message.GetOffset() + 1
+		seg.latest = max(seg.latest, visibleAt(message))
+		position += int64(recordHeaderSize + len(data))
+		ordinal++
+	}
+
+	// An indexed record that was not written in full is not indexed anymore
+	for len(seg.index) > 0 && seg.index[len(seg.index)-1].position >= position {
+		seg.index = seg.index[:len(seg.index)-1]
+	}
+
+	seg.size = position
+	seg.count = ordinal
+	if position < info.Size() {
+		if err := seg.file.Truncate(position); err != nil {
+			return err
+		}
+	}
+	if err := seg.indexFile.Truncate(int64(len(seg.index) * indexEntrySize)); err != nil {
+		return err
+	}
+
+	return seg.writeIndex(entries)
+}
+
+// writeIndex appends the entries to the index of the segment
+func (seg *segment) writeIndex(entries []indexEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, len(entries)*indexEntrySize)
+	for i, entry := range entries {
+		binary.BigEndian.PutUint64(buf[i*indexEntrySize:], entry.offset)
+		binary.BigEndian.PutUint64(buf[i*indexEntrySize+8:], uint64(entry.position))
+		binary.BigEndian.PutUint64(buf[i*indexEntrySize+16:], uint64(entry.timestamp))
+		binary.BigEndian.PutUint64(buf[i*indexEntrySize+24:], entry.ordinal)
+	}
+
+	if _, err := seg.indexFile.WriteAt(buf, int64(len(seg.index)*indexEntrySize)); err != nil {
+		return err
+	}
+
+	seg.index = append(seg.index, entries...)
+	return nil
+}
+
+// append appends the records of the messages to the segment, the messages are indexed every indexIntervalBytes,
+// starting with the first one. Either all the records are appended or the segment is left as it was.
+func (seg *segment) append(messages []*pb.Message) error {
+	indexed := int64(-indexIntervalBytes)
+	if len(seg.index) > 0 {
+		indexed = seg.index[len(seg.index)-1].position
+	}
+
+	buf := make([]byte, 0)
+	entries := make([]indexEntry, 0)
+	position, ordinal, latest := seg.size, seg.count, seg.latest
+	for _, message := range messages {
+		data, err := proto.Marshal(message)
+		if err != nil {
+			return err
+		}
+
+		if position-indexed >= indexIntervalBytes {
+			entries = append(entries, indexEntry{
+				offset:    message.GetOffset(),
+				position:  position,
+				timestamp: visibleAt(message),
+				ordinal:   ordinal,
+			})
+			indexed = position
+		}
+
+		buf = appendRecord(buf, data)
+		position += int64(recordHeaderSize + len(data))
+		latest = max(latest, visibleAt(message))
+		ordinal++
+	}
+
+	if _, err := seg.file.WriteAt(buf, seg.size); err != nil {
+		_ = seg.file.Truncate(seg.size)
+		return err
+	}
+
+	if err := seg.writeIndex(entries); err != nil {
+		_ = seg.file.Truncate(seg.size)
+		_ = seg.indexFile.Truncate(int64(len(seg.index) * indexEntrySize))
+		return err
+	}
+
+	seg.size = position
+	seg.count = ordinal
+	seg.latest = latest
+	seg.next = messages[len(messages)-1].GetOffset() + 1
+	return nil
+}
+
+// read calls fn with the messages of the segment from the specified offset on, in order, until fn returns false,
+// and reports whether fn asked for more messages
+func (seg *segment) read(offset uint64, fn func(*pb.Message) bool) (bool, error) {
+	// Start from the last indexed message at or before the offset
+	i := sort.Search(len(seg.index), func(i int) bool {
+		return seg.index[i].offset > offset
+	}) - 1
+
+	position := int64(0)
+	if i >= 0 {
+		position = seg.index[i].position
+	}
+
+	return seg.readFrom(position, func(message *pb.Message) bool {
+		return message.GetOffset() < offset || fn(message)
+	})
+}
+
+// readFrom calls fn with the messages of the segment from the record at the position on, until fn returns false,
+// and reports whether fn asked for more messages
+func (seg *segment) readFrom(position int64, fn func(*pb.Message) bool) (bool, error) {
+	r := bufio.NewReader(io.NewSectionReader(seg.file, position, seg.size-position))
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		message := &pb.Message{}
+		if err := proto.Unmarshal(data, message); err != nil {
+			return false, err
+		}
+
+		if !fn(message) {
+			return false, nil
+		}
+	}
+}
+
+// positionAtTime returns the position of the last indexed record of a message visible before the timestamp,
+// the messages visible at or after it are found by reading on from there
+func (seg *segment) positionAtTime(timestamp int64) int64 {
+	i := sort.Search(len(seg.index), func(i int) bool {
+		return seg.index[i].timestamp >= timestamp
+	}) - 1
+
+	if i < 0 {
+		return 0
+	}
+
+	return seg.index[i].position
+}
+
+// sync commits the segment and its index to stable storage
+func (seg *segment) sync() error {
+	if err := seg.file.Sync(); err != nil {
+		return err
+	}
+
+	return seg.indexFile.Sync()
+}
+
+// close closes the files of the segment
+func (seg *segment) close() {
+	_ = seg.file.Close()
+	_ = seg.indexFile.Close()
+}
+
+// remove closes and deletes the files of the segment
+func (seg *segment) remove() error {
+	seg.close()
+
+	for _, name := range []string{seg.file.Name(), seg.indexFile.Name()} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diskLog is the log of a channel (or a partition of a channel), an ordered list of segments, the last of which
+// is the active segment the messages are appended to. The segments are named after their base offset, a new
+// segment is started once the active one is past the segment size.
+type diskLog struct {
+	dir         string
+	segmentSize int64
+	segments    []*segment
+}
+
+// logMark is the end of the log at some point, the log is truncated back to it when a write fails
+type logMark struct {
+	segments int
+	size     int64
+	count    uint64
+	next     uint64
+	latest   int64
+	index    int
+}
+
+// openLog opens the log in the directory, creating it if it does not exist
+func openLog(dir string, segmentSize int64) (*diskLog, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The segments left compacted only in part are thrown away
+	bases := make([]uint64, 0)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), cleanedFileExt) {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+
+		base, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), segmentFileExt), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), segmentFileExt) {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	if len(bases) == 0 {
+		bases = append(bases, 0)
+	}
+
+	l := &diskLog{
+		dir:         dir,
+		segmentSize: segmentSize,
+		segments:    make([]*segment, 0, len(bases)),
+	}
+	for _, base := range bases {
+		seg, err := openSegment(dir, base)
+		if err != nil {
+			l.close()
+			return nil, err
+		}
+		l.segments = append(l.segments, seg)
+	}
+
+	return l, nil
+}
+
+// active returns the segment the messages are appended to
+func (l *diskLog) active() *segment {
+	return l.segments[len(l.segments)-1]
+}
+
+// next returns the offset of the next message appended to the log
+func (l *diskLog) next() uint64 {
+	return l.active().next
+}
+
+// retainedFrom returns the first offset kept by the log, the segments before it having been deleted
+func (l *diskLog) retainedFrom() uint64 {
+	return l.segments[0].base
+}
+
+// count returns the number of messages in the log
+func (l *diskLog) count() uint64 {
+	var count uint64
+	for _, seg := range l.segments {
+		count += seg.count
+	}
+	return count
+}
+
+// bytes returns the size of the records of the log
+func (l *diskLog) bytes() uint64 {
+	var size uint64
+	for _, seg := range l.segments {
+		size += uint64(seg.size)
+	}
+	return size
+}
+
+// ends returns the first and the last message of the log, or nil if the log is empty
+func (l *diskLog) ends() (*pb.Message, *pb.Message, error) {
+	var first, last *pb.Message
+	for _, seg := range l.segments {
+		if seg.count == 0 {
+			continue
+		}
+
+		if _, err := seg.readFrom(0, func(message *pb.Message) bool {
+			first = message
+			return false
+		}); err != nil {
+			return nil, nil, err
+		}
+		break
+	}
+
+	// The last message is found by reading on from the last indexed record of the last segment holding messages
+	for i := len(l.segments) - 1; i >= 0; i-- {
+		seg := l.segments[i]
+		if seg.count == 0 {
+			continue
+		}
+
+		position := int64(0)
+		if len(seg.index) > 0 {
+			position = seg.index[len(seg.index)-1].position
+		}
+		if _, err := seg.readFrom(position, func(message *pb.Message) bool {
+			last = message
+			return true
+		}); err != nil {
+			return nil, nil, err
+		}
+		break
+	}
+
+	return first, last, nil
+}
+
+// mark returns the end of the log
+func (l *diskLog) mark() logMark {
+	active := l.active()
+	return logMark{
+		segments: len(l.segments),
+		size:     active.size,
+		count:    active.count,
+		next:     active.next,
+		latest:   active.latest,
+		index:    len(active.index),
+	}
+}
+
+// truncate truncates the log back to the mark, the segments started since are deleted
+func (l *diskLog) truncate(mark logMark) error {
+	for len(l.segments) > mark.segments {
+		if err := l.active().remove(); err != nil {
+			return err
+		}
+		l.segments = l.segments[:len(l.segments)-1]
+	}
+
+	active := l.active()
+	if err := active.file.Truncate(mark.size); err != nil {
+		return err
+	}
+	if err := active.indexFile.Truncate(int64(mark.index * indexEntrySize)); err != nil {
+		return err
+	}
+
+	active.size = mark.size
+	active.count = mark.count
+	active.next = mark.next
+	active.latest = mark.latest
+	active.index = active.index[:mark.index]
+	return nil
+}
+
+// append appends the messages to the log, which were given the next offsets of the log. A new segment
+// is started first if the messages would take the active segment past the segment size.
+func (l *diskLog) append(messages []*pb.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var size int64
+	for _, message := range messages {
+		size += int64(recordHeaderSize + proto.Size(message))
+	}
+
+	if active := l.active(); active.size > 0 && active.size+size > l.segmentSize {
+		if err := active.sync(); err != nil {
+			return err
+		}
+
+		seg, err := openSegment(l.dir, active.next)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, seg)
+	}
+
+	return l.active().append(messages)
+}
+
+// read calls fn with the messages of the log from the specified offset on, in order, until fn returns false
+func (l *diskLog) read(offset uint64, fn func(*pb.Message) bool) error {
+	// Start from the last segment at or before the offset
+	i := max(sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].base > offset
+	})-1, 0)
+
+	for _, seg := range l.segments[i:] {
+		more, err := seg.read(offset, fn)
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// offsetAtTime returns the offset of the first message visible at or after the timestamp,
+// or the offset of the next message appended to the log if there is none
+func (l *diskLog) offsetAtTime(timestamp int64) (uint64, error) {
+	// Find the first segment with a message visible at or after the timestamp
+	i := sort.Search(len(l.segments), func(i int) bool {
+		return l.segments[i].latest >= timestamp
+	})
+
+	offset := l.next()
+	for _, seg := range l.segments[min(i, len(l.segments)):] {
+		more, err := seg.readFrom(seg.positionAtTime(timestamp), func(message *pb.Message) bool {
+			if visibleAt(message) < timestamp {
+				return true
+			}
+			offset = message.GetOffset()
+			return false
+		})
+		if err != nil || !more {
+			return offset, err
+		}
+	}
+
+	return offset, nil
+}
+
+// sync commits the active segment to stable storage, the other segments were synced when they were closed
+func (l *diskLog) sync() error {
+	return l.active().sync()
+}
+
+// close closes the files of the segments of the log
+func (l *diskLog) close() {
+	for _, seg := range l.segments {
+		seg.close()
+	}
+}
+
+// deleteSegments deletes the segments before the i-th segment, the active segment is never deleted
+func (l *diskLog) deleteSegments(i int) error {
+	i = min(i, len(l.segments)-1)
+	for len(l.segments) > 0 && i > 0 {
+		if err := l.segments[0].remove(); err != nil {
+			return err
+		}
+		l.segments = l.segments[1:]
+		i--
+	}
+
+	return nil
+}
+
+// purge deletes the messages of the log, the log starts over from an empty segment at its next offset
+// so that the offsets of the messages deleted are not given out again
+func (l *diskLog) purge() error {
+	if active := l.active(); active.size > 0 {
+		if err := active.sync(); err != nil {
+			return err
+		}
+
+		seg, err := openSegment(l.dir, active.next)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, seg)
+	}
+
+	return l.deleteSegments(len(l.segments) - 1)
+}
+
+// rewrite rewrites the i-th segment of the log with only the messages kept, and returns how many were removed.
+// The segment is written to new files that replace the files of the segment once written in full.
+func (l *diskLog) rewrite(i int, keep func(*pb.Message) bool) (uint64, error) {
+	old := l.segments[i]
+	name, indexName := old.file.Name(), old.indexFile.Name()
+
+	_ = os.Remove(name + cleanedFileExt)
+	_ = os.Remove(indexName + cleanedFileExt)
+	seg, err := openSegmentFiles(name+cleanedFileExt, indexName+cleanedFileExt, old.base)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed uint64
+	var writeErr error
+	batch := make([]*pb.Message, 0)
+	_, err = old.readFrom(0, func(message *pb.Message) bool {
+		if !keep(message) {
+			removed++
+			return true
+		}
+
+		batch = append(batch, message)
+		if len(batch) < rewriteBatchSize {
+			return true
+		}
+		writeErr = seg.append(batch)
+		batch = batch[:0]
+		return writeErr == nil
+	})
+	if err == nil {
+		err = writeErr
+	}
+	if err == nil && len(batch) > 0 {
+		err = seg.append(batch)
+	}
+	if err == nil {
+		err = seg.sync()
+	}
+	if err != nil {
+		_ = seg.remove()
+		return 0, err
+	}
+
+	// The index of the segment is emptied first, so that the segment is indexed again when opened
+	// if the files are not both replaced
+	if err := old.indexFile.Truncate(0); err != nil {
+		_ = seg.remove()
+		return 0, err
+	}
+	if err := os.Rename(name+cleanedFileExt, name); err != nil {
+		_ = seg.remove()
+		return 0, err
+	}
+	if err := os.Rename(indexName+cleanedFileExt, indexName); err != nil {
+		return 0, err
+	}
+
+	// The segment spans the same offsets, even if its last messages were removed
+	seg.next = old.next
+	seg.latest = max(seg.latest, old.latest)
+	old.close()
+	l.segments[i] = seg
+
+	return removed, nil
+}
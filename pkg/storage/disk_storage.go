@@ -0,0 +1,1192 @@
+// pkg/storage/disk_storage.go
+
+package storage
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+const (
+	// metaFileName is the name of the file holding the metadata of a channel, in the directory of the channel
+	metaFileName = "channel.meta"
+
+	// deletedDirExt is appended to the directory of a deleted channel until it is removed, the names of
+	// the directories of the channels never have it as the dots of the channel names are escaped
+	deletedDirExt = ".deleted"
+
+	// minMetaEntries is the number of entries the metadata of a channel is not rewritten under
+	minMetaEntries = 64
+)
+
+// diskChannel is a channel (or a partition of a channel) stored on disk, the log of its messages along with its
+// metadata: its configuration, the offsets committed by its durable subscriptions and its delayed messages, written
+// as WAL entries to a file of their own. The messages published with an idempotency key within the deduplication
+// window, the delayed messages that are not due yet and the next offset read by each subscriber are kept in memory.
+// A channel has a lock of its own so that channels and partitions are written in parallel.
+type diskChannel struct {
+	mu          sync.Mutex
+	dir         string
+	log         *diskLog
+	meta        *os.File
+	metaSize    int64
+	metaEntries uint64
+	sync        bool
+	notify      chan struct{}
+	config      *pb.ChannelConfig
+	keys        *idempotencyKeys
+	delayed     delayedQueue
+	delayedSeq  uint64
+	compactedAt uint64
+	cursors     map[string]uint64
+	lagging     map[string]struct{}
+	committed   map[string]uint64
+}
+
+// channelDir returns the directory a channel is stored in, the name of the channel is escaped
+// so that any channel name makes a valid directory name
+func channelDir(dirPath string, channel string) string {
+	return filepath.Join(dirPath, strings.ReplaceAll(url.PathEscape(channel), ".", "%2E"))
+}
+
+// broadcast wakes up everyone waiting for new messages in the channel
+func (ch *diskChannel) broadcast() {
+	close(ch.notify)
+	ch.notify = make(chan struct{})
+}
+
+// delay hides the message from the subscribers of the channel until it is due
+func (ch *diskChannel) delay(message *pb.Message) {
+	ch.delayedSeq++
+	heap.Push(&ch.delayed, &delayedMessage{
+		message: message,
+		segment: 0,
+		seq:     ch.delayedSeq,
+	})
+}
+
+// readMeta reads the entries of the metadata of the channel, the entry that was not written in full is truncated
+func (ch *diskChannel) readMeta() ([]*pb.WalEntry, error) {
+	info, err := ch.meta.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*pb.WalEntry, 0)
+	r := bufio.NewReader(io.NewSectionReader(ch.meta, 0, info.Size()))
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF {
+			break
+		}
+
+		entry := &pb.WalEntry{}
+		if err == nil {
+			err = proto.Unmarshal(data, entry)
+		}
+		if err != nil {
+			slog.Warn(
+				"truncating channel metadata after an entry that was not written in full",
+				slog.String("channel", ch.dir),
+				slog.Any("error", err),
+			)
+			break
+		}
+
+		entries = append(entries, entry)
+		ch.metaSize += int64(recordHeaderSize + len(data))
+	}
+
+	ch.metaEntries = uint64(len(entries))
+	if ch.metaSize < info.Size() {
+		return entries, ch.meta.Truncate(ch.metaSize)
+	}
+
+	return entries, nil
+}
+
+// writeMeta appends the entries to the metadata of the channel, either all the entries are written or none is
+func (ch *diskChannel) writeMeta(entries ...*pb.WalEntry) error {
+	buf := make([]byte, 0)
+	for _, entry := range entries {
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf = appendRecord(buf, data)
+	}
+
+	if _, err := ch.meta.WriteAt(buf, ch.metaSize); err != nil {
+		_ = ch.meta.Truncate(ch.metaSize)
+		return err
+	}
+
+	if ch.sync {
+		if err := ch.meta.Sync(); err != nil {
+			_ = ch.meta.Truncate(ch.metaSize)
+			return err
+		}
+	}
+
+	ch.metaSize += int64(len(buf))
+	ch.metaEntries += uint64(len(entries))
+	return nil
+}
+
+// liveMeta returns the entries the metadata of the channel is made of, the configuration of the channel,
+// the offsets committed by its durable subscriptions and its delayed messages that are not due yet
+func (ch *diskChannel) liveMeta() []*pb.WalEntry {
+	entries := []*pb.WalEntry{
+		{
+			Type:   pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG,
+			Config: ch.config,
+		},
+	}
+	for subscriberID, offset := range ch.committed {
+		entries = append(entries, &pb.WalEntry{
+			Type:         pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET,
+			Subscription: subscriberID,
+			Offset:       offset,
+		})
+	}
+
+	delayed := append(delayedQueue(nil), ch.delayed...)
+	sort.Slice(delayed, func(i, j int) bool { return delayed[i].seq < delayed[j].seq })
+	for _, item := range delayed {
+		entries = append(entries, &pb.WalEntry{
+			Type:    pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE,
+			Message: item.message,
+		})
+	}
+
+	return entries
+}
+
+// rewriteMeta rewrites the metadata of the channel with only its live entries, once most of its entries are superseded
+func (ch *diskChannel) rewriteMeta() error {
+	entries := ch.liveMeta()
+	if ch.metaEntries < minMetaEntries || ch.metaEntries <= 2*uint64(len(entries)) {
+		return nil
+	}
+
+	return ch.replaceMeta(entries)
+}
+
+// replaceMeta replaces the metadata of the channel with the entries, the entries are written
+// to a new file that replaces the metadata once written in full
+func (ch *diskChannel) replaceMeta(entries []*pb.WalEntry) error {
+	buf := make([]byte, 0)
+	for _, entry := range entries {
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf = appendRecord(buf, data)
+	}
+
+	name := filepath.Join(ch.dir, metaFileName)
+	file, err := os.OpenFile(name+cleanedFileExt, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(buf); err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = os.Rename(name+cleanedFileExt, name)
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(name + cleanedFileExt)
+		return err
+	}
+
+	_ = ch.meta.Close()
+	ch.meta = file
+	ch.metaSize = int64(len(buf))
+	ch.metaEntries = uint64(len(entries))
+	return nil
+}
+
+// DiskStorageOptions represents the options for the DiskStorage, every channel is stored in a directory
+// of its own under the directory path, its log is made of segment files of about the segment size
+type DiskStorageOptions struct {
+	DirPath       string
+	SegmentSize   int64
+	Sync          bool
+	BatchSize     uint64
+	DedupWindow   time.Duration
+	SweepInterval time.Duration
+}
+
+// DiskStorage is an implementation of the Storage interface keeping the messages of every channel on disk,
+// in an append-only log of segment files with a sparse offset index, the messages are read from disk.
+// The lock of the storage guards the set of channels, a channel (or a partition) is read and written under
+// the read lock of the storage and the lock of the channel, while batches spanning several channels are
+// written under the write lock of the storage.
+type DiskStorage struct {
+	mu             sync.RWMutex
+	dirPath        string
+	segmentSize    int64
+	sync           bool
+	batchSize      uint64
+	dedupWindow    int64
+	data           map[string]*diskChannel
+	channelsNotify chan struct{}
+	delayedNotify  chan struct{}
+	delayedOnce    sync.Once
+	sweepInterval  time.Duration
+	closed         chan struct{}
+}
+
+// NewDiskStorage initializes a new DiskStorage instance, the channels stored under the directory path are opened
+func NewDiskStorage(
+	options *DiskStorageOptions,
+) (*DiskStorage, error) {
+	d := &DiskStorage{
+		mu:             sync.RWMutex{},
+		dirPath:        options.DirPath,
+		segmentSize:    options.SegmentSize,
+		sync:           options.Sync,
+		batchSize:      options.BatchSize,
+		dedupWindow:    int64(options.DedupWindow / time.Second),
+		data:           make(map[string]*diskChannel),
+		channelsNotify: make(chan struct{}),
+		delayedNotify:  make(chan struct{}, 1),
+		delayedOnce:    sync.Once{},
+		sweepInterval:  options.SweepInterval,
+		closed:         make(chan struct{}),
+	}
+
+	if err := os.MkdirAll(d.dirPath, 0o755); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(d.dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		// The channels deleted only in part are thrown away
+		if strings.HasSuffix(entry.Name(), deletedDirExt) {
+			_ = os.RemoveAll(filepath.Join(d.dirPath, entry.Name()))
+			continue
+		}
+
+		channel, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		ch, err := d.openChannel(channel)
+		if err != nil {
+			d.Close()
+			return nil, fmt.Errorf("failed to open channel '%s': %w", channel, err)
+		}
+		d.data[channel] = ch
+	}
+
+	// Append the delayed messages that are still pending once due
+	for _, ch := range d.data {
+		if len(ch.delayed) > 0 {
+			d.scheduleDelayed()
+			break
+		}
+	}
+
+	if d.sweepInterval > 0 {
+		go d.sweep()
+	}
+
+	return d, nil
+}
+
+// openChannel opens the log and the metadata of the channel, creating them if they do not exist. The delayed
+// messages written as due whose message was not appended to the log are appended at the offset they were given.
+func (d *DiskStorage) openChannel(channel string) (*diskChannel, error) {
+	dir := channelDir(d.dirPath, channel)
+	log, err := openLog(dir, d.segmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := os.OpenFile(filepath.Join(dir, metaFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		log.close()
+		return nil, err
+	}
+
+	ch := &diskChannel{
+		mu:          sync.Mutex{},
+		dir:         dir,
+		log:         log,
+		meta:        meta,
+		metaSize:    0,
+		metaEntries: 0,
+		sync:        d.sync,
+		notify:      make(chan struct{}),
+		config:      &pb.ChannelConfig{},
+		keys:        newIdempotencyKeys(),
+		delayed:     make(delayedQueue, 0),
+		delayedSeq:  0,
+		compactedAt: 0,
+		cursors:     make(map[string]uint64),
+		lagging:     make(map[string]struct{}),
+		committed:   make(map[string]uint64),
+	}
+
+	entries, err := ch.readMeta()
+	if err != nil {
+		ch.close()
+		return nil, err
+	}
+
+	pending := make(map[string]*pb.Message)
+	order := make([]string, 0)
+	for _, entry := range entries {
+		switch entry.GetType() {
+		case pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG:
+			ch.config = entry.GetConfig()
+		case pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET:
+			ch.committed[entry.GetSubscription()] = entry.GetOffset()
+		case pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE:
+			pending[entry.GetMessage().GetId()] = entry.GetMessage()
+			order = append(order, entry.GetMessage().GetId())
+		case pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE_DUE:
+			message, exists := pending[entry.GetMessage().GetId()]
+			if !exists {
+				continue
+			}
+			delete(pending, entry.GetMessage().GetId())
+
+			if entry.GetOffset() < log.next() {
+				continue
+			}
+			message.Offset = entry.GetOffset()
+			if err := log.append([]*pb.Message{message}); err != nil {
+				ch.close()
+				return nil, err
+			}
+		}
+	}
+
+	// Rebuild the deduplication state from the messages still within the window
+	if d.dedupWindow > 0 {
+		cutoff := time.Now().Unix() - d.dedupWindow
+		offset, err := log.offsetAtTime(cutoff)
+		if err == nil {
+			err = log.read(offset, func(message *pb.Message) bool {
+				if d.deduplicates(message) && message.GetCreatedAt() >= cutoff {
+					ch.keys.remember(message)
+				}
+				return true
+			})
+		}
+		if err != nil {
+			ch.close()
+			return nil, err
+		}
+	}
+
+	// Hide the delayed messages until they are due
+	for _, id := range order {
+		if message, exists := pending[id]; exists {
+			if d.deduplicates(message) {
+				ch.keys.remember(message)
+			}
+			ch.delay(message)
+		}
+	}
+
+	// Position the durable subscriptions on the offset they resume from, a subscription
+	// that has fallen behind the retention of its channel is told so on its next read
+	for subscriberID, offset := range ch.committed {
+		if offset > 0 && offset < log.retainedFrom() {
+			ch.lagging[subscriberID] = struct{}{}
+			continue
+		}
+		ch.cursors[subscriberID] = offset
+	}
+
+	return ch, nil
+}
+
+// close closes the files of the channel
+func (ch *diskChannel) close() {
+	ch.log.close()
+	_ = ch.meta.Close()
+}
+
+// Close stops appending the delayed messages and sweeping the channels, and closes the files
+// of every channel, the storage is not used afterwards
+func (d *DiskStorage) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	select {
+	case <-d.closed:
+		return
+	default:
+		close(d.closed)
+	}
+
+	for _, ch := range d.data {
+		ch.mu.Lock()
+		ch.close()
+		ch.mu.Unlock()
+	}
+}
+
+// Sync commits the channels to stable storage
+func (d *DiskStorage) Sync() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, ch := range d.data {
+		ch.mu.Lock()
+		err := ch.log.sync()
+		if err == nil {
+			err = ch.meta.Sync()
+		}
+		ch.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deduplicates reports whether the message is deduplicated on its idempotency key
+func (d *DiskStorage) deduplicates(message *pb.Message) bool {
+	return d.dedupWindow > 0 && message.GetIdempotencyKey() != ""
+}
+
+// SaveMessage saves a message to the specified channel, which must exist, the message is assigned its offset in the channel.
+// Duplicates are deduplicated, stale messages of producers refused and delayed messages hidden until they are due,
+// like in the MemoryStorage.
+func (d *DiskStorage) SaveMessage(
+	channel string,
+	message *pb.Message,
+) (uint64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	// Channels are only created explicitly
+	ch, exists := d.data[channel]
+	if !exists {
+		return 0, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+	_, config := d.configOf(channel)
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	// Return the offset of the original message, if the message is a duplicate, a message of its producer
+	// that is not a duplicate has to have a sequence number above the ones saved by the producer
+	if d.deduplicates(message) {
+		if original := ch.keys.duplicateOf(message, d.dedupWindow); original != nil {
+			copyDuplicate(message, original)
+			return message.GetOffset(), nil
+		}
+		if ch.keys.stale(message, nil) {
+			return 0, ErrStaleSequence
+		}
+	}
+
+	// Hide the delayed message until it is due, it is written to the metadata of the channel meanwhile
+	if isDelayed(message, time.Now().UnixMilli()) {
+		if err := ch.writeMeta(&pb.WalEntry{
+			Type:    pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE,
+			Message: message,
+		}); err != nil {
+			slog.Error(
+				"failed to write channel metadata",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+
+			return 0, ErrInternal
+		}
+
+		if d.deduplicates(message) {
+			ch.keys.remember(message)
+		}
+		ch.delay(message)
+		d.scheduleDelayed()
+		return message.GetOffset(), nil
+	}
+
+	// Append the message to the log of the channel, at the next offset of the channel
+	message.Offset = ch.log.next()
+	if err := d.appendMessages(ch, []*pb.Message{message}, d.sync || syncsPublishes(config)); err != nil {
+		slog.Error(
+			"failed to append to channel log",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+
+		return 0, ErrInternal
+	}
+
+	if d.deduplicates(message) {
+		ch.keys.remember(message)
+	}
+
+	// Notify the subscribers waiting for new messages in the channel
+	ch.broadcast()
+
+	// Return the offset of the message in the channel
+	return message.GetOffset(), nil
+}
+
+// appendMessages appends the messages to the log of the channel, syncing it if sync is set
+func (d *DiskStorage) appendMessages(ch *diskChannel, messages []*pb.Message, sync bool) error {
+	mark := ch.log.mark()
+	if err := ch.log.append(messages); err != nil {
+		return err
+	}
+
+	if sync {
+		if err := ch.log.sync(); err != nil {
+			_ = ch.log.truncate(mark)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveMessages saves messages to their channels, which must exist, either all the messages are saved or none is. The messages
+// are assigned their offsets, which are returned in order. Duplicates are deduplicated, stale messages of producers
+// refused and delayed messages hidden until they are due, like in the MemoryStorage.
+func (d *DiskStorage) SaveMessages(entries []*pb.WalEntry) ([]uint64, error) {
+	// The batch may span several channels, so it is written under the write lock of the storage
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Nothing is saved if any of the channels does not exist, channels are only created explicitly
+	syncs := make(map[string]bool)
+	for _, entry := range entries {
+		if _, exists := d.data[entry.GetChannel()]; !exists {
+			return nil, fmt.Errorf("channel '%s' does not exist", entry.GetChannel())
+		}
+		_, config := d.configOf(entry.GetChannel())
+		syncs[entry.GetChannel()] = d.sync || syncsPublishes(config)
+	}
+
+	// Assign the messages the next offsets of their channels, and group them by channel
+	messages := make(map[string][]*pb.Message)
+	delayed := make(map[string][]*pb.WalEntry)
+	channels := make([]string, 0)
+	offsets := make([]uint64, 0, len(entries))
+	batchKeys := make(map[string]map[string]*pb.Message)
+	batchSequences := make(map[string]map[string]uint64)
+	now := time.Now().UnixMilli()
+	for _, entry := range entries {
+		channel := entry.GetChannel()
+		message := entry.GetMessage()
+		ch := d.data[channel]
+
+		// Return the offset of the original message, if the message is a duplicate, nothing is saved
+		// if a message that is not is stale
+		if d.deduplicates(message) {
+			if _, exists := batchKeys[channel]; !exists {
+				batchKeys[channel] = make(map[string]*pb.Message)
+				batchSequences[channel] = make(map[string]uint64)
+			}
+			original := batchKeys[channel][message.GetIdempotencyKey()]
+			if original == nil {
+				original = ch.keys.duplicateOf(message, d.dedupWindow)
+			}
+			if original != nil {
+				copyDuplicate(message, original)
+				offsets = append(offsets, message.GetOffset())
+				continue
+			}
+			if ch.keys.stale(message, batchSequences[channel]) {
+				return nil, ErrStaleSequence
+			}
+			batchKeys[channel][message.GetIdempotencyKey()] = message
+		}
+
+		if _, exists := messages[channel]; !exists {
+			messages[channel] = make([]*pb.Message, 0)
+			channels = append(channels, channel)
+		}
+
+		if isDelayed(message, now) {
+			delayed[channel] = append(delayed[channel], &pb.WalEntry{
+				Type:    pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE,
+				Message: message,
+			})
+		} else {
+			message.Offset = ch.log.next() + uint64(len(messages[channel]))
+			messages[channel] = append(messages[channel], message)
+		}
+		offsets = append(offsets, message.GetOffset())
+	}
+
+	// Write the messages to their channels, the channels written so far are truncated back if a write fails
+	marks := make(map[string]logMark)
+	metaSizes := make(map[string]int64)
+	rollback := func() {
+		for channel, mark := range marks {
+			_ = d.data[channel].log.truncate(mark)
+		}
+		for channel, size := range metaSizes {
+			ch := d.data[channel]
+			_ = ch.meta.Truncate(size)
+			ch.metaEntries -= uint64(len(delayed[channel]))
+			ch.metaSize = size
+		}
+	}
+	for _, channel := range channels {
+		ch := d.data[channel]
+		marks[channel] = ch.log.mark()
+		if err := ch.log.append(messages[channel]); err != nil {
+			slog.Error(
+				"failed to append batch to channel log",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+
+			rollback()
+			return nil, ErrInternal
+		}
+
+		if len(delayed[channel]) == 0 {
+			continue
+		}
+		size := ch.metaSize
+		if err := ch.writeMeta(delayed[channel]...); err != nil {
+			slog.Error(
+				"failed to write channel metadata",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+
+			rollback()
+			return nil, ErrInternal
+		}
+		metaSizes[channel] = size
+	}
+
+	// Sync the channels if the storage syncs its writes, or if they are synced on every publish
+	for _, channel := range channels {
+		if !syncs[channel] {
+			continue
+		}
+		if err := d.data[channel].log.sync(); err != nil {
+			slog.Error(
+				"failed to sync channel log",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+
+			rollback()
+			return nil, ErrInternal
+		}
+	}
+
+	// Remember the idempotency keys of the messages saved, the delayed messages are hidden until they are due
+	for _, channel := range channels {
+		ch := d.data[channel]
+		for _, message := range messages[channel] {
+			if d.deduplicates(message) {
+				ch.keys.remember(message)
+			}
+		}
+		for _, entry := range delayed[channel] {
+			if d.deduplicates(entry.GetMessage()) {
+				ch.keys.remember(entry.GetMessage())
+			}
+			ch.delay(entry.GetMessage())
+			d.scheduleDelayed()
+		}
+
+		// Notify the subscribers waiting for new messages in the channel
+		if len(messages[channel]) > 0 {
+			ch.broadcast()
+		}
+	}
+
+	return offsets, nil
+}
+
+// GetMessages retrieves the next batch of messages from the specified channel for the subscriber, the messages
+// are read from the log of the channel, a batch holds at most the batch size of the channel, or else of the storage
+func (d *DiskStorage) GetMessages(
+	channel string,
+	subscriberID string,
+	offset uint64,
+) ([]*pb.Message, uint64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		slog.Error(
+			"channel does not exist",
+			slog.String("channel", channel),
+		)
+		return nil, 0, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	_, config := d.configOf(channel)
+	batchSize := batchSizeOf(config, d.batchSize)
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	// The subscriber has fallen behind the retention of the channel, it is told so once
+	if _, lagging := ch.lagging[subscriberID]; lagging {
+		delete(ch.lagging, subscriberID)
+		return []*pb.Message(nil), 0, ErrOffsetOutOfRange
+	}
+
+	// Get the next offset the subscriber reads, the offset is only used to position
+	// a subscriber (or a consumer group) reading the channel for the first time
+	next, hasCursor := ch.cursors[subscriberID]
+	switch {
+	case hasCursor:
+		// Resume right after the last message read
+	case offset == OffsetLatest:
+		// Move the cursor past the latest message
+		ch.cursors[subscriberID] = ch.log.next()
+		return []*pb.Message(nil), ch.log.next() - 1, nil
+	case offset >= ch.log.next():
+		return []*pb.Message(nil), 0, ErrInvalidOffset
+	case offset > OffsetBeginning && offset < ch.log.retainedFrom():
+		// The messages from the offset on have been deleted
+		return []*pb.Message(nil), 0, ErrOffsetOutOfRange
+	default:
+		next = offset
+	}
+
+	// Read the messages from the log, limiting the number of messages to be returned,
+	// the expired messages are skipped
+	now := time.Now().UnixMilli()
+	data := make([]*pb.Message, 0)
+	read := false
+	last := next
+	err := ch.log.read(next, func(message *pb.Message) bool {
+		if uint64(len(data)) >= batchSize {
+			return false
+		}
+		if !isExpired(message, now) {
+			data = append(data, message)
+		}
+		last = message.GetOffset()
+		read = true
+		return true
+	})
+	if err != nil {
+		slog.Error(
+			"failed to read channel log",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return nil, 0, ErrInternal
+	}
+
+	// Nothing new to read
+	if !read {
+		return []*pb.Message(nil), 0, ErrInvalidOffset
+	}
+
+	// Move the cursor past the last message read
+	ch.cursors[subscriberID] = last + 1
+
+	// Nothing new to read, every message left has expired
+	if len(data) == 0 {
+		return []*pb.Message(nil), 0, ErrInvalidOffset
+	}
+
+	// Return the messages and the offset of the last message read
+	return data, last, nil
+}
+
+// CreateChannel creates a new channel, an existing channel is left untouched
+func (d *DiskStorage) CreateChannel(channel string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.data[channel]; exists {
+		return nil
+	}
+
+	ch, err := d.openChannel(channel)
+	if err != nil {
+		slog.Error(
+			"failed to create channel",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+	d.data[channel] = ch
+
+	// Notify everyone waiting for new channels
+	close(d.channelsNotify)
+	d.channelsNotify = make(chan struct{})
+
+	return nil
+}
+
+// DeleteChannel deletes the specified channel along with its partitions, their logs, metadata and cursors. The partitions
+// are deleted before the channel, the directory of each is renamed before it is removed so that a channel deleted only
+// in part is not opened again.
+func (d *DiskStorage) DeleteChannel(channel string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	names := make([]string, 0)
+	for p := ch.config.GetPartitions(); p > 1; p-- {
+		names = append(names, PartitionChannel(channel, p-1))
+	}
+	names = append(names, channel)
+
+	for _, name := range names {
+		ch, exists := d.data[name]
+		if !exists {
+			continue
+		}
+
+		// Wake up the subscribers waiting for new messages, so that they find out the channel is gone
+		ch.close()
+		ch.broadcast()
+		delete(d.data, name)
+
+		err := os.Rename(ch.dir, ch.dir+deletedDirExt)
+		if err == nil {
+			err = os.RemoveAll(ch.dir + deletedDirExt)
+		}
+		if err != nil {
+			slog.Error(
+				"failed to delete channel",
+				slog.String("channel", name),
+				slog.Any("error", err),
+			)
+
+			return ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// PurgeChannel deletes the messages of the specified channel and of its partitions, including the delayed messages
+// that are not due yet, the channel keeps its configuration and the offsets of the messages deleted are not given out again
+func (d *DiskStorage) PurgeChannel(channel string) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	partitions := ch.config.GetPartitions()
+	ch.mu.Unlock()
+
+	for p := uint32(0); p < max(partitions, 1); p++ {
+		name := PartitionChannel(channel, p)
+		ch, exists := d.data[name]
+		if !exists {
+			continue
+		}
+
+		ch.mu.Lock()
+		removed, err := ch.purge()
+		ch.broadcast()
+		ch.mu.Unlock()
+
+		if err != nil {
+			slog.Error(
+				"failed to purge channel",
+				slog.String("channel", name),
+				slog.Any("error", err),
+			)
+
+			return ErrInternal
+		}
+
+		slog.Info(
+			"channel purged",
+			slog.String("channel", name),
+			slog.Uint64("count", removed),
+		)
+	}
+
+	return nil
+}
+
+// purge deletes the messages of the channel and its delayed messages, and returns how many messages were deleted.
+// The cursors and the committed offsets are moved past the messages deleted, so that no subscriber is told it has
+// fallen behind, the metadata is rewritten first so that the delayed messages are not brought back.
+func (ch *diskChannel) purge() (uint64, error) {
+	next, count := ch.log.next(), ch.log.count()
+
+	ch.delayed = make(delayedQueue, 0)
+	clear(ch.lagging)
+	for subscriberID, offset := range ch.committed {
+		ch.committed[subscriberID] = max(offset, next)
+	}
+	for subscriberID, offset := range ch.cursors {
+		ch.cursors[subscriberID] = max(offset, next)
+	}
+
+	if err := ch.replaceMeta(ch.liveMeta()); err != nil {
+		return 0, err
+	}
+	if err := ch.log.purge(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetChannels returns the names of all the channels, in lexical order, the partitions of the channels are left out
+func (d *DiskStorage) GetChannels() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	channels := make([]string, 0, len(d.data))
+	for channel := range d.data {
+		if _, partition := ParsePartitionChannel(channel); partition != 0 {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	return channels
+}
+
+// WatchChannels returns a channel that is closed when the next channel is created
+func (d *DiskStorage) WatchChannels() <-chan struct{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.channelsNotify
+}
+
+// WatchChannel returns a channel that is closed when the next message is saved to the specified channel
+func (d *DiskStorage) WatchChannel(channel string) (<-chan struct{}, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return nil, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.notify, nil
+}
+
+// SetChannelConfig sets the configuration of the specified channel, the configuration is written to the metadata of the channel
+func (d *DiskStorage) SetChannelConfig(
+	channel string,
+	config *pb.ChannelConfig,
+) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if err := ch.writeMeta(&pb.WalEntry{
+		Type:   pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG,
+		Config: config,
+	}); err != nil {
+		slog.Error(
+			"failed to write channel metadata",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	ch.config = config
+	return nil
+}
+
+// GetChannelConfig returns the configuration of the specified channel
+func (d *DiskStorage) GetChannelConfig(channel string) (*pb.ChannelConfig, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return nil, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.config, nil
+}
+
+// GetChannelStats returns the number, the offsets, the timestamps and the size of the messages of the specified channel
+func (d *DiskStorage) GetChannelStats(channel string) (*pb.ChannelStats, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return nil, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	first, last, err := ch.log.ends()
+	if err != nil {
+		slog.Error(
+			"failed to read channel log",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return nil, ErrInternal
+	}
+
+	return &pb.ChannelStats{
+		MessageCount:        ch.log.count(),
+		FirstOffset:         first.GetOffset(),
+		LastOffset:          last.GetOffset(),
+		FirstCreatedAt:      first.GetCreatedAt(),
+		LastCreatedAt:       last.GetCreatedAt(),
+		NextOffset:          ch.log.next(),
+		SizeBytes:           ch.log.bytes(),
+		DelayedMessageCount: uint64(len(ch.delayed)),
+	}, nil
+}
+
+// GetOffsetByTime returns the offset of the first message of the specified channel created at or after
+// the timestamp, or the offset of the next message to be saved if there is none
+func (d *DiskStorage) GetOffsetByTime(channel string, timestamp int64) (uint64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return 0, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	offset, err := ch.log.offsetAtTime(timestamp)
+	if err != nil {
+		slog.Error(
+			"failed to read channel log",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return 0, ErrInternal
+	}
+
+	return offset, nil
+}
+
+// ChannelExists checks if a channel exists
+func (d *DiskStorage) ChannelExists(channel string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	_, exists := d.data[channel]
+	return exists
+}
+
+// CommitOffset writes the offset a durable subscription resumes the channel from to the metadata of the channel,
+// the subscription is positioned on it when the channel is opened
+func (d *DiskStorage) CommitOffset(
+	channel string,
+	subscriberID string,
+	offset uint64,
+) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if err := ch.writeMeta(&pb.WalEntry{
+		Type:         pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET,
+		Subscription: subscriberID,
+		Offset:       offset,
+	}); err != nil {
+		slog.Error(
+			"failed to write channel metadata",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	ch.committed[subscriberID] = offset
+	return nil
+}
+
+// RemoveChannelFromSubscriberMap removes the cursor of the subscriber from the channel
+func (d *DiskStorage) RemoveChannelFromSubscriberMap(
+	channel string,
+	subscriberID string,
+) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ch, exists := d.data[channel]
+	if !exists {
+		return
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	delete(ch.cursors, subscriberID)
+	delete(ch.lagging, subscriberID)
+}
@@ -0,0 +1,138 @@
+// pkg/storage/disk_storage_test.go
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+func openDiskStorage(t *testing.T, dir string) *DiskStorage {
+	d, err := NewDiskStorage(&DiskStorageOptions{
+		DirPath:       dir,
+		SegmentSize:   256,
+		Sync:          false,
+		BatchSize:     100,
+		DedupWindow:   0,
+		SweepInterval: 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDiskStorageTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	d := openDiskStorage(t, dir)
+	assert.NoError(t, d.CreateChannel("channel"))
+	for _, content := range []string{"a", "b", "c"} {
+		_, err := d.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	d.Close()
+
+	// Cut the last record of the log in half
+	name := segmentName(channelDir(dir, "channel"), 0, segmentFileExt)
+	info, err := os.Stat(name)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(name, info.Size()-3))
+
+	d = openDiskStorage(t, dir)
+	defer d.Close()
+
+	messages, _, err := d.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, contentsOf(messages))
+
+	offset, err := d.SaveMessage("channel", newMessage("d"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), offset)
+}
+
+func TestDiskStorageRetention(t *testing.T) {
+	d := openDiskStorage(t, t.TempDir())
+	defer d.Close()
+
+	assert.NoError(t, d.CreateChannel("channel"))
+	config := &pb.ChannelConfig{RetentionMessages: 4}
+	assert.NoError(t, d.SetChannelConfig("channel", config))
+
+	// The subscriber reads the first message only
+	content := string(make([]byte, 100))
+	_, err := d.SaveMessage("channel", newMessage(content))
+	assert.NoError(t, err)
+	_, _, err = d.GetMessages("channel", "behind", OffsetBeginning)
+	assert.NoError(t, err)
+
+	for i := 1; i < 10; i++ {
+		_, err := d.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+
+	ch := d.data["channel"]
+	evicted, lagging := ch.evict(config, time.Now().UnixMilli())
+	assert.True(t, evicted > 0)
+	assert.True(t, lagging)
+
+	// The segments are deleted as long as the retention is kept without them
+	assert.True(t, ch.log.count() >= 4)
+	assert.Equal(t, uint64(10)-evicted, ch.log.count())
+	assert.Equal(t, evicted, ch.log.retainedFrom())
+
+	_, _, err = d.GetMessages("channel", "behind", OffsetBeginning)
+	assert.Equal(t, ErrOffsetOutOfRange, err)
+	_, _, err = d.GetMessages("channel", "other", 1)
+	assert.Equal(t, ErrOffsetOutOfRange, err)
+}
+
+func TestDiskStorageCompaction(t *testing.T) {
+	dir := t.TempDir()
+	d := openDiskStorage(t, dir)
+
+	assert.NoError(t, d.CreateChannel("channel"))
+	config := &pb.ChannelConfig{Compacted: true}
+	assert.NoError(t, d.SetChannelConfig("channel", config))
+
+	for _, kv := range [][2]string{{"a", "1"}, {"b", "1"}, {"a", "2"}, {"b", ""}, {"a", "3"}, {"c", "1"}, {"a", "4"}, {"c", "2"}} {
+		message := newMessage(kv[1])
+		message.Key = kv[0]
+		if kv[1] != "" {
+			message.Content = append(message.Content, make([]byte, 64)...)
+		}
+		_, err := d.SaveMessage("channel", message)
+		assert.NoError(t, err)
+	}
+
+	ch := d.data["channel"]
+	assert.True(t, len(ch.log.segments) > 1)
+	compacted, err := ch.compact(config)
+	assert.NoError(t, err)
+	assert.True(t, compacted > 0)
+	d.Close()
+
+	// The compaction is kept across restarts, the offsets of the messages kept are unchanged
+	d = openDiskStorage(t, dir)
+	defer d.Close()
+
+	messages, _, err := d.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	for _, message := range messages {
+		assert.NotEqual(t, "b", message.GetKey())
+	}
+	assert.Equal(t, uint64(7), messages[len(messages)-1].GetOffset())
+	assert.Equal(t, "c", messages[len(messages)-1].GetKey())
+
+	offset, err := d.SaveMessage("channel", &pb.Message{Key: "a", Content: []byte("5")})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), offset)
+
+	_, err = os.Stat(filepath.Join(channelDir(dir, "channel"), metaFileName))
+	assert.NoError(t, err)
+}
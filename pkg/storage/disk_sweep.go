@@ -0,0 +1,216 @@
+// pkg/storage/disk_sweep.go
+
+package storage
+
+import (
+	"log/slog"
+	"time"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// sweep deletes the segments past retention, compacts the compacted channels and rewrites the metadata
+// of the channels left with mostly superseded entries every sweep interval, until the storage is closed
+func (d *DiskStorage) sweep() {
+	ticker := time.NewTicker(d.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.closed:
+			return
+		}
+
+		now := time.Now().UnixMilli()
+		d.mu.RLock()
+		for channel, ch := range d.data {
+			name, config := d.configOf(channel)
+
+			ch.mu.Lock()
+			evicted, lagging := ch.evict(config, now)
+			compacted, err := ch.compact(config)
+			if err == nil {
+				err = ch.rewriteMeta()
+			}
+
+			// Wake up the subscribers that have fallen behind, so that they are told so
+			if lagging {
+				ch.broadcast()
+			}
+			ch.mu.Unlock()
+
+			if err != nil {
+				slog.Error(
+					"failed to sweep channel",
+					slog.String("channel", channel),
+					slog.Any("error", err),
+				)
+			}
+
+			if evicted > 0 {
+				evictedMessages.Add(name, int64(evicted))
+				slog.Info(
+					"segments past retention deleted",
+					slog.String("channel", channel),
+					slog.Uint64("count", evicted),
+				)
+			}
+
+			if compacted > 0 {
+				compactedMessages.Add(name, int64(compacted))
+				slog.Info(
+					"channel compacted",
+					slog.String("channel", channel),
+					slog.Uint64("count", compacted),
+				)
+			}
+		}
+		d.mu.RUnlock()
+	}
+}
+
+// configOf returns the channel a log belongs to and the configuration of the channel, the partitions
+// of a channel share the configuration of the channel. The caller must hold the read lock of the storage.
+func (d *DiskStorage) configOf(name string) (string, *pb.ChannelConfig) {
+	channel, _ := ParsePartitionChannel(name)
+
+	ch := d.data[channel]
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return channel, ch.config
+}
+
+// evict deletes the oldest segments of the log as long as the log is past the retention of the channel without
+// them, and returns how many messages were deleted and whether a subscriber has fallen behind. A subscriber falls
+// behind when the next message it had to read is deleted, its cursor is removed and it is told so on its next read.
+func (ch *diskChannel) evict(config *pb.ChannelConfig, now int64) (uint64, bool) {
+	if !hasRetention(config) {
+		return 0, false
+	}
+
+	count, bytes := ch.log.count(), ch.log.bytes()
+
+	// The active segment is never deleted
+	var removed, deletedUpTo uint64
+	i := 0
+	for ; i < len(ch.log.segments)-1; i++ {
+		seg := ch.log.segments[i]
+
+		var past bool
+		switch {
+		case config.GetRetentionMessages() > 0 && count-seg.count >= config.GetRetentionMessages():
+			past = true
+		case config.GetRetentionBytes() > 0 && bytes-uint64(seg.size) >= config.GetRetentionBytes():
+			past = true
+		case config.GetRetentionMs() > 0:
+			past = seg.latest*1000+int64(config.GetRetentionMs()) <= now
+		}
+		if !past {
+			break
+		}
+
+		count -= seg.count
+		bytes -= uint64(seg.size)
+		removed += seg.count
+		if seg.count > 0 {
+			deletedUpTo = seg.next
+		}
+	}
+
+	if i == 0 {
+		return 0, false
+	}
+
+	if err := ch.log.deleteSegments(i); err != nil {
+		slog.Error(
+			"failed to delete segment",
+			slog.String("channel", ch.dir),
+			slog.Any("error", err),
+		)
+	}
+
+	lagging := false
+	for subscriberID, next := range ch.cursors {
+		if next < deletedUpTo {
+			delete(ch.cursors, subscriberID)
+			ch.lagging[subscriberID] = struct{}{}
+			lagging = true
+		}
+	}
+
+	return removed, lagging
+}
+
+// compact rewrites the segments of the log holding obsolete messages, once there are enough of them, and returns
+// how many messages were removed. A message is obsolete once a later message has the same key, or once it is
+// a tombstone every subscriber has read. The active segment is never compacted, and a compacted segment is kept
+// even when left empty so that the offsets it spans are not taken for deleted ones.
+func (ch *diskChannel) compact(config *pb.ChannelConfig) (uint64, error) {
+	if !config.GetCompacted() || len(ch.log.segments) < 2 || ch.log.next() == ch.compactedAt {
+		return 0, nil
+	}
+	ch.compactedAt = ch.log.next()
+
+	latest := make(map[string]uint64)
+	if err := ch.log.read(0, func(message *pb.Message) bool {
+		latest[message.GetKey()] = message.GetOffset()
+		return true
+	}); err != nil {
+		return 0, err
+	}
+
+	isObsolete := func(message *pb.Message) bool {
+		if latest[message.GetKey()] != message.GetOffset() {
+			return true
+		}
+		if !isTombstone(message) {
+			return false
+		}
+
+		for _, next := range ch.cursors {
+			if next <= message.GetOffset() {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Count the obsolete messages of the segments that are not active
+	closed := ch.log.segments[:len(ch.log.segments)-1]
+	obsolete := make([]uint64, len(closed))
+	var total uint64
+	for i, seg := range closed {
+		if _, err := seg.read(0, func(message *pb.Message) bool {
+			if isObsolete(message) {
+				obsolete[i]++
+			}
+			return true
+		}); err != nil {
+			return 0, err
+		}
+		total += obsolete[i]
+	}
+
+	if total == 0 || float64(total) < minObsoleteRatio*float64(ch.log.count()) {
+		return 0, nil
+	}
+
+	var removed uint64
+	for i := range closed {
+		if obsolete[i] == 0 {
+			continue
+		}
+
+		n, err := ch.log.rewrite(i, func(message *pb.Message) bool {
+			return !isObsolete(message)
+		})
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
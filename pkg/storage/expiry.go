@@ -0,0 +1,175 @@
+// pkg/storage/expiry.go
+
+package storage
+
+import (
+	"expvar"
+	"log/slog"
+	"time"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// expiredMessages counts the expired messages dropped from memory, by channel
+var expiredMessages = expvar.NewMap("mq_expired_messages")
+
+// isExpired reports whether the message has expired, now is in milliseconds since the epoch
+func isExpired(message *pb.Message, now int64) bool {
+	return message.GetExpiresAt() > 0 && message.GetExpiresAt() <= now
+}
+
+// dropExpired removes the expired chunks from the head of the list and returns how many were removed, the chunks
+// are in the order they were saved so the walk stops at the first chunk that has not expired. The expired chunks
+// behind it are skipped by the readers until the chunks ahead of them are dropped too.
+func (cl *chunkList) dropExpired(now int64) uint64 {
+	removed := cl.removeHead(func(chunk *chunk) bool {
+		return isExpired(chunk.data, now)
+	})
+
+	cl.expired += removed
+	return removed
+}
+
+// removeChunks removes the chunks matching drop from the list and returns how many were removed. The cursors
+// on a removed chunk are moved back to the last chunk before it, so that they resume right after it,
+// and the sparse index is rebuilt from the first remaining chunk of every interval.
+func (cl *chunkList) removeChunks(drop func(*chunk) bool) uint64 {
+	removed := make(map[*chunk]struct{})
+	cl.index = cl.index[:0]
+	for iterator := cl.head; iterator != nil; iterator = iterator.next {
+		if drop(iterator) {
+			cl.unlink(iterator)
+			removed[iterator] = struct{}{}
+			continue
+		}
+
+		if len(cl.index) == 0 || cl.index[len(cl.index)-1].offset/indexInterval != iterator.offset/indexInterval {
+			cl.index = append(cl.index, iterator)
+		}
+	}
+
+	if len(removed) == 0 {
+		return 0
+	}
+
+	for subscriberID, lastChunk := range cl.cursors {
+		for lastChunk != nil {
+			if _, exists := removed[lastChunk]; !exists {
+				break
+			}
+			lastChunk = lastChunk.prev
+		}
+		cl.cursors[subscriberID] = lastChunk
+	}
+
+	return uint64(len(removed))
+}
+
+// removeHead removes the chunks from the head of the list for as long as drop matches them and returns how many
+// were removed. The cursors on a removed chunk are moved back to the start of the list, and the sparse index
+// entries of the removed chunks are replaced by the first remaining chunk of their interval.
+func (cl *chunkList) removeHead(drop func(*chunk) bool) uint64 {
+	var removed uint64
+	for cl.head != nil && drop(cl.head) {
+		cl.unlink(cl.head)
+		removed++
+	}
+
+	if removed == 0 {
+		return 0
+	}
+
+	first := cl.head
+	for subscriberID, lastChunk := range cl.cursors {
+		if lastChunk != nil && (first == nil || lastChunk.offset < first.offset) {
+			cl.cursors[subscriberID] = nil
+		}
+	}
+
+	kept := 0
+	for kept < len(cl.index) && (first == nil || cl.index[kept].offset < first.offset) {
+		kept++
+	}
+	cl.index = cl.index[kept:]
+	if first != nil && (len(cl.index) == 0 || cl.index[0].offset/indexInterval != first.offset/indexInterval) {
+		cl.index = append([]*chunk{first}, cl.index...)
+	}
+
+	return removed
+}
+
+// unlink removes the chunk from the list, the chunk keeps pointing to its neighbours
+// so that a reader positioned on it still moves on to the rest of the list
+func (cl *chunkList) unlink(chunk *chunk) {
+	cl.count--
+	cl.bytes -= chunk.size
+
+	if chunk.prev != nil {
+		chunk.prev.next = chunk.next
+	} else {
+		cl.head = chunk.next
+	}
+
+	if chunk.next != nil {
+		chunk.next.prev = chunk.prev
+	} else {
+		cl.tail = chunk.prev
+	}
+}
+
+// scheduleSweep makes sure the expired messages and the messages past retention are dropped from memory, and the
+// compacted channels compacted, it is called whenever a message with an expiry is saved or a channel is configured so
+func (m *MemoryStorage) scheduleSweep() {
+	if m.sweepInterval <= 0 {
+		return
+	}
+
+	m.sweepOnce.Do(func() {
+		m.runInBackground(m.sweep)
+	})
+}
+
+// sweep drops the expired messages and the messages past retention from memory every sweep interval, compacts
+// the compacted channels and deletes the WAL segments left with nothing to replay, until the storage is closed
+func (m *MemoryStorage) sweep() {
+	ticker := time.NewTicker(m.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UnixMilli()
+		m.dropExpired(now)
+		m.evict(now)
+		m.compact()
+		m.deleteSegments()
+
+		select {
+		case <-ticker.C:
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+// dropExpired drops the expired messages of every channel from memory
+func (m *MemoryStorage) dropExpired(now int64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for channel, msgList := range m.data {
+		msgList.mu.Lock()
+		dropped := msgList.dropExpired(now)
+		msgList.mu.Unlock()
+
+		if dropped == 0 {
+			continue
+		}
+
+		name, _ := ParsePartitionChannel(channel)
+		expiredMessages.Add(name, int64(dropped))
+		slog.Info(
+			"expired messages dropped",
+			slog.String("channel", channel),
+			slog.Uint64("count", dropped),
+		)
+	}
+}
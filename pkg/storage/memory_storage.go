@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/rosedblabs/wal"
 	"google.golang.org/protobuf/proto"
@@ -15,151 +16,599 @@ import (
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
 )
 
-// chunk represents a chunk of data
+// indexInterval is the number of chunks between two entries of the sparse index of a chunk list
+const indexInterval = 64
+
+// chunk represents a chunk of data, along with its size and the WAL segment it was written to
 type chunk struct {
-	data *pb.Message
-	prev *chunk
-	next *chunk
+	data    *pb.Message
+	offset  uint64
+	size    uint64
+	segment wal.SegmentID
+	prev    *chunk
+	next    *chunk
 }
 
-// chunkList represents a linked list of chunks
+// chunkList represents a linked list of chunks, along with a sparse index of every
+// indexInterval-th chunk so that a chunk can be looked up without walking the whole list,
+// the messages published with an idempotency key within the deduplication window, the
+// delayed messages that are not due yet, the last chunk read by each subscriber and the offset committed
+// by each durable subscription. A chunk list holds a channel, or a partition of a channel, and has a lock
+// of its own so that channels and partitions are written in parallel.
+//
+// The len of the list is the offset of the next chunk, while count and bytes are the number and the size
+// of the chunks still in the list, the chunks before retainedFrom having been evicted by the retention of the channel.
 type chunkList struct {
-	head *chunk
-	tail *chunk
-	len  uint64
+	mu           sync.Mutex
+	head         *chunk
+	tail         *chunk
+	len          uint64
+	count        uint64
+	bytes        uint64
+	retainedFrom uint64
+	index        []*chunk
+	notify       chan struct{}
+	config       *pb.ChannelConfig
+	keys         *idempotencyKeys
+	delayed      delayedQueue
+	delayedSeq   uint64
+	expired      uint64
+	evicted      uint64
+	cursors      map[string]*chunk
+	lagging      map[string]struct{}
+	committed    map[string]uint64
 }
 
 // appendChunk appends a chunk to the chunk list
 func (cl *chunkList) appendChunk(chunk *chunk) {
+	// The offset of the chunk is its position in the list
+	chunk.offset = cl.len
+	chunk.size = uint64(proto.Size(chunk.data))
+
 	// Append the message to the list
 	if cl.head == nil {
 		cl.head = chunk
 		cl.tail = chunk
-		cl.len = 1
 	} else {
 		chunk.prev = cl.tail
 		cl.tail.next = chunk
 		cl.tail = cl.tail.next
-		cl.len++
 	}
+	cl.len++
+	cl.count++
+	cl.bytes += chunk.size
+
+	// Index the chunk, if it is the first one of its interval
+	if len(cl.index) == 0 || cl.index[len(cl.index)-1].offset/indexInterval != chunk.offset/indexInterval {
+		cl.index = append(cl.index, chunk)
+	}
+}
+
+// chunkAt returns the chunk at the specified offset (or the first chunk after it, if it was dropped), or nil if there is none
+func (cl *chunkList) chunkAt(offset uint64) *chunk {
+	// Find the last indexed chunk at or before the offset
+	i := sort.Search(len(cl.index), func(i int) bool {
+		return cl.index[i].offset > offset
+	}) - 1
+
+	// Every chunk is past the offset, the chunks before it have expired
+	if i < 0 {
+		return cl.head
+	}
+
+	// Walk the rest of the way from the indexed chunk
+	iterator := cl.index[i]
+	for iterator != nil && iterator.offset < offset {
+		iterator = iterator.next
+	}
+
+	return iterator
+}
+
+// chunkAtTime returns the first chunk created (or due, for a delayed message) at or after the specified timestamp,
+// or nil if there is none
+func (cl *chunkList) chunkAtTime(timestamp int64) *chunk {
+	// Find the last indexed chunk created before the timestamp
+	i := sort.Search(len(cl.index), func(i int) bool {
+		return visibleAt(cl.index[i].data) >= timestamp
+	}) - 1
+
+	// Every chunk was created at or after the timestamp
+	if i < 0 {
+		return cl.head
+	}
+
+	// Walk the rest of the way from the indexed chunk
+	iterator := cl.index[i]
+	for iterator != nil && visibleAt(iterator.data) < timestamp {
+		iterator = iterator.next
+	}
+
+	return iterator
+}
+
+// broadcast wakes up everyone waiting for new chunks in the list
+func (cl *chunkList) broadcast() {
+	close(cl.notify)
+	cl.notify = make(chan struct{})
+}
+
+// purge removes the chunks before the next offset and the delayed messages from the list, and returns how many chunks
+// were removed. The offsets of the removed chunks are not given out again, the cursors are moved to the start of the
+// list and the committed offsets past the removed chunks, so that no subscriber is told it has fallen behind.
+func (cl *chunkList) purge(next uint64) uint64 {
+	removed := cl.removeChunks(func(chunk *chunk) bool {
+		return chunk.offset < next
+	})
+
+	cl.len = max(cl.len, next)
+	cl.retainedFrom = max(cl.retainedFrom, next)
+	cl.delayed = make(delayedQueue, 0)
+	clear(cl.lagging)
+	for subscriberID, offset := range cl.committed {
+		cl.committed[subscriberID] = max(offset, next)
+	}
+
+	return removed
 }
 
-// MemoryStorageOptions represents the options for the MemoryStorage
+// MemoryStorageOptions represents the options for the MemoryStorage, the directory and the extension
+// of the WAL segment files are required for the segments past retention to be deleted, and the
+// snapshot directory for snapshots to be taken every snapshot interval. The recovery mode of the WAL
+// decides whether the damaged records found while replaying it are skipped, the default, or refused.
 type MemoryStorageOptions struct {
-	Wal           *wal.WAL
-	BatchSize     uint64
-	SyncOnStartup bool
+	Wal               *wal.WAL
+	WalDirPath        string
+	WalSegmentFileExt string
+	WalSync           bool
+	WalRecoveryMode   WalRecoveryMode
+	BatchSize         uint64
+	SyncOnStartup     bool
+	DedupWindow       time.Duration
+	SweepInterval     time.Duration
+	SnapshotDirPath   string
+	SnapshotInterval  time.Duration
 }
 
-// MemoryStorage is an in-memory implementation of the Storage interface
+// MemoryStorage is an in-memory implementation of the Storage interface. The lock of the storage
+// guards the set of channels, a channel (or a partition) is read and written under the read lock
+// of the storage and the lock of its chunk list, while batches spanning several channels are
+// written under the write lock of the storage.
 type MemoryStorage struct {
-	mu                       sync.RWMutex
-	wal                      *wal.WAL
-	batchSize                uint64
-	data                     map[string]*chunkList
-	subscriberToChannelChunk map[string]map[string]*chunk
+	mu                sync.RWMutex
+	wal               *wal.WAL
+	walDirPath        string
+	walSegmentFileExt string
+	firstSegment      wal.SegmentID
+	replayed          bool
+	walSync           bool
+	batchSize         uint64
+	dedupWindow       int64
+	data              map[string]*chunkList
+	channelsNotify    chan struct{}
+	delayedNotify     chan struct{}
+	delayedOnce       sync.Once
+	sweepInterval     time.Duration
+	sweepOnce         sync.Once
+	snapshotDirPath   string
+	snapshotInterval  time.Duration
+	closeMu           sync.Mutex
+	closed            chan struct{}
+	background        sync.WaitGroup
 }
 
-// NewMemoryStorage initializes a new MemoryStorage instance
+// NewMemoryStorage initializes a new MemoryStorage instance, replaying the WAL if it syncs on startup. It fails if the WAL
+// cannot be read, or if it has damaged records left and the recovery mode refuses them, RecoverWal having truncated or
+// skipped the ones it found before the WAL was opened otherwise.
 func NewMemoryStorage(
 	options *MemoryStorageOptions,
-) *MemoryStorage {
+) (*MemoryStorage, error) {
 	m := &MemoryStorage{
-		mu:                       sync.RWMutex{},
-		wal:                      options.Wal,
-		batchSize:                options.BatchSize,
-		data:                     make(map[string]*chunkList),
-		subscriberToChannelChunk: make(map[string]map[string]*chunk),
+		mu:                sync.RWMutex{},
+		wal:               options.Wal,
+		walDirPath:        options.WalDirPath,
+		walSegmentFileExt: options.WalSegmentFileExt,
+		firstSegment:      1,
+		replayed:          false,
+		walSync:           options.WalSync,
+		batchSize:         options.BatchSize,
+		dedupWindow:       int64(options.DedupWindow / time.Second),
+		data:              make(map[string]*chunkList),
+		channelsNotify:    make(chan struct{}),
+		delayedNotify:     make(chan struct{}, 1),
+		delayedOnce:       sync.Once{},
+		sweepInterval:     options.SweepInterval,
+		sweepOnce:         sync.Once{},
+		snapshotDirPath:   options.SnapshotDirPath,
+		snapshotInterval:  options.SnapshotInterval,
+		closeMu:           sync.Mutex{},
+		closed:            make(chan struct{}),
+		background:        sync.WaitGroup{},
 	}
 
+	// No snapshot is taken without syncing, it would leave out the entries of the WAL before it
 	if !options.SyncOnStartup {
-		return m
+		return m, nil
 	}
 
 	// Inform the user that the storage is being synced
 	slog.Info("syncing storage on startup, this may take a while")
 
-	// Load data from the Write-Ahead Log (WAL)
-	reader := m.wal.NewReader()
-	for {
-		data, _, err := reader.Next()
+	// Load the latest valid snapshot, and replay the Write-Ahead Log (WAL) after the checkpoint it was taken at on top of it,
+	// or the whole WAL if there is none. The committed offsets of the durable subscriptions are resolved once all
+	// the messages have been loaded.
+	state := newReplayState()
+	checkpoint := m.loadSnapshot(state)
+
+	// The damaged records of the WAL were recovered from before it was opened, the ones left are skipped
+	var from wal.SegmentID
+	if checkpoint != nil {
+		from = checkpoint.SegmentId
+		state.afterSnapshot = true
+	}
+	refuse := options.WalRecoveryMode == WalRecoveryModeRefuse
+	var damaged *walDamage
+	reader, err := newWalReader(m.walDirPath, m.walSegmentFileExt, from, func(damage *walDamage) {
+		if damaged == nil {
+			damaged = damage
+		}
+		if !refuse {
+			slog.Warn("skipping damaged WAL records", damage.attrs()...)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAL: %w", err)
+	}
+	defer reader.Close()
+
+	for first := true; ; first = false {
+		data, position, err := reader.Next()
+		if refuse && damaged != nil {
+			return nil, fmt.Errorf(
+				"%w: damaged records in segment %d at block %d, chunk offset %d (%v)",
+				ErrWalCorrupt,
+				damaged.segment,
+				damaged.offset/walBlockSize,
+				damaged.offset%walBlockSize,
+				damaged.reason,
+			)
+		}
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
 
-			slog.Error(
-				"failed to read WAL",
-				slog.Any("error", err),
-			)
-			os.Exit(1)
+			return nil, fmt.Errorf("failed to read WAL: %w", err)
+		}
+
+		// The entries up to the checkpoint are in the snapshot
+		if checkpoint != nil && !isAfter(position, checkpoint) {
+			continue
 		}
 
 		// Unmarshal the protobuf data
 		entry := &pb.WalEntry{}
 		if err := proto.Unmarshal(data, entry); err != nil {
-			slog.Error(
-				"failed to unmarshal data",
+			if refuse {
+				return nil, fmt.Errorf(
+					"%w: record in segment %d at block %d, chunk offset %d cannot be decoded (%v)",
+					ErrWalCorrupt,
+					position.SegmentId,
+					position.BlockNumber,
+					position.ChunkOffset,
+					err,
+				)
+			}
+			slog.Warn(
+				"skipping WAL record that cannot be decoded",
+				slog.Uint64("segment", uint64(position.SegmentId)),
+				slog.Uint64("block", uint64(position.BlockNumber)),
+				slog.Int64("chunk_offset", position.ChunkOffset),
 				slog.Any("error", err),
 			)
+			continue
+		}
+
+		// The segments before the first one were deleted once past retention, the first segment
+		// is only known when the whole WAL is replayed
+		if first && checkpoint == nil {
+			m.firstSegment = position.SegmentId
+		}
+
+		m.replayEntry(entry, position.SegmentId, state)
+	}
+
+	// Position the durable subscriptions right before the offset they resume from, a subscription
+	// that has fallen behind the retention of its channel is told so on its next read
+	for subscriberID, channels := range state.committedOffsets {
+		for channel, offset := range channels {
+			m.data[channel].committed[subscriberID] = offset
+			if offset > 0 && offset < m.data[channel].retainedFrom {
+				m.data[channel].lagging[subscriberID] = struct{}{}
+				continue
+			}
+
+			var lastChunk *chunk
+			for iterator := m.data[channel].head; iterator != nil && iterator.offset < offset; iterator = iterator.next {
+				lastChunk = iterator
+			}
+			m.data[channel].cursors[subscriberID] = lastChunk
+		}
+	}
+
+	if state.expiring || state.retaining {
+		m.scheduleSweep()
+	}
+
+	// Append the delayed messages that are still pending once due
+	for _, msgList := range m.data {
+		if len(msgList.delayed) > 0 {
+			m.scheduleDelayed()
 			break
 		}
+	}
 
-		channel := entry.GetChannel()
-		message := entry.GetMessage()
+	// The WAL segments can only be deleted once their entries are in memory
+	m.replayed = true
 
-		// Create the channel if it does not exist
-		if !m.ChannelExists(channel) {
-			_ = m.CreateChannel(channel)
-			slog.Info(
-				"created channel",
-				slog.String("channel", channel),
-			)
+	m.scheduleSnapshots()
+
+	// Inform the user that the storage has been synced
+	slog.Info("storage synced successfully")
+	return m, nil
+}
+
+// runInBackground runs the function in a goroutine of its own that returns once the storage is closed,
+// the function is not run if the storage is closed already
+func (m *MemoryStorage) runInBackground(run func()) {
+	m.closeMu.Lock()
+	defer m.closeMu.Unlock()
+
+	select {
+	case <-m.closed:
+		return
+	default:
+	}
+
+	m.background.Add(1)
+	go func() {
+		defer m.background.Done()
+		run()
+	}()
+}
+
+// Close stops appending the delayed messages, sweeping the channels and taking snapshots and waits until they
+// have stopped, then takes a final snapshot when snapshots are scheduled. The WAL is closed by the caller
+// afterwards and the storage is not used once closed.
+func (m *MemoryStorage) Close() error {
+	m.closeMu.Lock()
+	select {
+	case <-m.closed:
+		m.closeMu.Unlock()
+		m.background.Wait()
+		return nil
+	default:
+		close(m.closed)
+	}
+	m.closeMu.Unlock()
+
+	m.background.Wait()
+
+	if m.snapshotDirPath == "" || m.snapshotInterval <= 0 || !m.replayed {
+		return nil
+	}
+	return m.Snapshot()
+}
+
+// replayState is the state of a replay of the WAL (or of a snapshot) kept across its entries, the committed offsets
+// of the durable subscriptions, the messages written again by the compactions that are not over, and whether messages
+// have to be dropped once expired or past retention. The entries replayed after a snapshot may already be in it.
+type replayState struct {
+	committedOffsets map[string]map[string]uint64
+	compactions      map[string][]*chunk
+	expiring         bool
+	retaining        bool
+	afterSnapshot    bool
+}
+
+// newReplayState initializes the state of a new replay
+func newReplayState() *replayState {
+	return &replayState{
+		committedOffsets: make(map[string]map[string]uint64),
+		compactions:      make(map[string][]*chunk),
+		expiring:         false,
+		retaining:        false,
+		afterSnapshot:    false,
+	}
+}
+
+// replayEntry applies an entry of the WAL (or of a snapshot) written to the segment to the storage
+func (m *MemoryStorage) replayEntry(entry *pb.WalEntry, segment wal.SegmentID, state *replayState) {
+	// The checkpoints of the snapshots hold no data
+	if entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT {
+		return
+	}
+
+	channel := entry.GetChannel()
+	message := entry.GetMessage()
+
+	// The deleted channel is only brought back by the entries written to it afterwards
+	if entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_DELETED {
+		for _, name := range m.deleteChannel(channel) {
+			for _, channels := range state.committedOffsets {
+				delete(channels, name)
+			}
+			delete(state.compactions, name)
 		}
+		return
+	}
+
+	// Create the channel if it does not exist, the channels are created by their creation entries
+	// while the WAL written before those were introduced only has the entries written to the channels
+	msgList, exists := m.data[channel]
+	if !exists {
+		msgList = m.createChannel(channel)
+		slog.Info(
+			"created channel",
+			slog.String("channel", channel),
+		)
+	}
+
+	switch entry.GetType() {
+	case pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CREATED:
+		// The channel was created above, along with its partitions, which have creation entries of their own
 
-		// Get the list of messages in the channel
-		msgList := m.data[channel]
+	case pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG:
+		// The latest configuration of the channel wins, along with the next offset and the first offset
+		// retained by the channel when the configuration was written again for older segments to be deleted
+		msgList.config = entry.GetConfig()
+		msgList.len = max(msgList.len, entry.GetOffset())
+		msgList.retainedFrom = max(msgList.retainedFrom, entry.GetRetainedFrom())
+		state.retaining = state.retaining || hasRetention(entry.GetConfig()) || entry.GetConfig().GetCompacted()
 
-		// Make a new chunk and append it to the list
+		// The partitions of the channel that were never published to are not in the WAL
+		for p := uint32(1); p < entry.GetConfig().GetPartitions(); p++ {
+			if _, exists := m.data[PartitionChannel(channel, p)]; !exists {
+				m.createChannel(PartitionChannel(channel, p))
+			}
+		}
+
+	case pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET:
+		// The latest committed offset of the subscription wins
+		if _, exists := state.committedOffsets[entry.GetSubscription()]; !exists {
+			state.committedOffsets[entry.GetSubscription()] = make(map[string]uint64)
+		}
+		state.committedOffsets[entry.GetSubscription()][channel] = entry.GetOffset()
+
+	case pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE_DUE:
+		// Append the delayed message that became due, at the position it was appended at,
+		// unless the snapshot replayed before has it appended already
+		appended := state.afterSnapshot && entry.GetOffset() < msgList.len
+		msgList.len = max(msgList.len, entry.GetOffset())
+		if delayed, segment := msgList.undelay(message.GetId()); delayed != nil && !appended {
+			msgList.appendDelayed(delayed, segment)
+		}
+
+	case pb.WalEntryType_WAL_ENTRY_TYPE_COMPACTED_MESSAGE:
+		// The messages written again by a compaction make up the channel once the compaction is over,
+		// a compaction that was not written in full is left out
+		state.expiring = state.expiring || message.GetExpiresAt() > 0
+		state.compactions[channel] = append(state.compactions[channel], &chunk{
+			data:    message,
+			segment: segment,
+			prev:    nil,
+			next:    nil,
+		})
+
+	case pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_COMPACTED:
+		msgList.restore(state.compactions[channel], entry.GetOffset())
+		delete(state.compactions, channel)
+
+	case pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_PURGED:
+		// The messages saved after the purge are kept, which happens when the purge is replayed after a snapshot
+		// taken once it was over, while the delayed messages saved after it are written after it as well
+		msgList.purge(entry.GetOffset())
+		for _, channels := range state.committedOffsets {
+			if offset, exists := channels[channel]; exists {
+				channels[channel] = max(offset, entry.GetOffset())
+			}
+		}
+
+	default:
+		// The messages saved before the snapshot was taken are in it already
+		if state.afterSnapshot {
+			if entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE && msgList.hasDelayed(message.GetId()) {
+				return
+			}
+			if entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_MESSAGE && message.GetOffset() < msgList.len {
+				return
+			}
+		}
+
+		// Rebuild the deduplication state from the messages still within the window
+		if m.deduplicates(message) {
+			msgList.keys.forget(message.GetCreatedAt() - m.dedupWindow)
+			msgList.keys.remember(message)
+		}
+
+		// Drop the message from memory once it expires, the sweeper starts once the WAL is replayed
+		state.expiring = state.expiring || message.GetExpiresAt() > 0
+
+		// Hide the delayed message until it is due
+		if entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE {
+			msgList.delay(message, segment)
+			return
+		}
+
+		// Make a new chunk and append it to the list, the messages before it may have been evicted
+		msgList.len = max(msgList.len, message.GetOffset())
 		msgList.appendChunk(
 			&chunk{
-				data: message,
-				prev: nil,
-				next: nil,
+				data:    message,
+				segment: segment,
+				prev:    nil,
+				next:    nil,
 			},
 		)
 	}
+}
 
-	// Inform the user that the storage has been synced
-	slog.Info("storage synced successfully")
-	return m
+// deduplicates reports whether the message is deduplicated on its idempotency key
+func (m *MemoryStorage) deduplicates(message *pb.Message) bool {
+	return m.dedupWindow > 0 && message.GetIdempotencyKey() != ""
 }
 
-// SaveMessage saves a message to the specified channel
+// copyDuplicate makes the message a copy of the original message it duplicates
+func copyDuplicate(message *pb.Message, original *pb.Message) {
+	message.Id = original.GetId()
+	message.CreatedAt = original.GetCreatedAt()
+	message.Offset = original.GetOffset()
+}
+
+// SaveMessage saves a message to the specified channel, which must exist, the message is assigned its offset in the channel.
+// A message with the idempotency key of a message saved within the deduplication window is not saved again,
+// it is given the ID, timestamp and offset of the original message instead, and a message of a producer that is not
+// a duplicate is refused if its sequence number is not above the ones saved by the producer. A delayed message is hidden
+// from the subscribers of the channel until it is due, it is only assigned its offset then.
 func (m *MemoryStorage) SaveMessage(
 	channel string,
 	message *pb.Message,
 ) (uint64, error) {
-	// Create the channel if it does not exist
-	if !m.ChannelExists(channel) {
-		_ = m.CreateChannel(channel)
-		slog.Info(
-			"created channel",
-			slog.String("channel", channel),
-		)
-	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	// Get the list of messages in the channel, channels are only created explicitly
+	msgList, exists := m.data[channel]
+	if !exists {
+		return 0, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+	_, config := m.configOf(channel)
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
 
-	// Get the list of messages in the channel
-	msgList := m.data[channel]
+	// Return the offset of the original message, if the message is a duplicate, a message of its producer
+	// that is not a duplicate has to have a sequence number above the ones saved by the producer
+	if m.deduplicates(message) {
+		if original := msgList.keys.duplicateOf(message, m.dedupWindow); original != nil {
+			copyDuplicate(message, original)
+			return message.GetOffset(), nil
+		}
+		if msgList.keys.stale(message, nil) {
+			return 0, ErrStaleSequence
+		}
+	}
 
-	// Write the message to the Write-Ahead Log (WAL)
+	// Write the message to the Write-Ahead Log (WAL), a message that is not delayed is assigned the next offset of the channel
 	entry := &pb.WalEntry{
 		Channel: channel,
 		Message: message,
 	}
+	delayed := isDelayed(message, time.Now().UnixMilli())
+	if delayed {
+		entry.Type = pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE
+	} else {
+		message.Offset = msgList.len
+	}
 
 	data, err := proto.Marshal(entry)
 	if err != nil {
@@ -168,33 +617,207 @@ func (m *MemoryStorage) SaveMessage(
 			slog.Any("error", err),
 		)
 
-		return msgList.len, ErrInternal
+		return 0, ErrInternal
 	}
 
 	// Write the data to the WAL
-	if _, err = m.wal.Write(data); err != nil {
+	position, err := m.wal.Write(data)
+	if err != nil {
 		slog.Error(
 			"failed to write to WAL",
 			slog.Any("error", err),
 		)
 
-		return msgList.len, ErrInternal
+		return 0, ErrInternal
+	}
+
+	// Sync the WAL for the channels synced on every publish, when the WAL does not sync its writes already
+	if !m.walSync && syncsPublishes(config) {
+		if err := m.wal.Sync(); err != nil {
+			slog.Error(
+				"failed to sync WAL",
+				slog.Any("error", err),
+			)
+
+			return 0, ErrInternal
+		}
+	}
+
+	if m.deduplicates(message) {
+		msgList.keys.remember(message)
+	}
+
+	// Drop the message from memory once it expires
+	if message.GetExpiresAt() > 0 {
+		m.scheduleSweep()
+	}
+
+	// Hide the delayed message until it is due
+	if delayed {
+		msgList.delay(message, position.SegmentId)
+		m.scheduleDelayed()
+		return message.GetOffset(), nil
 	}
 
 	// Make a new chunk and append it to the list
 	msgList.appendChunk(
 		&chunk{
-			data: message,
-			prev: nil,
-			next: nil,
+			data:    message,
+			segment: position.SegmentId,
+			prev:    nil,
+			next:    nil,
 		},
 	)
 
-	// Return the index of the message in the channel
-	return msgList.len, nil
+	// Notify the subscribers waiting for new messages in the channel
+	msgList.broadcast()
+
+	// Return the offset of the message in the channel
+	return message.GetOffset(), nil
 }
 
-// GetMessages retrieves all messages from the specified channel
+// SaveMessages saves messages to their channels, which must exist, as a single WAL batch, either all the messages
+// are saved or none is. The messages are assigned their offsets, which are returned in order.
+// Duplicates, of saved messages or of messages earlier in the batch, are deduplicated like in SaveMessage,
+// nothing is saved if a message of a producer is stale, and delayed messages are hidden until they are due like in SaveMessage.
+func (m *MemoryStorage) SaveMessages(entries []*pb.WalEntry) ([]uint64, error) {
+	// The batch may span several channels, so it is written under the write lock of the storage
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Nothing is saved if any of the channels does not exist, channels are only created explicitly.
+	// The batch is synced if the WAL syncs its writes, or if any of the channels is synced on every publish.
+	sync := m.walSync
+	for _, entry := range entries {
+		if _, exists := m.data[entry.GetChannel()]; !exists {
+			return nil, fmt.Errorf("channel '%s' does not exist", entry.GetChannel())
+		}
+		if _, config := m.configOf(entry.GetChannel()); syncsPublishes(config) {
+			sync = true
+		}
+	}
+
+	// Assign the messages the next offsets of their channels, and stage them in the WAL
+	m.wal.ClearPendingWrites()
+	nextOffsets := make(map[string]uint64)
+	offsets := make([]uint64, 0, len(entries))
+	saved := make([]*pb.WalEntry, 0, len(entries))
+	batchKeys := make(map[string]map[string]*pb.Message)
+	batchSequences := make(map[string]map[string]uint64)
+	now := time.Now().UnixMilli()
+	for _, entry := range entries {
+		channel := entry.GetChannel()
+		message := entry.GetMessage()
+
+		// Return the offset of the original message, if the message is a duplicate, nothing is saved
+		// if a message that is not is stale
+		if m.deduplicates(message) {
+			if _, exists := batchKeys[channel]; !exists {
+				batchKeys[channel] = make(map[string]*pb.Message)
+				batchSequences[channel] = make(map[string]uint64)
+			}
+			original := batchKeys[channel][message.GetIdempotencyKey()]
+			if original == nil {
+				original = m.data[channel].keys.duplicateOf(message, m.dedupWindow)
+			}
+			if original != nil {
+				copyDuplicate(message, original)
+				offsets = append(offsets, message.GetOffset())
+				continue
+			}
+			if m.data[channel].keys.stale(message, batchSequences[channel]) {
+				m.wal.ClearPendingWrites()
+				return nil, ErrStaleSequence
+			}
+			batchKeys[channel][message.GetIdempotencyKey()] = message
+		}
+
+		if isDelayed(message, now) {
+			entry.Type = pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE
+		} else {
+			if _, exists := nextOffsets[channel]; !exists {
+				nextOffsets[channel] = m.data[channel].len
+			}
+			entry.Message.Offset = nextOffsets[channel]
+			nextOffsets[channel]++
+		}
+		offsets = append(offsets, entry.GetMessage().GetOffset())
+
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			slog.Error(
+				"failed to marshal data",
+				slog.Any("error", err),
+			)
+
+			m.wal.ClearPendingWrites()
+			return nil, ErrInternal
+		}
+		m.wal.PendingWrites(data)
+		saved = append(saved, entry)
+	}
+
+	// Nothing to write, every message is a duplicate
+	if len(saved) == 0 {
+		return offsets, nil
+	}
+
+	// Write the whole batch to the Write-Ahead Log (WAL), it is synced once if it has to be
+	positions, err := m.wal.WriteAll()
+	if err != nil {
+		slog.Error(
+			"failed to write batch to WAL",
+			slog.Any("error", err),
+		)
+
+		return nil, ErrInternal
+	}
+
+	if sync {
+		if err := m.wal.Sync(); err != nil {
+			slog.Error(
+				"failed to sync WAL",
+				slog.Any("error", err),
+			)
+
+			return nil, ErrInternal
+		}
+	}
+
+	// Make new chunks and append them to their lists, the delayed messages are hidden until they are due
+	for i, entry := range saved {
+		msgList := m.data[entry.GetChannel()]
+		if m.deduplicates(entry.GetMessage()) {
+			msgList.keys.remember(entry.GetMessage())
+		}
+		if entry.GetMessage().GetExpiresAt() > 0 {
+			m.scheduleSweep()
+		}
+		if entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE {
+			msgList.delay(entry.GetMessage(), positions[i].SegmentId)
+			m.scheduleDelayed()
+			continue
+		}
+		msgList.appendChunk(
+			&chunk{
+				data:    entry.GetMessage(),
+				segment: positions[i].SegmentId,
+				prev:    nil,
+				next:    nil,
+			},
+		)
+	}
+
+	// Notify the subscribers waiting for new messages in the channels
+	for channel := range nextOffsets {
+		m.data[channel].broadcast()
+	}
+
+	return offsets, nil
+}
+
+// GetMessages retrieves the next batch of messages from the specified channel for the subscriber,
+// a batch holds at most the batch size of the channel, or else of the storage
 func (m *MemoryStorage) GetMessages(
 	channel string,
 	subscriberID string,
@@ -211,72 +834,420 @@ func (m *MemoryStorage) GetMessages(
 		)
 		return nil, 0, fmt.Errorf("channel '%s' does not exist", channel)
 	}
+	_, config := m.configOf(channel)
+	batchSize := batchSizeOf(config, m.batchSize)
 
-	// Initialize the subscriberToChannelChunk map if it does not exist
-	if _, exists := m.subscriberToChannelChunk[subscriberID]; !exists {
-		m.subscriberToChannelChunk[subscriberID] = make(map[string]*chunk)
+	// The lock of the list is required as the cursors are updated,
+	// and subscribers woken up together read the channel concurrently
+	messages.mu.Lock()
+	defer messages.mu.Unlock()
+
+	// The subscriber has fallen behind the retention of the channel, it is told so once
+	if _, lagging := messages.lagging[subscriberID]; lagging {
+		delete(messages.lagging, subscriberID)
+		return []*pb.Message(nil), 0, ErrOffsetOutOfRange
+	}
+
+	// Get the position of the subscriber in the channel, the offset is only used to
+	// position a subscriber (or a consumer group) reading the channel for the first time
+	lastChunk, hasCursor := messages.cursors[subscriberID]
+	iterator := messages.head
+	switch {
+	case lastChunk != nil:
+		// Resume right after the last chunk read
+		iterator = lastChunk.next
+	case hasCursor:
+		// Nothing has been read yet, start from the beginning
+	case offset == OffsetLatest:
+		// Move the cursor to the latest message
+		messages.cursors[subscriberID] = messages.tail
+		return []*pb.Message(nil), messages.len - 1, nil
+	case offset >= messages.len:
+		return []*pb.Message(nil), 0, ErrInvalidOffset
+	case offset > OffsetBeginning && offset < messages.retainedFrom:
+		// The messages from the offset on have been evicted
+		return []*pb.Message(nil), 0, ErrOffsetOutOfRange
+	default:
+		// Move the iterator to the start offset
+		iterator = messages.chunkAt(offset)
 	}
 
-	// Check if the offset is valid
-	if offset >= messages.len {
-		// Return only the latest message if the offset is set to the latest
-		if offset == OffsetLatest {
-			// Update the last chunk in the subscriberToChannelChunk map to the latest message
-			m.subscriberToChannelChunk[subscriberID][channel] = messages.tail
-			return []*pb.Message(nil), messages.len - 1, nil
+	// Nothing new to read
+	if iterator == nil {
+		return []*pb.Message(nil), 0, ErrInvalidOffset
+	}
+
+	// Copy the messages from the channel, limiting the number of messages to be returned,
+	// the expired messages that have not been dropped yet are skipped
+	now := time.Now().UnixMilli()
+	data := make([]*pb.Message, 0)
+	for iterator != nil && uint64(len(data)) < batchSize {
+		if !isExpired(iterator.data, now) {
+			data = append(data, iterator.data)
 		}
+		lastChunk = iterator
+		iterator = iterator.next
+	}
+
+	// Move the cursor to the last chunk read
+	messages.cursors[subscriberID] = lastChunk
 
+	// Nothing new to read, every message left has expired
+	if len(data) == 0 {
 		return []*pb.Message(nil), 0, ErrInvalidOffset
 	}
 
-	// Limit the number of messages to be returned
-	var endIndx uint64 = 0
-	if offset+m.batchSize > messages.len {
-		endIndx = messages.len
-	} else {
-		endIndx = offset + m.batchSize
+	// Return the messages and the offset of the last message read
+	return data, lastChunk.offset, nil
+}
+
+// CreateChannel creates a new channel, an existing channel is left untouched. The creation is written
+// to the WAL, so that the channel is brought back when the storage is synced on startup even if it is empty.
+func (m *MemoryStorage) CreateChannel(channel string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.data[channel]; exists {
+		return nil
 	}
 
-	// Copy the messages from the channel
-	data := make([]*pb.Message, 0)
+	if _, err := m.writeEntry(&pb.WalEntry{
+		Channel: channel,
+		Type:    pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CREATED,
+	}); err != nil {
+		slog.Error(
+			"failed to write to WAL",
+			slog.Any("error", err),
+		)
 
-	// Get the iterator to the start offset
-	iterator := messages.head
-	prevChunk := m.subscriberToChannelChunk[subscriberID][channel]
-	if prevChunk != nil {
-		iterator = prevChunk.next
+		return ErrInternal
 	}
 
-	for i := offset; i < endIndx; i++ {
-		data = append(data, iterator.data)
-		if iterator.next == nil {
-			break
-		}
+	m.createChannel(channel)
+	return nil
+}
 
-		iterator = iterator.next
+// createChannel creates a new channel in memory, the caller must hold the lock of the storage
+// (unless the storage is being synced on startup) and make sure the channel does not exist
+func (m *MemoryStorage) createChannel(channel string) *chunkList {
+	msgList := &chunkList{
+		mu:           sync.Mutex{},
+		head:         nil,
+		tail:         nil,
+		len:          0,
+		count:        0,
+		bytes:        0,
+		retainedFrom: 0,
+		index:        make([]*chunk, 0),
+		notify:       make(chan struct{}),
+		config:       &pb.ChannelConfig{},
+		keys:         newIdempotencyKeys(),
+		delayed:      make(delayedQueue, 0),
+		delayedSeq:   0,
+		expired:      0,
+		evicted:      0,
+		cursors:      make(map[string]*chunk),
+		lagging:      make(map[string]struct{}),
+		committed:    make(map[string]uint64),
 	}
+	m.data[channel] = msgList
 
-	// Update the last chunk in the subscriberToChannelChunk map
-	m.subscriberToChannelChunk[subscriberID][channel] = iterator
+	// Notify everyone waiting for new channels
+	close(m.channelsNotify)
+	m.channelsNotify = make(chan struct{})
 
-	// Return the messages and the next offset
-	return data, endIndx - 1, nil
+	return msgList
 }
 
-// CreateChannel creates a new channel
-func (m *MemoryStorage) CreateChannel(channel string) error {
+// DeleteChannel deletes the specified channel along with its partitions, their messages and cursors, a tombstone
+// is written to the WAL so that the channel is not brought back when the storage is synced on startup
+func (m *MemoryStorage) DeleteChannel(channel string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.data[channel]; !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	if _, err := m.writeEntry(&pb.WalEntry{
+		Channel: channel,
+		Type:    pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_DELETED,
+	}); err != nil {
+		slog.Error(
+			"failed to write to WAL",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	m.deleteChannel(channel)
+	return nil
+}
+
+// deleteChannel removes the channel along with its partitions, and returns the names of the lists removed. The readers
+// waiting for new chunks are woken up, so that they find out the channel is gone. The caller must hold the write lock
+// of the storage.
+func (m *MemoryStorage) deleteChannel(channel string) []string {
+	msgList, exists := m.data[channel]
+	if !exists {
+		return nil
+	}
+
+	names := []string{channel}
+	for p := uint32(1); p < msgList.config.GetPartitions(); p++ {
+		names = append(names, PartitionChannel(channel, p))
+	}
+
+	for _, name := range names {
+		if msgList, exists := m.data[name]; exists {
+			msgList.broadcast()
+			delete(m.data, name)
+		}
+	}
+
+	return names
+}
+
+// PurgeChannel drops the messages of the specified channel and of its partitions, including the delayed messages that
+// are not due yet, the channel keeps its configuration and the offsets of the messages dropped are not given out again.
+// The next offset of every partition is written to the WAL, so that the messages dropped are not brought back when
+// the storage is synced on startup.
+func (m *MemoryStorage) PurgeChannel(channel string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.data[channel] = &chunkList{
-		head: nil,
-		tail: nil,
-		len:  0,
+	msgList, exists := m.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	names := []string{channel}
+	for p := uint32(1); p < msgList.config.GetPartitions(); p++ {
+		if _, exists := m.data[PartitionChannel(channel, p)]; exists {
+			names = append(names, PartitionChannel(channel, p))
+		}
+	}
+
+	// Write the purge of every partition to the Write-Ahead Log (WAL) as a single batch
+	m.wal.ClearPendingWrites()
+	for _, name := range names {
+		data, err := proto.Marshal(&pb.WalEntry{
+			Channel: name,
+			Type:    pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_PURGED,
+			Offset:  m.data[name].len,
+		})
+		if err != nil {
+			slog.Error(
+				"failed to marshal data",
+				slog.Any("error", err),
+			)
+
+			m.wal.ClearPendingWrites()
+			return ErrInternal
+		}
+		m.wal.PendingWrites(data)
+	}
+
+	if _, err := m.wal.WriteAll(); err != nil {
+		slog.Error(
+			"failed to write batch to WAL",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	if m.walSync {
+		if err := m.wal.Sync(); err != nil {
+			slog.Error(
+				"failed to sync WAL",
+				slog.Any("error", err),
+			)
+
+			return ErrInternal
+		}
+	}
+
+	for _, name := range names {
+		msgList := m.data[name]
+		removed := msgList.purge(msgList.len)
+		msgList.broadcast()
+
+		slog.Info(
+			"channel purged",
+			slog.String("channel", name),
+			slog.Uint64("count", removed),
+		)
+	}
+
+	return nil
+}
+
+// GetChannels returns the names of all the channels, in lexical order, the partitions of the channels are left out
+func (m *MemoryStorage) GetChannels() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	channels := make([]string, 0, len(m.data))
+	for channel := range m.data {
+		if _, partition := ParsePartitionChannel(channel); partition != 0 {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	return channels
+}
+
+// WatchChannels returns a channel that is closed when the next channel is created
+func (m *MemoryStorage) WatchChannels() <-chan struct{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.channelsNotify
+}
+
+// WatchChannel returns a channel that is closed when the next message is saved to the specified channel
+func (m *MemoryStorage) WatchChannel(channel string) (<-chan struct{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages, exists := m.data[channel]
+	if !exists {
+		return nil, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	messages.mu.Lock()
+	defer messages.mu.Unlock()
+
+	return messages.notify, nil
+}
+
+// SetChannelConfig sets the configuration of the specified channel, the configuration is written to the WAL
+func (m *MemoryStorage) SetChannelConfig(
+	channel string,
+	config *pb.ChannelConfig,
+) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList, exists := m.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	// Write the configuration to the Write-Ahead Log (WAL), so that it survives a restart
+	entry := &pb.WalEntry{
+		Channel: channel,
+		Type:    pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG,
+		Config:  config,
+	}
+
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		slog.Error(
+			"failed to marshal data",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	if _, err = m.wal.Write(data); err != nil {
+		slog.Error(
+			"failed to write to WAL",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	msgList.config = config
+
+	// Evict the messages past the retention of the channel, or compact it
+	if hasRetention(config) || config.GetCompacted() {
+		m.scheduleSweep()
 	}
 
 	return nil
 }
 
+// GetChannelConfig returns the configuration of the specified channel
+func (m *MemoryStorage) GetChannelConfig(channel string) (*pb.ChannelConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList, exists := m.data[channel]
+	if !exists {
+		return nil, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	return msgList.config, nil
+}
+
+// GetChannelStats returns the number, the offsets, the timestamps and the size of the messages of the specified channel
+func (m *MemoryStorage) GetChannelStats(channel string) (*pb.ChannelStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList, exists := m.data[channel]
+	if !exists {
+		return nil, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	stats := &pb.ChannelStats{
+		MessageCount:        msgList.count,
+		FirstOffset:         0,
+		LastOffset:          0,
+		FirstCreatedAt:      0,
+		LastCreatedAt:       0,
+		NextOffset:          msgList.len,
+		SizeBytes:           msgList.bytes,
+		DelayedMessageCount: uint64(len(msgList.delayed)),
+	}
+	if msgList.head != nil {
+		stats.FirstOffset = msgList.head.offset
+		stats.LastOffset = msgList.tail.offset
+		stats.FirstCreatedAt = msgList.head.data.GetCreatedAt()
+		stats.LastCreatedAt = msgList.tail.data.GetCreatedAt()
+	}
+
+	return stats, nil
+}
+
+// GetOffsetByTime returns the offset of the first message of the specified channel created at or after
+// the timestamp, or the offset of the next message to be saved if there is none
+func (m *MemoryStorage) GetOffsetByTime(channel string, timestamp int64) (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages, exists := m.data[channel]
+	if !exists {
+		return 0, fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	messages.mu.Lock()
+	defer messages.mu.Unlock()
+
+	chunk := messages.chunkAtTime(timestamp)
+	if chunk == nil {
+		return messages.len, nil
+	}
+
+	return chunk.offset, nil
+}
+
 // ChannelExists checks if a channel exists
 func (m *MemoryStorage) ChannelExists(channel string) bool {
 	m.mu.RLock()
@@ -286,14 +1257,72 @@ func (m *MemoryStorage) ChannelExists(channel string) bool {
 	return exists
 }
 
-// RemoveChannelFromSubscriberMap removes the channel from the subscriberToChannelChunk map
+// CommitOffset writes the offset a durable subscription resumes the channel from to the WAL,
+// the subscription is positioned right before it when the storage is synced on startup
+func (m *MemoryStorage) CommitOffset(
+	channel string,
+	subscriberID string,
+	offset uint64,
+) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList, exists := m.data[channel]
+	if !exists {
+		return fmt.Errorf("channel '%s' does not exist", channel)
+	}
+
+	// Commits of the channel are written in order
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	entry := &pb.WalEntry{
+		Channel:      channel,
+		Type:         pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET,
+		Subscription: subscriberID,
+		Offset:       offset,
+	}
+
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		slog.Error(
+			"failed to marshal data",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	if _, err = m.wal.Write(data); err != nil {
+		slog.Error(
+			"failed to write to WAL",
+			slog.Any("error", err),
+		)
+
+		return ErrInternal
+	}
+
+	// Kept so that it is written again when the segment it was written to is deleted
+	msgList.committed[subscriberID] = offset
+	return nil
+}
+
+// RemoveChannelFromSubscriberMap removes the cursor of the subscriber from the channel
 func (m *MemoryStorage) RemoveChannelFromSubscriberMap(
 	channel string,
 	subscriberID string,
 ) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList, exists := m.data[channel]
+	if !exists {
+		return
+	}
+
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
 
-	// Remove the channel from the subscriberToChannelChunk map
-	delete(m.subscriberToChannelChunk[subscriberID], channel)
+	delete(msgList.cursors, subscriberID)
+	delete(msgList.lagging, subscriberID)
 }
@@ -0,0 +1,584 @@
+// pkg/storage/memory_storage_test.go
+
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rosedblabs/wal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// openSegmentedMemoryStorage opens a memory storage whose WAL is split in small segments
+func openSegmentedMemoryStorage(t *testing.T, dir string, syncOnStartup bool) (*MemoryStorage, func()) {
+	log, err := wal.Open(wal.Options{
+		DirPath:        dir,
+		SegmentSize:    512,
+		SegmentFileExt: ".wal",
+		Sync:           false,
+		BytesPerSync:   0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMemoryStorage(&MemoryStorageOptions{
+		Wal:               log,
+		WalDirPath:        dir,
+		WalSegmentFileExt: ".wal",
+		WalSync:           false,
+		WalRecoveryMode:   WalRecoveryModeSkip,
+		BatchSize:         100,
+		SyncOnStartup:     syncOnStartup,
+		DedupWindow:       0,
+		SweepInterval:     0,
+		SnapshotDirPath:   "",
+		SnapshotInterval:  0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, func() {
+		_ = m.Close()
+		_ = log.Close()
+	}
+}
+
+// walSegmentFiles returns the paths of the segment files of the WAL in the directory
+func walSegmentFiles(t *testing.T, dir string) []string {
+	segments, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return segments
+}
+
+// saveMessages saves messages with the contents to the channel, padded so that they span several WAL segments
+func saveMessages(t *testing.T, s Storage, channel string, contents ...string) {
+	for _, content := range contents {
+		message := newMessage(content)
+		message.Content = append(message.Content, make([]byte, 100)...)
+		_, err := s.SaveMessage(channel, message)
+		assert.NoError(t, err)
+	}
+}
+
+func TestMemoryStorageSegmentsKeptWithoutReplay(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openSegmentedMemoryStorage(t, dir, true)
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{RetentionMessages: 2}))
+	saveMessages(t, m, "channel", "a", "b", "c", "d", "e", "f", "g", "h")
+	closeStorage()
+
+	segments := walSegmentFiles(t, dir)
+	assert.True(t, len(segments) > 2)
+
+	// The messages of the WAL are not in memory, none of its segments is deleted
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, false)
+	assert.NoError(t, m.CreateChannel("other"))
+	assert.NoError(t, m.SetChannelConfig("other", &pb.ChannelConfig{RetentionMessages: 1}))
+	saveMessages(t, m, "other", "x", "y", "z")
+	m.evict(time.Now().UnixMilli())
+	m.deleteSegments()
+	closeStorage()
+
+	assert.Subset(t, walSegmentFiles(t, dir), segments)
+
+	// Every message is still replayed on the next restart
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, true)
+	defer closeStorage()
+
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 8)
+	messages, _, err = m.GetMessages("other", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+}
+
+func TestMemoryStorageRetention(t *testing.T) {
+	now := time.Now()
+	size := uint64(proto.Size(&pb.Message{Id: "a", Content: []byte("a"), CreatedAt: now.Unix(), Offset: 1}))
+
+	tests := []struct {
+		name   string
+		config *pb.ChannelConfig
+	}{
+		{
+			name:   "retention by messages",
+			config: &pb.ChannelConfig{RetentionMessages: 2},
+		},
+		{
+			name:   "retention by bytes",
+			config: &pb.ChannelConfig{RetentionBytes: 2 * size},
+		},
+		{
+			name:   "retention by time",
+			config: &pb.ChannelConfig{RetentionMs: 30000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, closeStorage := openSegmentedMemoryStorage(t, t.TempDir(), true)
+			defer closeStorage()
+
+			assert.NoError(t, m.CreateChannel("channel"))
+			assert.NoError(t, m.SetChannelConfig("channel", tt.config))
+
+			// The first messages were created before the retention time
+			for i, content := range []string{"a", "b", "c", "d", "e"} {
+				message := newMessage(content)
+				if i < 3 {
+					message.CreatedAt = now.Add(-time.Minute).Unix()
+				}
+				_, err := m.SaveMessage("channel", message)
+				assert.NoError(t, err)
+			}
+
+			m.evict(now.UnixMilli())
+
+			messages, offset, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"d", "e"}, contentsOf(messages))
+			assert.Equal(t, uint64(4), offset)
+
+			// The evicted messages can no longer be read from their offset
+			_, _, err = m.GetMessages("channel", "other", 1)
+			assert.Equal(t, ErrOffsetOutOfRange, err)
+
+			stats, err := m.GetChannelStats("channel")
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(5), stats.GetNextOffset())
+		})
+	}
+}
+
+func TestMemoryStorageLaggingCursor(t *testing.T) {
+	m, closeStorage := openSegmentedMemoryStorage(t, t.TempDir(), true)
+	defer closeStorage()
+
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{RetentionMessages: 2, BatchSize: 1}))
+	for _, content := range []string{"a", "b", "c"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+
+	// The lagging subscriber has read a, the other one has read everything up to c
+	messages, _, err := m.GetMessages("channel", "lagging", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, contentsOf(messages))
+	for _, content := range []string{"a", "b", "c"} {
+		messages, _, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{content}, contentsOf(messages))
+	}
+
+	for _, content := range []string{"d", "e"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	m.evict(time.Now().UnixMilli())
+
+	// The lagging subscriber is told once that b has been evicted, and then reads from the start of the channel
+	_, _, err = m.GetMessages("channel", "lagging", OffsetBeginning)
+	assert.Equal(t, ErrOffsetOutOfRange, err)
+	messages, offset, err := m.GetMessages("channel", "lagging", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d"}, contentsOf(messages))
+	assert.Equal(t, uint64(3), offset)
+
+	// The other subscriber resumes where it left off
+	messages, offset, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d"}, contentsOf(messages))
+	assert.Equal(t, uint64(3), offset)
+}
+
+func TestMemoryStorageSegmentsDeleted(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openSegmentedMemoryStorage(t, dir, true)
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{RetentionMessages: 2}))
+	saveMessages(t, m, "channel", "a", "b", "c", "d", "e", "f", "g", "h")
+	assert.NoError(t, m.CommitOffset("channel", "durable", 7))
+
+	m.evict(time.Now().UnixMilli())
+	m.deleteSegments()
+	closeStorage()
+
+	// The first segments only held the evicted messages, along with the creation and configuration of the channel
+	_, err := os.Stat(wal.SegmentFileName(dir, ".wal", 1))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	// The configuration and the committed offset are written again to the segments left
+	walRecordAt(t, dir, func(entry *pb.WalEntry) bool {
+		return entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG &&
+			entry.GetConfig().GetRetentionMessages() == 2 &&
+			entry.GetOffset() == 8 &&
+			entry.GetRetainedFrom() == 6
+	})
+	walRecordAt(t, dir, func(entry *pb.WalEntry) bool {
+		return entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET &&
+			entry.GetSubscription() == "durable" &&
+			entry.GetOffset() == 7
+	})
+
+	// The messages left keep their offsets on restart
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, true)
+	defer closeStorage()
+
+	config, err := m.GetChannelConfig("channel")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), config.GetRetentionMessages())
+
+	messages, offset, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "g", messages[0].GetId())
+	assert.Equal(t, uint64(6), messages[0].GetOffset())
+	assert.Equal(t, uint64(7), offset)
+
+	_, _, err = m.GetMessages("channel", "other", 5)
+	assert.Equal(t, ErrOffsetOutOfRange, err)
+
+	// The durable subscription resumes from its committed offset
+	messages, _, err = m.GetMessages("channel", "durable", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "h", messages[0].GetId())
+
+	// The next message is saved after the last one
+	offset, err = m.SaveMessage("channel", newMessage("i"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), offset)
+}
+
+// saveKeyed saves messages with the keys and contents to the channel, an empty content is a tombstone
+func saveKeyed(t *testing.T, s Storage, channel string, entries ...[2]string) {
+	for _, entry := range entries {
+		message := newMessage(entry[1])
+		message.Key = entry[0]
+		_, err := s.SaveMessage(channel, message)
+		assert.NoError(t, err)
+	}
+}
+
+func TestMemoryStorageCompaction(t *testing.T) {
+	walDir := t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, "")
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{Compacted: true, BatchSize: 3}))
+	saveKeyed(t, m, "channel", [2]string{"a", "a1"}, [2]string{"b", "b1"}, [2]string{"a", "a2"}, [2]string{"b", ""},
+		[2]string{"a", "a3"}, [2]string{"c", "c1"}, [2]string{"a", "a4"}, [2]string{"c", "c2"})
+
+	// The reader has read up to a2, so the tombstone of b is kept for it to read
+	messages, _, err := m.GetMessages("channel", "reader", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "a2"}, contentsOf(messages))
+
+	// The tombstone of a key nobody reads removes the key
+	assert.NoError(t, m.CreateChannel("deleted"))
+	assert.NoError(t, m.SetChannelConfig("deleted", &pb.ChannelConfig{Compacted: true}))
+	saveKeyed(t, m, "deleted", [2]string{"a", "a1"}, [2]string{"b", "b1"}, [2]string{"b", ""})
+
+	m.compact()
+
+	// Only the latest message of every key is kept, at its offset
+	messages, offset, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "a4", "c2"}, contentsOf(messages))
+	assert.Equal(t, uint64(3), messages[0].GetOffset())
+	assert.Equal(t, uint64(6), messages[1].GetOffset())
+	assert.Equal(t, uint64(7), offset)
+
+	// The cursor on a removed message moves back to the last message kept before it
+	messages, _, err = m.GetMessages("channel", "reader", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "a4", "c2"}, contentsOf(messages))
+
+	// A start offset in a compacted range starts from the next message kept
+	messages, _, err = m.GetMessages("channel", "late", 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a4", "c2"}, contentsOf(messages))
+
+	messages, _, err = m.GetMessages("deleted", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1"}, contentsOf(messages))
+	closeStorage()
+
+	// The compaction is replayed on restart, the offsets of the messages kept are unchanged
+	m, closeStorage = openMemoryStorage(t, walDir, "")
+	defer closeStorage()
+
+	messages, offset, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "a4", "c2"}, contentsOf(messages))
+	assert.Equal(t, uint64(7), offset)
+
+	messages, _, err = m.GetMessages("deleted", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1"}, contentsOf(messages))
+
+	offset, err = m.SaveMessage("channel", &pb.Message{Key: "a", Content: []byte("a5")})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), offset)
+}
+
+func TestMemoryStorageCompactionWriteFailed(t *testing.T) {
+	m, closeStorage := openMemoryStorage(t, t.TempDir(), "")
+	defer closeStorage()
+
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{Compacted: true}))
+	saveKeyed(t, m, "channel", [2]string{"a", "a1"}, [2]string{"b", "b1"}, [2]string{"a", "a2"}, [2]string{"a", "a3"})
+
+	// The compaction fails to be written to the WAL, the messages are kept in memory as they are in the WAL
+	assert.NoError(t, m.wal.Close())
+	m.mu.RLock()
+	msgList := m.data["channel"]
+	m.mu.RUnlock()
+
+	msgList.mu.Lock()
+	removed, err := m.compactList("channel", msgList)
+	msgList.mu.Unlock()
+	assert.Error(t, err)
+	assert.Equal(t, uint64(0), removed)
+
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "a2", "a3"}, contentsOf(messages))
+}
+
+func TestMemoryStorageTornCompaction(t *testing.T) {
+	walDir := t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, "")
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{Compacted: true}))
+	saveKeyed(t, m, "channel", [2]string{"a", "a1"}, [2]string{"b", "b1"}, [2]string{"a", "a2"}, [2]string{"a", "a3"})
+	m.compact()
+	closeStorage()
+
+	// The compaction is cut short before its end is written
+	compacted := walRecordAt(t, walDir, func(entry *pb.WalEntry) bool {
+		return entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_COMPACTED
+	})
+	assert.NoError(t, os.Truncate(wal.SegmentFileName(walDir, ".wal", 1), compacted))
+
+	// The messages written again by the compaction are left out, the channel is replayed as it was before
+	m, closeStorage = openMemoryStorage(t, walDir, "")
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "a2", "a3"}, contentsOf(messages))
+
+	offset, err := m.SaveMessage("channel", &pb.Message{Key: "b", Content: []byte("b2")})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), offset)
+	closeStorage()
+
+	// The entries written after the compaction that was cut short are replayed along with the channel
+	m, closeStorage = openMemoryStorage(t, walDir, "")
+	defer closeStorage()
+
+	messages, _, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "a2", "a3", "b2"}, contentsOf(messages))
+}
+
+func TestMemoryStorageDropExpired(t *testing.T) {
+	m, closeStorage := openSegmentedMemoryStorage(t, t.TempDir(), true)
+	defer closeStorage()
+
+	assert.NoError(t, m.CreateChannel("channel"))
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{BatchSize: 1}))
+
+	// The messages expire once the sweeper runs an hour from now, but not before
+	now := time.Now().Add(time.Hour).UnixMilli()
+	for _, message := range []*pb.Message{
+		{Id: "a", Content: []byte("a"), CreatedAt: time.Now().Unix(), ExpiresAt: now - 1},
+		{Id: "b", Content: []byte("b"), CreatedAt: time.Now().Unix(), ExpiresAt: now},
+		{Id: "c", Content: []byte("c"), CreatedAt: time.Now().Unix(), ExpiresAt: now + 1},
+		{Id: "d", Content: []byte("d"), CreatedAt: time.Now().Unix(), ExpiresAt: now - 1},
+		{Id: "e", Content: []byte("e"), CreatedAt: time.Now().Unix()},
+	} {
+		_, err := m.SaveMessage("channel", message)
+		assert.NoError(t, err)
+	}
+
+	// The reader has read a, which is dropped
+	messages, _, err := m.GetMessages("channel", "reader", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, contentsOf(messages))
+
+	// The messages are dropped up to c, which has not expired yet, d is kept behind it
+	m.dropExpired(now)
+
+	stats, err := m.GetChannelStats("channel")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), stats.GetMessageCount())
+	assert.Equal(t, uint64(5), stats.GetNextOffset())
+
+	// The messages left keep their offsets, the reader resumes with the first message kept after a
+	messages, offset, err := m.GetMessages("channel", "reader", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, contentsOf(messages))
+	assert.Equal(t, uint64(2), offset)
+
+	// A start offset on a dropped message starts from the next message kept
+	messages, offset, err = m.GetMessages("channel", "subscriber", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c"}, contentsOf(messages))
+	assert.Equal(t, uint64(2), offset)
+
+	// Once c expires, d is dropped along with it
+	m.dropExpired(now + 1)
+
+	stats, err = m.GetChannelStats("channel")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.GetMessageCount())
+	messages, offset, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e"}, contentsOf(messages))
+	assert.Equal(t, uint64(4), offset)
+}
+
+func TestMemoryStorageExpiredAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openSegmentedMemoryStorage(t, dir, true)
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	expired := newMessage("expired")
+	expired.ExpiresAt = time.Now().Add(-time.Second).UnixMilli()
+	_, err := m.SaveMessage("channel", expired)
+	assert.NoError(t, err)
+	_, err = m.SaveMessage("channel", newMessage("a"))
+	assert.NoError(t, err)
+	closeStorage()
+
+	// The expired message is replayed, and skipped until it is dropped
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, true)
+	defer closeStorage()
+
+	messages, offset, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, contentsOf(messages))
+	assert.Equal(t, uint64(1), offset)
+
+	m.dropExpired(time.Now().UnixMilli())
+	stats, err := m.GetChannelStats("channel")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.GetMessageCount())
+
+	offset, err = m.SaveMessage("channel", newMessage("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), offset)
+}
+
+func TestMemoryStorageDelayedMessages(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openSegmentedMemoryStorage(t, dir, true)
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	deliverAt := time.Now().Add(time.Hour).UnixMilli()
+	delayed := newMessage("delayed")
+	delayed.DeliverAt = deliverAt
+	for _, message := range []*pb.Message{newMessage("a"), delayed, newMessage("b")} {
+		_, err := m.SaveMessage("channel", message)
+		assert.NoError(t, err)
+	}
+	closeStorage()
+
+	// The delayed message is written to the WAL without an offset, it is given one once due
+	walRecordAt(t, dir, func(entry *pb.WalEntry) bool {
+		return entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE && entry.GetMessage().GetId() == "delayed"
+	})
+
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, true)
+	assert.True(t, hasPendingDelayed(m, "channel", "delayed"))
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, contentsOf(messages))
+
+	assert.Equal(t, int64(0), m.appendDue(deliverAt))
+	assert.False(t, hasPendingDelayed(m, "channel", "delayed"))
+	offset, err := m.SaveMessage("channel", newMessage("c"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), offset)
+	closeStorage()
+
+	// The due message is replayed at the offset it was given, once
+	due := walRecordAt(t, dir, func(entry *pb.WalEntry) bool {
+		return entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE_DUE && entry.GetOffset() == 2
+	})
+
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, true)
+	assert.False(t, hasPendingDelayed(m, "channel", "delayed"))
+	messages, _, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "delayed", "c"}, contentsOf(messages))
+	assert.Equal(t, uint64(2), messages[2].GetOffset())
+	closeStorage()
+
+	// A due message whose record was torn is pending again, and given the same offset once due
+	assert.NoError(t, os.Truncate(wal.SegmentFileName(dir, ".wal", 1), due))
+
+	m, closeStorage = openSegmentedMemoryStorage(t, dir, true)
+	defer closeStorage()
+
+	assert.True(t, hasPendingDelayed(m, "channel", "delayed"))
+	assert.Equal(t, int64(0), m.appendDue(deliverAt))
+	messages, _, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "delayed"}, contentsOf(messages))
+	assert.Equal(t, uint64(2), messages[2].GetOffset())
+}
+
+func TestMemoryStorageClose(t *testing.T) {
+	m, closeStorage := openSegmentedMemoryStorage(t, t.TempDir(), true)
+	defer closeStorage()
+
+	assert.NoError(t, m.CreateChannel("channel"))
+	delayed := newMessage("delayed")
+	delayed.DeliverAt = time.Now().Add(50 * time.Millisecond).UnixMilli()
+	_, err := m.SaveMessage("channel", delayed)
+	assert.NoError(t, err)
+
+	// The delayed messages are no longer appended once the storage is closed
+	assert.NoError(t, m.Close())
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, hasPendingDelayed(m, "channel", "delayed"))
+
+	// Closing the storage again returns right away
+	assert.NoError(t, m.Close())
+}
+
+func TestMemoryStorageCloseStopsSweep(t *testing.T) {
+	m, closeStorage := openSegmentedMemoryStorage(t, t.TempDir(), true)
+	defer closeStorage()
+
+	m.sweepInterval = time.Hour
+	m.scheduleSweep()
+
+	// The sweeper waiting for its next tick returns once the storage is closed
+	closed := make(chan struct{})
+	go func() {
+		_ = m.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("the sweeper did not stop once the storage was closed")
+	}
+}
@@ -0,0 +1,244 @@
+// pkg/storage/retention.go
+
+package storage
+
+import (
+	"errors"
+	"expvar"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/rosedblabs/wal"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// evictedMessages counts the messages evicted from memory by the retention of their channel, by channel
+var evictedMessages = expvar.NewMap("mq_evicted_messages")
+
+// hasRetention reports whether the configuration limits the messages kept by the channel
+func hasRetention(config *pb.ChannelConfig) bool {
+	return config.GetRetentionMs() > 0 || config.GetRetentionBytes() > 0 || config.GetRetentionMessages() > 0
+}
+
+// pastRetention reports whether the first chunk of the list is past the retention of the channel,
+// now is in milliseconds since the epoch
+func (cl *chunkList) pastRetention(config *pb.ChannelConfig, now int64) bool {
+	switch {
+	case cl.head == nil:
+		return false
+	case config.GetRetentionMessages() > 0 && cl.count > config.GetRetentionMessages():
+		return true
+	case config.GetRetentionBytes() > 0 && cl.bytes > config.GetRetentionBytes():
+		return true
+	case config.GetRetentionMs() > 0:
+		return visibleAt(cl.head.data)*1000+int64(config.GetRetentionMs()) <= now
+	default:
+		return false
+	}
+}
+
+// evict removes the chunks past the retention of the channel from the list, oldest first, and returns how many
+// were removed and whether a subscriber has fallen behind. A subscriber falls behind when the next chunk it had
+// to read is removed, its cursor is removed and it is told so on its next read. The cursors on a removed chunk
+// whose next chunk is kept are moved to the start of the list.
+func (cl *chunkList) evict(config *pb.ChannelConfig, now int64) (uint64, bool) {
+	head := cl.head
+
+	var removed uint64
+	for cl.pastRetention(config, now) {
+		cl.retainedFrom = cl.head.offset + 1
+		cl.unlink(cl.head)
+		removed++
+	}
+
+	if removed == 0 {
+		return 0, false
+	}
+
+	// The index starts with the first chunk left, which is the first one of its interval now
+	i := sort.Search(len(cl.index), func(i int) bool {
+		return cl.index[i].offset >= cl.retainedFrom
+	})
+	cl.index = cl.index[i:]
+	if cl.head != nil && (len(cl.index) == 0 || cl.index[0] != cl.head) {
+		cl.index = append([]*chunk{cl.head}, cl.index...)
+	}
+
+	lagging := false
+	for subscriberID, lastChunk := range cl.cursors {
+		next := head
+		if lastChunk != nil {
+			next = lastChunk.next
+		}
+
+		switch {
+		case next != nil && next.offset < cl.retainedFrom:
+			delete(cl.cursors, subscriberID)
+			cl.lagging[subscriberID] = struct{}{}
+			lagging = true
+		case lastChunk != nil && lastChunk.offset < cl.retainedFrom:
+			cl.cursors[subscriberID] = nil
+		}
+	}
+
+	cl.evicted += removed
+	return removed, lagging
+}
+
+// firstSegment returns the first WAL segment holding a message of the list, or the specified segment if none is before it
+func (cl *chunkList) firstSegment(segment wal.SegmentID) wal.SegmentID {
+	// Messages are written to the WAL in order, except for the delayed messages appended once due
+	for iterator := cl.head; iterator != nil; iterator = iterator.next {
+		segment = min(segment, iterator.segment)
+	}
+	for _, item := range cl.delayed {
+		segment = min(segment, item.segment)
+	}
+
+	return segment
+}
+
+// configOf returns the channel a chunk list belongs to and the configuration of the channel, the partitions
+// of a channel share the configuration of the channel. The caller must hold the read lock of the storage.
+func (m *MemoryStorage) configOf(name string) (string, *pb.ChannelConfig) {
+	channel, _ := ParsePartitionChannel(name)
+
+	msgList := m.data[channel]
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	return channel, msgList.config
+}
+
+// evict drops the messages past the retention of every channel from memory,
+// the partitions of a channel share the retention of the channel
+func (m *MemoryStorage) evict(now int64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for channel, msgList := range m.data {
+		name, config := m.configOf(channel)
+		if !hasRetention(config) {
+			continue
+		}
+
+		msgList.mu.Lock()
+		evicted, lagging := msgList.evict(config, now)
+
+		// Wake up the subscribers that have fallen behind, so that they are told so
+		if lagging {
+			msgList.broadcast()
+		}
+		msgList.mu.Unlock()
+
+		if evicted == 0 {
+			continue
+		}
+
+		evictedMessages.Add(name, int64(evicted))
+		slog.Info(
+			"messages past retention evicted",
+			slog.String("channel", channel),
+			slog.Uint64("count", evicted),
+		)
+	}
+}
+
+// deleteSegments deletes the WAL segments before the first one holding a message still in memory. The configurations
+// of the channels and the offsets committed by the durable subscriptions are written again beforehand, so that the
+// entries of the deleted segments replayed on startup are not lost, along with the next offset of every channel and
+// the first offset it retains. The segments of a WAL that was not replayed on startup are never deleted, the entries
+// they hold are not in memory.
+func (m *MemoryStorage) deleteSegments() {
+	if m.walDirPath == "" || !m.replayed {
+		return
+	}
+
+	// The active segment is never deleted
+	m.mu.RLock()
+	first := m.wal.ActiveSegmentID()
+	for _, msgList := range m.data {
+		msgList.mu.Lock()
+		first = msgList.firstSegment(first)
+		msgList.mu.Unlock()
+	}
+	m.mu.RUnlock()
+
+	if first <= m.firstSegment {
+		return
+	}
+
+	// Nothing is written to the WAL meanwhile, so that the entries written again are the latest ones
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for channel, msgList := range m.data {
+		entries := []*pb.WalEntry{
+			{
+				Channel:      channel,
+				Type:         pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG,
+				Config:       msgList.config,
+				Offset:       msgList.len,
+				RetainedFrom: msgList.retainedFrom,
+			},
+		}
+		for subscriberID, offset := range msgList.committed {
+			entries = append(entries, &pb.WalEntry{
+				Channel:      channel,
+				Type:         pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET,
+				Subscription: subscriberID,
+				Offset:       offset,
+			})
+		}
+
+		for _, entry := range entries {
+			data, err := proto.Marshal(entry)
+			if err != nil {
+				slog.Error(
+					"failed to marshal data",
+					slog.Any("error", err),
+				)
+				return
+			}
+
+			if _, err = m.wal.Write(data); err != nil {
+				slog.Error(
+					"failed to write to WAL",
+					slog.Any("error", err),
+				)
+				return
+			}
+		}
+	}
+
+	if err := m.wal.Sync(); err != nil {
+		slog.Error(
+			"failed to sync WAL",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	// The WAL keeps the deleted segments open until it is closed, they are not read from again
+	for segment := m.firstSegment; segment < first; segment++ {
+		name := wal.SegmentFileName(m.walDirPath, m.walSegmentFileExt, segment)
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Error(
+				"failed to delete WAL segment",
+				slog.String("segment", name),
+				slog.Any("error", err),
+			)
+			return
+		}
+		m.firstSegment = segment + 1
+
+		slog.Info(
+			"WAL segment deleted",
+			slog.String("segment", name),
+		)
+	}
+}
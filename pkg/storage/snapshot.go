@@ -0,0 +1,357 @@
+// pkg/storage/snapshot.go
+
+package storage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rosedblabs/wal"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+const (
+	// snapshotFileExt is the extension of the snapshot files
+	snapshotFileExt = ".snapshot"
+
+	// snapshotsKept is the number of snapshots kept, the older ones are deleted once a new one is taken,
+	// so that an older snapshot is loaded if the latest one is corrupt
+	snapshotsKept = 2
+)
+
+var (
+	// errIncompleteSnapshot is returned when a snapshot ends before its end entry
+	errIncompleteSnapshot = errors.New("snapshot is incomplete")
+
+	// errCheckpointNotInWal is returned when the checkpoint a snapshot was taken at is not in the WAL, as the WAL was
+	// truncated before it or the checkpoint was lost in a crash, the records written at its position since are not in the snapshot
+	errCheckpointNotInWal = errors.New("snapshot checkpoint is not in the WAL")
+
+	// errWalNotReplayed is returned when a snapshot is taken of a storage that was not synced with the WAL on startup,
+	// the snapshot would leave out the entries of the WAL before it
+	errWalNotReplayed = errors.New("cannot snapshot a storage that was not synced on startup")
+)
+
+// isAfter reports whether the position is after the checkpoint in the WAL
+func isAfter(position *wal.ChunkPosition, checkpoint *wal.ChunkPosition) bool {
+	if position.SegmentId != checkpoint.SegmentId {
+		return position.SegmentId > checkpoint.SegmentId
+	}
+	if position.BlockNumber != checkpoint.BlockNumber {
+		return position.BlockNumber > checkpoint.BlockNumber
+	}
+	return position.ChunkOffset > checkpoint.ChunkOffset
+}
+
+// scheduleSnapshots takes a snapshot of the storage every snapshot interval, until the storage is closed
+func (m *MemoryStorage) scheduleSnapshots() {
+	if m.snapshotDirPath == "" || m.snapshotInterval <= 0 {
+		return
+	}
+
+	m.runInBackground(func() {
+		ticker := time.NewTicker(m.snapshotInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-m.closed:
+				return
+			}
+
+			if err := m.Snapshot(); err != nil {
+				slog.Error(
+					"failed to take snapshot",
+					slog.Any("error", err),
+				)
+			}
+		}
+	})
+}
+
+// Snapshot writes a snapshot of the channels, the offsets committed by the durable subscriptions and the delayed messages
+// to the snapshot directory, so that the storage is synced on startup by loading the snapshot and replaying the WAL after it.
+// A checkpoint is written to the WAL first, and the channels are copied one at a time afterwards, so that the storage is not
+// held for the whole snapshot. The entries written between the checkpoint and the copy of their channel are in the snapshot
+// as well as after the checkpoint, they are told apart from their offsets when the WAL is replayed.
+func (m *MemoryStorage) Snapshot() error {
+	if !m.replayed {
+		return errWalNotReplayed
+	}
+
+	if err := os.MkdirAll(m.snapshotDirPath, 0o755); err != nil {
+		return err
+	}
+
+	checkpoint, err := m.writeEntry(&pb.WalEntry{
+		Type: pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT,
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.writeSnapshot(checkpoint)
+}
+
+// writeSnapshot writes a snapshot of the storage taken at the checkpoint, and deletes the older snapshots
+func (m *MemoryStorage) writeSnapshot(checkpoint *wal.ChunkPosition) error {
+	name := filepath.Join(m.snapshotDirPath, fmt.Sprintf("%020d%s", time.Now().UnixNano(), snapshotFileExt))
+	file, err := os.Create(name + cleanedFileExt)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(name + cleanedFileExt)
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	var count uint64
+	write := func(entry *pb.WalEntry) error {
+		data, err := proto.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		count++
+		_, err = w.Write(appendRecord(nil, data))
+		return err
+	}
+
+	if err := write(&pb.WalEntry{
+		Type:       pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT,
+		Checkpoint: checkpoint.Encode(),
+	}); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	channels := make([]string, 0, len(m.data))
+	for channel := range m.data {
+		channels = append(channels, channel)
+	}
+	m.mu.RUnlock()
+
+	for _, channel := range channels {
+		for _, entry := range m.snapshotChannel(channel) {
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := write(&pb.WalEntry{
+		Type:   pb.WalEntryType_WAL_ENTRY_TYPE_SNAPSHOT_END,
+		Offset: count,
+	}); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := os.Rename(name+cleanedFileExt, name); err != nil {
+		return err
+	}
+
+	slog.Info(
+		"snapshot taken",
+		slog.String("snapshot", name),
+		slog.Uint64("entries", count),
+	)
+
+	// Delete the older snapshots
+	names := m.snapshots()
+	for _, name := range names[min(snapshotsKept, len(names)):] {
+		if err := os.Remove(name); err != nil {
+			slog.Error(
+				"failed to delete snapshot",
+				slog.String("snapshot", name),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// snapshotChannel returns the entries the channel is replayed from, its messages and delayed messages
+// followed by its configuration, along with its next offset, and the offsets committed to it
+func (m *MemoryStorage) snapshotChannel(channel string) []*pb.WalEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList, exists := m.data[channel]
+	if !exists {
+		return nil
+	}
+
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	entries := make([]*pb.WalEntry, 0, msgList.count+uint64(len(msgList.delayed))+uint64(len(msgList.committed))+1)
+	for iterator := msgList.head; iterator != nil; iterator = iterator.next {
+		entries = append(entries, &pb.WalEntry{
+			Channel: channel,
+			Type:    pb.WalEntryType_WAL_ENTRY_TYPE_MESSAGE,
+			Message: iterator.data,
+			Segment: iterator.segment,
+		})
+	}
+
+	delayed := append(delayedQueue(nil), msgList.delayed...)
+	sort.Slice(delayed, func(i, j int) bool { return delayed[i].seq < delayed[j].seq })
+	for _, item := range delayed {
+		entries = append(entries, &pb.WalEntry{
+			Channel: channel,
+			Type:    pb.WalEntryType_WAL_ENTRY_TYPE_DELAYED_MESSAGE,
+			Message: item.message,
+			Segment: item.segment,
+		})
+	}
+
+	entries = append(entries, &pb.WalEntry{
+		Channel:      channel,
+		Type:         pb.WalEntryType_WAL_ENTRY_TYPE_CHANNEL_CONFIG,
+		Config:       msgList.config,
+		Offset:       msgList.len,
+		RetainedFrom: msgList.retainedFrom,
+	})
+	for subscriberID, offset := range msgList.committed {
+		entries = append(entries, &pb.WalEntry{
+			Channel:      channel,
+			Type:         pb.WalEntryType_WAL_ENTRY_TYPE_SUBSCRIPTION_OFFSET,
+			Subscription: subscriberID,
+			Offset:       offset,
+		})
+	}
+
+	return entries
+}
+
+// snapshots returns the names of the snapshot files, latest first
+func (m *MemoryStorage) snapshots() []string {
+	entries, err := os.ReadDir(m.snapshotDirPath)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), snapshotFileExt) {
+			names = append(names, filepath.Join(m.snapshotDirPath, entry.Name()))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	return names
+}
+
+// loadSnapshot loads the latest valid snapshot, and returns the position of the checkpoint it was taken at, or nil if there
+// is none. A snapshot that cannot be loaded is left out for the one before it, and the storage is synced by replaying the
+// whole WAL if none can be loaded.
+func (m *MemoryStorage) loadSnapshot(state *replayState) *wal.ChunkPosition {
+	if m.snapshotDirPath == "" {
+		return nil
+	}
+
+	for _, name := range m.snapshots() {
+		checkpoint, err := m.replaySnapshot(name, state)
+		if err == nil {
+			slog.Info(
+				"snapshot loaded",
+				slog.String("snapshot", name),
+			)
+			return checkpoint
+		}
+
+		slog.Warn(
+			"failed to load snapshot",
+			slog.String("snapshot", name),
+			slog.Any("error", err),
+		)
+
+		// Start over from an empty storage
+		m.data = make(map[string]*chunkList)
+		*state = *newReplayState()
+	}
+
+	return nil
+}
+
+// replaySnapshot replays the entries of the snapshot, and returns the position of the checkpoint it was taken at
+func (m *MemoryStorage) replaySnapshot(name string, state *replayState) (*wal.ChunkPosition, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var checkpoint *wal.ChunkPosition
+	var count uint64
+	for {
+		data, err := readRecord(r)
+		if err == io.EOF {
+			return nil, errIncompleteSnapshot
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &pb.WalEntry{}
+		if err := proto.Unmarshal(data, entry); err != nil {
+			return nil, err
+		}
+		count++
+
+		switch {
+		case checkpoint == nil:
+			// The snapshot starts with its checkpoint
+			if entry.GetType() != pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT {
+				return nil, fmt.Errorf("snapshot starts with a %s entry", entry.GetType())
+			}
+
+			checkpoint = wal.DecodeChunkPosition(entry.GetCheckpoint())
+			if !m.hasCheckpoint(checkpoint) {
+				return nil, errCheckpointNotInWal
+			}
+
+		case entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_SNAPSHOT_END:
+			if entry.GetOffset() != count-1 {
+				return nil, errIncompleteSnapshot
+			}
+			return checkpoint, nil
+
+		default:
+			m.replayEntry(entry, entry.GetSegment(), state)
+		}
+	}
+}
+
+// hasCheckpoint reports whether the WAL has a checkpoint at the specified position
+func (m *MemoryStorage) hasCheckpoint(position *wal.ChunkPosition) bool {
+	if position == nil {
+		return false
+	}
+
+	data, err := readWalRecord(m.walDirPath, m.walSegmentFileExt, position)
+	if err != nil {
+		return false
+	}
+
+	entry := &pb.WalEntry{}
+	return proto.Unmarshal(data, entry) == nil && entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT
+}
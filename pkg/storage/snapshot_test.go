@@ -0,0 +1,321 @@
+// pkg/storage/snapshot_test.go
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rosedblabs/wal"
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+func openMemoryStorage(t *testing.T, walDir string, snapshotDir string) (*MemoryStorage, func()) {
+	log, err := wal.Open(wal.Options{
+		DirPath:        walDir,
+		SegmentSize:    wal.DefaultOptions.SegmentSize,
+		SegmentFileExt: ".wal",
+		Sync:           false,
+		BytesPerSync:   0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMemoryStorage(&MemoryStorageOptions{
+		Wal:               log,
+		WalDirPath:        walDir,
+		WalSegmentFileExt: ".wal",
+		WalSync:           false,
+		WalRecoveryMode:   WalRecoveryModeSkip,
+		BatchSize:         100,
+		SyncOnStartup:     true,
+		DedupWindow:       time.Minute,
+		SweepInterval:     0,
+		SnapshotDirPath:   snapshotDir,
+		SnapshotInterval:  0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, func() {
+		_ = m.Close()
+		_ = log.Close()
+	}
+}
+
+func hasPendingDelayed(m *MemoryStorage, channel string, id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgList := m.data[channel]
+	msgList.mu.Lock()
+	defer msgList.mu.Unlock()
+
+	return msgList.hasDelayed(id)
+}
+
+func TestSnapshotLoadedOnStartup(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	for _, content := range []string{"a", "b", "c"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, m.SetChannelConfig("channel", &pb.ChannelConfig{Partitions: 2}))
+	assert.NoError(t, m.CommitOffset("channel", "durable", 1))
+
+	delayed := newMessage("delayed")
+	delayed.DeliverAt = time.Now().Add(time.Hour).UnixMilli()
+	_, err := m.SaveMessage("channel", delayed)
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Snapshot())
+
+	// The WAL after the snapshot is replayed on top of it
+	_, err = m.SaveMessage("channel", newMessage("d"))
+	assert.NoError(t, err)
+	assert.NoError(t, m.CommitOffset("channel", "durable", 2))
+	closeStorage()
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	messages, _, err := m.GetMessages("channel", "durable", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, contentsOf(messages))
+	assert.Equal(t, uint64(3), messages[1].GetOffset())
+	assert.True(t, m.ChannelExists(PartitionChannel("channel", 1)))
+	assert.True(t, hasPendingDelayed(m, "channel", "delayed"))
+	closeStorage()
+
+	// The WAL before the checkpoint is not replayed, the messages it held are loaded from the snapshot
+	name := wal.SegmentFileName(walDir, ".wal", 1)
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	data[walMessageAt(t, walDir, "a")+walChunkHeaderSize+1] ^= 0xff
+	assert.NoError(t, os.WriteFile(name, data, 0o644))
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+
+	messages, _, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, contentsOf(messages))
+}
+
+func TestSnapshotCheckpointNotInWal(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	for _, content := range []string{"a", "b"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, m.Snapshot())
+	closeStorage()
+
+	// The checkpoint is lost in a crash, the message saved afterwards is written at its position
+	checkpoint := walRecordAt(t, walDir, func(entry *pb.WalEntry) bool {
+		return entry.GetType() == pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT
+	})
+	assert.NoError(t, os.Truncate(wal.SegmentFileName(walDir, ".wal", 1), checkpoint))
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	_, err := m.SaveMessage("channel", newMessage("c"))
+	assert.NoError(t, err)
+	closeStorage()
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, contentsOf(messages))
+}
+
+func TestSnapshotOverlappingWal(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	_, err := m.SaveMessage("channel", newMessage("a"))
+	assert.NoError(t, err)
+	delayed := newMessage("delayed")
+	delayed.DeliverAt = time.Now().Add(100 * time.Millisecond).UnixMilli()
+	_, err = m.SaveMessage("channel", delayed)
+	assert.NoError(t, err)
+
+	// The entries written between the checkpoint and the copy of the channel are in the snapshot too
+	checkpoint, err := m.writeEntry(&pb.WalEntry{Type: pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT})
+	assert.NoError(t, err)
+	_, err = m.SaveMessage("channel", newMessage("b"))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return !hasPendingDelayed(m, "channel", "delayed")
+	}, 2*time.Second, 10*time.Millisecond)
+	_, err = m.SaveMessage("channel", newMessage("c"))
+	assert.NoError(t, err)
+	assert.NoError(t, m.writeSnapshot(checkpoint))
+
+	_, err = m.SaveMessage("channel", newMessage("d"))
+	assert.NoError(t, err)
+	closeStorage()
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+
+	messages, last, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "delayed", "c", "d"}, contentsOf(messages))
+	assert.Equal(t, uint64(4), last)
+	assert.False(t, hasPendingDelayed(m, "channel", "delayed"))
+}
+
+func TestSnapshotDeletedAndPurgedChannels(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	assert.NoError(t, m.CreateChannel("deleted"))
+	assert.NoError(t, m.CreateChannel("purged"))
+
+	for _, content := range []string{"a", "b", "c"} {
+		_, err := m.SaveMessage("deleted", newMessage(content))
+		assert.NoError(t, err)
+		_, err = m.SaveMessage("purged", newMessage(content))
+		assert.NoError(t, err)
+	}
+
+	// The channels are deleted (and created again) and purged between the checkpoint and their copy
+	checkpoint, err := m.writeEntry(&pb.WalEntry{Type: pb.WalEntryType_WAL_ENTRY_TYPE_CHECKPOINT})
+	assert.NoError(t, err)
+	assert.NoError(t, m.DeleteChannel("deleted"))
+	assert.NoError(t, m.CreateChannel("deleted"))
+	_, err = m.SaveMessage("deleted", newMessage("x"))
+	assert.NoError(t, err)
+	assert.NoError(t, m.PurgeChannel("purged"))
+	_, err = m.SaveMessage("purged", newMessage("y"))
+	assert.NoError(t, err)
+	assert.NoError(t, m.writeSnapshot(checkpoint))
+	closeStorage()
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+
+	messages, last, err := m.GetMessages("deleted", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, contentsOf(messages))
+	assert.Equal(t, uint64(0), last)
+
+	messages, last, err = m.GetMessages("purged", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"y"}, contentsOf(messages))
+	assert.Equal(t, uint64(3), last)
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	for _, content := range []string{"a", "b"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, m.Snapshot())
+	_, err := m.SaveMessage("channel", newMessage("c"))
+	assert.NoError(t, err)
+	assert.NoError(t, m.Snapshot())
+	_, err = m.SaveMessage("channel", newMessage("d"))
+	assert.NoError(t, err)
+	closeStorage()
+
+	// Corrupt the latest snapshot, the one before it is loaded instead
+	names := m.snapshots()
+	assert.Len(t, names, 2)
+	data, err := os.ReadFile(names[0])
+	assert.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	assert.NoError(t, os.WriteFile(names[0], data, 0o644))
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, contentsOf(messages))
+	closeStorage()
+
+	// Without a snapshot that can be loaded, the whole WAL is replayed
+	assert.NoError(t, os.Truncate(names[1], 10))
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+
+	messages, _, err = m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, contentsOf(messages))
+
+	_, err = os.Stat(filepath.Join(walDir, "000000001.wal"))
+	assert.NoError(t, err)
+}
+
+func TestSnapshotWithoutReplay(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	assert.NoError(t, m.CreateChannel("channel"))
+	_, err := m.SaveMessage("channel", newMessage("a"))
+	assert.NoError(t, err)
+	closeStorage()
+
+	// A snapshot of a storage that was not synced on startup would leave out the message saved before
+	m, closeStorage = openSegmentedMemoryStorage(t, walDir, false)
+	m.snapshotDirPath = snapshotDir
+	assert.Equal(t, errWalNotReplayed, m.Snapshot())
+	closeStorage()
+
+	snapshots, err := os.ReadDir(snapshotDir)
+	assert.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+	assert.NoError(t, m.Snapshot())
+
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, contentsOf(messages))
+}
+
+func TestSnapshotOnClose(t *testing.T) {
+	walDir, snapshotDir := t.TempDir(), t.TempDir()
+	m, closeStorage := openMemoryStorage(t, walDir, snapshotDir)
+	m.snapshotInterval = time.Hour
+	m.scheduleSnapshots()
+
+	assert.NoError(t, m.CreateChannel("channel"))
+	_, err := m.SaveMessage("channel", newMessage("a"))
+	assert.NoError(t, err)
+
+	// The snapshots stop once the storage is closed, which takes a final one
+	assert.NoError(t, m.Close())
+	assert.Len(t, m.snapshots(), 1)
+	assert.NoError(t, m.Close())
+	assert.Len(t, m.snapshots(), 1)
+	closeStorage()
+
+	// The message is loaded from the snapshot
+	name := wal.SegmentFileName(walDir, ".wal", 1)
+	data, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	data[walMessageAt(t, walDir, "a")+walChunkHeaderSize+1] ^= 0xff
+	assert.NoError(t, os.WriteFile(name, data, 0o644))
+
+	m, closeStorage = openMemoryStorage(t, walDir, snapshotDir)
+	defer closeStorage()
+
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, contentsOf(messages))
+}
@@ -5,6 +5,8 @@ package storage
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
 )
@@ -15,12 +17,22 @@ const (
 
 	// OffsetLatest is the offset to start reading messages from the latest
 	OffsetLatest uint64 = ^uint64(0)
+
+	// PartitionSeparator separates the name of a channel from the number of one of its partitions
+	PartitionSeparator = "#"
 )
 
 var (
 	// ErrInvalidOffset is returned when an invalid offset is provided
 	ErrInvalidOffset = errors.New("error: invalid offset provided for message retrieval")
 
+	// ErrOffsetOutOfRange is returned when the messages a subscriber has to read next have been evicted by the retention of the channel
+	ErrOffsetOutOfRange = errors.New("error: offset out of range")
+
+	// ErrStaleSequence is returned when a producer publishes a message with a sequence number that is not above the highest one it saved,
+	// and the message is not a duplicate of a message saved within the deduplication window
+	ErrStaleSequence = errors.New("error: sequence number not above the last one saved by the producer")
+
 	// ErrInternal is returned when storage is unavailable
 	ErrInternal = errors.New("error: storage unavailable")
 )
@@ -28,8 +40,60 @@ var (
 // Storage defines the interface for message storage mechanisms
 type Storage interface {
 	SaveMessage(string, *pb.Message) (uint64, error)
+	SaveMessages([]*pb.WalEntry) ([]uint64, error)
 	GetMessages(string, string, uint64) ([]*pb.Message, uint64, error)
 	CreateChannel(string) error
+	DeleteChannel(string) error
+	PurgeChannel(string) error
 	ChannelExists(string) bool
+	GetChannels() []string
+	WatchChannel(string) (<-chan struct{}, error)
+	WatchChannels() <-chan struct{}
+	SetChannelConfig(string, *pb.ChannelConfig) error
+	GetChannelConfig(string) (*pb.ChannelConfig, error)
+	GetChannelStats(string) (*pb.ChannelStats, error)
 	RemoveChannelFromSubscriberMap(string, string)
+	CommitOffset(string, string, uint64) error
+	GetOffsetByTime(string, int64) (uint64, error)
+}
+
+// PartitionChannel returns the name under which a partition of a channel is stored,
+// the first partition of a channel is stored under the name of the channel itself
+func PartitionChannel(channel string, partition uint32) string {
+	if partition == 0 {
+		return channel
+	}
+
+	return channel + PartitionSeparator + strconv.FormatUint(uint64(partition), 10)
+}
+
+// ParsePartitionChannel returns the channel and the partition stored under the name
+func ParsePartitionChannel(name string) (string, uint32) {
+	i := strings.LastIndex(name, PartitionSeparator)
+	if i < 0 {
+		return name, 0
+	}
+
+	partition, err := strconv.ParseUint(name[i+len(PartitionSeparator):], 10, 32)
+	if err != nil {
+		return name, 0
+	}
+
+	return name[:i], uint32(partition)
+}
+
+// batchSizeOf returns the number of messages read at once from a channel with the configuration,
+// the batch size of the channel if it has one or else the batch size of the storage
+func batchSizeOf(config *pb.ChannelConfig, batchSize uint64) uint64 {
+	if config.GetBatchSize() > 0 {
+		return config.GetBatchSize()
+	}
+
+	return batchSize
+}
+
+// syncsPublishes reports whether the messages published to a channel with the configuration are synced
+// to disk before they are acknowledged, whether the storage syncs its writes or not
+func syncsPublishes(config *pb.ChannelConfig) bool {
+	return config.GetDurability() == pb.Durability_DURABILITY_SYNC
 }
@@ -0,0 +1,754 @@
+// pkg/storage/storage_test.go
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rosedblabs/wal"
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// engine opens a Storage implementation in a directory, along with the function closing it,
+// the storage opened again in the same directory has the same contents
+type engine struct {
+	name string
+	open func(t *testing.T, dir string) (Storage, func())
+}
+
+var engines = []engine{
+	{
+		name: "memory",
+		open: func(t *testing.T, dir string) (Storage, func()) {
+			log, err := wal.Open(wal.Options{
+				DirPath:        dir,
+				SegmentSize:    wal.DefaultOptions.SegmentSize,
+				SegmentFileExt: ".wal",
+				Sync:           false,
+				BytesPerSync:   0,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := NewMemoryStorage(&MemoryStorageOptions{
+				Wal:               log,
+				WalDirPath:        dir,
+				WalSegmentFileExt: ".wal",
+				WalSync:           false,
+				WalRecoveryMode:   WalRecoveryModeSkip,
+				BatchSize:         3,
+				SyncOnStartup:     true,
+				DedupWindow:       time.Minute,
+				SweepInterval:     0,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return m, func() {
+				_ = m.Close()
+				_ = log.Close()
+			}
+		},
+	},
+	{
+		name: "disk",
+		open: func(t *testing.T, dir string) (Storage, func()) {
+			d, err := NewDiskStorage(&DiskStorageOptions{
+				DirPath:       dir,
+				SegmentSize:   256,
+				Sync:          false,
+				BatchSize:     3,
+				DedupWindow:   time.Minute,
+				SweepInterval: 0,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return d, d.Close
+		},
+	},
+}
+
+// forEachEngine runs the test against every Storage implementation
+func forEachEngine(t *testing.T, test func(t *testing.T, open func() (Storage, func()))) {
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			dir := t.TempDir()
+			test(t, func() (Storage, func()) {
+				return e.open(t, dir)
+			})
+		})
+	}
+}
+
+func newMessage(content string) *pb.Message {
+	return &pb.Message{
+		Id:        content,
+		Content:   []byte(content),
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+func contentsOf(messages []*pb.Message) []string {
+	contents := make([]string, 0, len(messages))
+	for _, message := range messages {
+		contents = append(contents, string(message.GetContent()))
+	}
+	return contents
+}
+
+func TestStorageSaveAndGetMessages(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+		defer closeStorage()
+
+		// Messages are only saved to the channels created beforehand
+		_, err := s.SaveMessage("channel", newMessage("a"))
+		assert.Error(t, err)
+		assert.False(t, s.ChannelExists("channel"))
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		for i, content := range []string{"a", "b", "c", "d", "e"} {
+			offset, err := s.SaveMessage("channel", newMessage(content))
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(i), offset)
+		}
+		assert.True(t, s.ChannelExists("channel"))
+
+		// The messages are read in batches of the batch size
+		messages, last, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, contentsOf(messages))
+		assert.Equal(t, uint64(2), last)
+
+		messages, last, err = s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d", "e"}, contentsOf(messages))
+		assert.Equal(t, uint64(4), last)
+
+		_, _, err = s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.Equal(t, ErrInvalidOffset, err)
+
+		// Another subscriber starts from its own offset
+		messages, _, err = s.GetMessages("channel", "other", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d", "e"}, contentsOf(messages))
+
+		_, _, err = s.GetMessages("channel", "invalid", 5)
+		assert.Equal(t, ErrInvalidOffset, err)
+
+		_, _, err = s.GetMessages("missing", "subscriber", OffsetBeginning)
+		assert.Error(t, err)
+
+		// A subscriber starting from the latest message only reads the messages saved afterwards
+		_, last, err = s.GetMessages("channel", "latest", OffsetLatest)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(4), last)
+
+		_, err = s.SaveMessage("channel", newMessage("f"))
+		assert.NoError(t, err)
+
+		messages, last, err = s.GetMessages("channel", "latest", OffsetLatest)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"f"}, contentsOf(messages))
+		assert.Equal(t, uint64(5), last)
+
+		// The cursor of a removed subscriber is forgotten
+		s.RemoveChannelFromSubscriberMap("channel", "latest")
+		messages, _, err = s.GetMessages("channel", "latest", 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"e", "f"}, contentsOf(messages))
+	})
+}
+
+func TestStorageSaveMessages(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+		defer closeStorage()
+
+		assert.NoError(t, s.CreateChannel("first"))
+		assert.NoError(t, s.CreateChannel("second"))
+		_, err := s.SaveMessage("first", newMessage("a"))
+		assert.NoError(t, err)
+
+		// Nothing is saved when any of the channels does not exist
+		_, err = s.SaveMessages([]*pb.WalEntry{
+			{Channel: "first", Message: newMessage("x")},
+			{Channel: "missing", Message: newMessage("y")},
+		})
+		assert.Error(t, err)
+		assert.False(t, s.ChannelExists("missing"))
+
+		offsets, err := s.SaveMessages([]*pb.WalEntry{
+			{Channel: "first", Message: newMessage("b")},
+			{Channel: "second", Message: newMessage("c")},
+			{Channel: "first", Message: newMessage("d")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1, 0, 2}, offsets)
+
+		messages, _, err := s.GetMessages("first", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "d"}, contentsOf(messages))
+
+		messages, _, err = s.GetMessages("second", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c"}, contentsOf(messages))
+	})
+}
+
+func TestStorageChannels(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+		defer closeStorage()
+
+		created := s.WatchChannels()
+		assert.NoError(t, s.CreateChannel("b"))
+		assert.NoError(t, s.CreateChannel("a.b/c"))
+		assert.NoError(t, s.CreateChannel(PartitionChannel("b", 1)))
+		assert.NoError(t, s.CreateChannel("b"))
+
+		select {
+		case <-created:
+		default:
+			t.Fatal("channel creation was not notified")
+		}
+		assert.Equal(t, []string{"a.b/c", "b"}, s.GetChannels())
+
+		saved, err := s.WatchChannel("b")
+		assert.NoError(t, err)
+		_, err = s.SaveMessage("b", newMessage("a"))
+		assert.NoError(t, err)
+
+		select {
+		case <-saved:
+		default:
+			t.Fatal("saved message was not notified")
+		}
+
+		_, err = s.WatchChannel("missing")
+		assert.Error(t, err)
+
+		config := &pb.ChannelConfig{Partitions: 2}
+		assert.NoError(t, s.SetChannelConfig("b", config))
+		got, err := s.GetChannelConfig("b")
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(2), got.GetPartitions())
+
+		assert.Error(t, s.SetChannelConfig("missing", config))
+	})
+}
+
+func TestStorageGetOffsetByTime(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+		defer closeStorage()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		for i, createdAt := range []int64{100, 200, 200, 300} {
+			message := newMessage(string(rune('a' + i)))
+			message.CreatedAt = createdAt
+			_, err := s.SaveMessage("channel", message)
+			assert.NoError(t, err)
+		}
+
+		for timestamp, expected := range map[int64]uint64{50: 0, 100: 0, 150: 1, 200: 1, 300: 3, 400: 4} {
+			offset, err := s.GetOffsetByTime("channel", timestamp)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, offset, "timestamp %d", timestamp)
+		}
+
+		_, err := s.GetOffsetByTime("missing", 100)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorageDeduplication(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		message := newMessage("a")
+		message.IdempotencyKey = "key"
+		_, err := s.SaveMessage("channel", message)
+		assert.NoError(t, err)
+		_, err = s.SaveMessage("channel", newMessage("b"))
+		assert.NoError(t, err)
+
+		duplicate := newMessage("c")
+		duplicate.IdempotencyKey = "key"
+		offset, err := s.SaveMessage("channel", duplicate)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), offset)
+		assert.Equal(t, "a", duplicate.GetId())
+
+		// The idempotency keys are remembered across restarts
+		closeStorage()
+		s, closeStorage = open()
+		defer closeStorage()
+
+		duplicate = newMessage("d")
+		duplicate.IdempotencyKey = "key"
+		offsets, err := s.SaveMessages([]*pb.WalEntry{
+			{Channel: "channel", Message: duplicate},
+			{Channel: "channel", Message: newMessage("e")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{0, 2}, offsets)
+	})
+}
+
+func TestStorageProducerSequences(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		produce := func(content string, sequence uint64) *pb.Message {
+			message := newMessage(content)
+			message.IdempotencyKey = ProducerKey("producer", sequence)
+			return message
+		}
+
+		_, err := s.SaveMessage("channel", produce("a", 1))
+		assert.NoError(t, err)
+		_, err = s.SaveMessage("channel", produce("b", 3))
+		assert.NoError(t, err)
+
+		// A retry of a saved message is deduplicated, a message below the last sequence number is refused
+		duplicate := produce("c", 1)
+		offset, err := s.SaveMessage("channel", duplicate)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), offset)
+		assert.Equal(t, "a", duplicate.GetId())
+		_, err = s.SaveMessage("channel", produce("d", 2))
+		assert.Equal(t, ErrStaleSequence, err)
+
+		// A client idempotency key never collides with the key of a producer
+		client := newMessage("e")
+		client.IdempotencyKey = "producer:3"
+		offset, err = s.SaveMessage("channel", client)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), offset)
+
+		// The sequence numbers are remembered across restarts, a batch with a stale message is refused
+		closeStorage()
+		s, closeStorage = open()
+		defer closeStorage()
+
+		_, err = s.SaveMessages([]*pb.WalEntry{
+			{Channel: "channel", Message: produce("f", 4)},
+			{Channel: "channel", Message: produce("g", 2)},
+		})
+		assert.Equal(t, ErrStaleSequence, err)
+		_, err = s.SaveMessages([]*pb.WalEntry{
+			{Channel: "channel", Message: produce("h", 5)},
+			{Channel: "channel", Message: produce("i", 5)},
+			{Channel: "channel", Message: produce("j", 4)},
+		})
+		assert.Equal(t, ErrStaleSequence, err)
+
+		offsets, err := s.SaveMessages([]*pb.WalEntry{
+			{Channel: "channel", Message: produce("k", 3)},
+			{Channel: "channel", Message: produce("l", 4)},
+			{Channel: "channel", Message: produce("m", 5)},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1, 3, 4}, offsets)
+
+		messages, _, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "e"}, contentsOf(messages))
+	})
+}
+
+func TestParseProducerKey(t *testing.T) {
+	producerID, sequence, ok := ParseProducerKey(ProducerKey("producer:1", 7))
+	assert.True(t, ok)
+	assert.Equal(t, "producer:1", producerID)
+	assert.Equal(t, uint64(7), sequence)
+
+	for _, key := range []string{"producer:7", "\x00prod:producer", "\x00prod:producer:x", "\x00prod:7"} {
+		_, _, ok := ParseProducerKey(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestIdempotencyKeysForget(t *testing.T) {
+	k := newIdempotencyKeys()
+	save := func(key string, createdAt int64) *pb.Message {
+		message := &pb.Message{Id: key, IdempotencyKey: key, CreatedAt: createdAt}
+		k.remember(message)
+		return message
+	}
+
+	save(ProducerKey("producer", 1), 100)
+	save("key", 101)
+	save(ProducerKey("other", 1), 102)
+	last := save(ProducerKey("producer", 2), 103)
+
+	// The producers are forgotten along with their last message, the messages forgotten are released
+	k.forget(103)
+	assert.Equal(t, map[string]*pb.Message{last.GetIdempotencyKey(): last}, k.dedup)
+	assert.Equal(t, []*pb.Message{last}, k.dedupOrder)
+	assert.Equal(t, map[string]producerSequence{"producer": {sequence: 2, message: last}}, k.sequences)
+
+	assert.True(t, k.stale(&pb.Message{IdempotencyKey: ProducerKey("producer", 2)}, nil))
+	assert.False(t, k.stale(&pb.Message{IdempotencyKey: ProducerKey("other", 1)}, nil))
+
+	k.forget(104)
+	assert.Empty(t, k.dedup)
+	assert.Empty(t, k.dedupOrder)
+	assert.Empty(t, k.sequences)
+}
+
+func TestStorageRestart(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		assert.NoError(t, s.CreateChannel("empty"))
+		for _, content := range []string{"a", "b", "c", "d", "e"} {
+			_, err := s.SaveMessage("channel", newMessage(content))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, s.SetChannelConfig("channel", &pb.ChannelConfig{Partitions: 1, RetentionMessages: 100}))
+		assert.NoError(t, s.CommitOffset("channel", "durable", 2))
+		assert.NoError(t, s.CommitOffset("channel", "durable", 3))
+
+		closeStorage()
+		s, closeStorage = open()
+		defer closeStorage()
+
+		// The messages, the configuration and the committed offsets survive the restart, and so do the empty channels
+		assert.True(t, s.ChannelExists("channel"))
+		assert.True(t, s.ChannelExists("empty"))
+		config, err := s.GetChannelConfig("channel")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), config.GetRetentionMessages())
+
+		messages, _, err := s.GetMessages("channel", "durable", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d", "e"}, contentsOf(messages))
+
+		offset, err := s.SaveMessage("channel", newMessage("f"))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), offset)
+
+		messages, _, err = s.GetMessages("channel", "subscriber", 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"e", "f"}, contentsOf(messages))
+	})
+}
+
+func TestStorageDelayedAndExpiredMessages(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+		defer closeStorage()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		delayed := newMessage("delayed")
+		delayed.DeliverAt = time.Now().Add(200 * time.Millisecond).UnixMilli()
+		_, err := s.SaveMessage("channel", delayed)
+		assert.NoError(t, err)
+
+		expired := newMessage("expired")
+		expired.ExpiresAt = time.Now().Add(-time.Second).UnixMilli()
+		_, err = s.SaveMessage("channel", expired)
+		assert.NoError(t, err)
+
+		_, err = s.SaveMessage("channel", newMessage("a"))
+		assert.NoError(t, err)
+
+		// The expired message is skipped, the delayed message is hidden until it is due
+		messages, _, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, contentsOf(messages))
+
+		assert.Eventually(t, func() bool {
+			messages, last, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+			return err == nil && len(messages) == 1 && string(messages[0].GetContent()) == "delayed" && last == 2
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+}
+
+func TestStorageDelayedMessageRestart(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		_, err := s.SaveMessage("channel", newMessage("a"))
+		assert.NoError(t, err)
+		delayed := newMessage("delayed")
+		delayed.DeliverAt = time.Now().Add(300 * time.Millisecond).UnixMilli()
+		_, err = s.SaveMessage("channel", delayed)
+		assert.NoError(t, err)
+		closeStorage()
+
+		// The delayed message that is not due yet when the storage restarts is still hidden, and appended once due
+		s, closeStorage = open()
+		messages, _, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, contentsOf(messages))
+
+		assert.Eventually(t, func() bool {
+			messages, last, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+			return err == nil && len(messages) == 1 && string(messages[0].GetContent()) == "delayed" && last == 1
+		}, 2*time.Second, 20*time.Millisecond)
+		closeStorage()
+
+		// The message appended keeps its offset after another restart, and is not appended again
+		s, closeStorage = open()
+		defer closeStorage()
+
+		messages, _, err = s.GetMessages("channel", "other", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "delayed"}, contentsOf(messages))
+		assert.Equal(t, uint64(1), messages[1].GetOffset())
+
+		offset, err := s.SaveMessage("channel", newMessage("b"))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), offset)
+	})
+}
+
+func TestStorageDeleteChannel(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		assert.NoError(t, s.CreateChannel("other"))
+		for _, content := range []string{"a", "b"} {
+			_, err := s.SaveMessage("channel", newMessage(content))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, s.SetChannelConfig("channel", &pb.ChannelConfig{Partitions: 2}))
+		assert.NoError(t, s.CreateChannel(PartitionChannel("channel", 1)))
+		_, err := s.SaveMessage(PartitionChannel("channel", 1), newMessage("c"))
+		assert.NoError(t, err)
+		assert.NoError(t, s.CommitOffset("channel", "durable", 1))
+		_, err = s.SaveMessage("other", newMessage("d"))
+		assert.NoError(t, err)
+
+		// The subscribers waiting for new messages are woken up
+		deleted, err := s.WatchChannel("channel")
+		assert.NoError(t, err)
+		assert.NoError(t, s.DeleteChannel("channel"))
+		select {
+		case <-deleted:
+		default:
+			t.Fatal("channel deletion was not notified")
+		}
+
+		assert.False(t, s.ChannelExists("channel"))
+		assert.False(t, s.ChannelExists(PartitionChannel("channel", 1)))
+		assert.Equal(t, []string{"other"}, s.GetChannels())
+		assert.Error(t, s.DeleteChannel("channel"))
+
+		// The channel is not brought back by a restart
+		closeStorage()
+		s, closeStorage = open()
+
+		assert.False(t, s.ChannelExists("channel"))
+		assert.False(t, s.ChannelExists(PartitionChannel("channel", 1)))
+		assert.Equal(t, []string{"other"}, s.GetChannels())
+
+		// A channel created again under the same name starts out empty, without the cursors of the deleted one
+		assert.NoError(t, s.CreateChannel("channel"))
+		offset, err := s.SaveMessage("channel", newMessage("e"))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), offset)
+
+		closeStorage()
+		s, closeStorage = open()
+		defer closeStorage()
+
+		config, err := s.GetChannelConfig("channel")
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0), config.GetPartitions())
+
+		messages, _, err := s.GetMessages("channel", "durable", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"e"}, contentsOf(messages))
+	})
+}
+
+func TestStoragePurgeChannel(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		for _, content := range []string{"a", "b", "c"} {
+			_, err := s.SaveMessage("channel", newMessage(content))
+			assert.NoError(t, err)
+		}
+		delayed := newMessage("delayed")
+		delayed.DeliverAt = time.Now().Add(200 * time.Millisecond).UnixMilli()
+		_, err := s.SaveMessage("channel", delayed)
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.SetChannelConfig("channel", &pb.ChannelConfig{Partitions: 2, RetentionMessages: 100}))
+		assert.NoError(t, s.CreateChannel(PartitionChannel("channel", 1)))
+		_, err = s.SaveMessage(PartitionChannel("channel", 1), newMessage("x"))
+		assert.NoError(t, err)
+		assert.NoError(t, s.CommitOffset("channel", "durable", 1))
+
+		messages, _, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, contentsOf(messages))
+
+		assert.NoError(t, s.PurgeChannel("channel"))
+		assert.Error(t, s.PurgeChannel("missing"))
+
+		// The messages of every partition are dropped, the channel and its configuration are kept
+		assert.True(t, s.ChannelExists("channel"))
+		config, err := s.GetChannelConfig("channel")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), config.GetRetentionMessages())
+
+		_, _, err = s.GetMessages("channel", "fresh", OffsetBeginning)
+		assert.ErrorIs(t, err, ErrInvalidOffset)
+		_, _, err = s.GetMessages(PartitionChannel("channel", 1), "fresh", OffsetBeginning)
+		assert.ErrorIs(t, err, ErrInvalidOffset)
+
+		// The offsets of the messages dropped are not given out again, and the subscribers move on to the next messages
+		offset, err := s.SaveMessage("channel", newMessage("d"))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), offset)
+
+		messages, _, err = s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d"}, contentsOf(messages))
+
+		// The messages dropped, the delayed one included, are not brought back by a restart
+		closeStorage()
+		s, closeStorage = open()
+		defer closeStorage()
+
+		time.Sleep(300 * time.Millisecond)
+		messages, _, err = s.GetMessages("channel", "fresh", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d"}, contentsOf(messages))
+
+		// The durable subscription resumes after the messages dropped, it has not fallen behind
+		messages, _, err = s.GetMessages("channel", "durable", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d"}, contentsOf(messages))
+
+		offset, err = s.SaveMessage("channel", newMessage("e"))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(4), offset)
+	})
+}
+
+func TestStorageGetChannelStats(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		assert.NoError(t, s.CreateChannel("channel"))
+		stats, err := s.GetChannelStats("channel")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), stats.GetMessageCount())
+		assert.Equal(t, uint64(0), stats.GetNextOffset())
+		assert.Equal(t, uint64(0), stats.GetSizeBytes())
+
+		for i, content := range []string{"a", "b", "c"} {
+			message := newMessage(content)
+			message.CreatedAt = int64(100 + i)
+			_, err := s.SaveMessage("channel", message)
+			assert.NoError(t, err)
+		}
+		delayed := newMessage("delayed")
+		delayed.DeliverAt = time.Now().Add(time.Hour).UnixMilli()
+		_, err = s.SaveMessage("channel", delayed)
+		assert.NoError(t, err)
+
+		// The stats are the same once the storage is restarted
+		for i := 0; i < 2; i++ {
+			stats, err = s.GetChannelStats("channel")
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(3), stats.GetMessageCount())
+			assert.Equal(t, uint64(0), stats.GetFirstOffset())
+			assert.Equal(t, uint64(2), stats.GetLastOffset())
+			assert.Equal(t, int64(100), stats.GetFirstCreatedAt())
+			assert.Equal(t, int64(102), stats.GetLastCreatedAt())
+			assert.Equal(t, uint64(3), stats.GetNextOffset())
+			assert.Equal(t, uint64(1), stats.GetDelayedMessageCount())
+			assert.Greater(t, stats.GetSizeBytes(), uint64(0))
+
+			closeStorage()
+			s, closeStorage = open()
+		}
+		defer closeStorage()
+
+		// A purged channel has no messages left, but keeps its next offset
+		assert.NoError(t, s.PurgeChannel("channel"))
+		stats, err = s.GetChannelStats("channel")
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), stats.GetMessageCount())
+		assert.Equal(t, uint64(3), stats.GetNextOffset())
+		assert.Equal(t, uint64(0), stats.GetDelayedMessageCount())
+
+		_, err = s.GetChannelStats("missing")
+		assert.Error(t, err)
+	})
+}
+
+func TestStorageChannelBatchSizeAndDurability(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, open func() (Storage, func())) {
+		s, closeStorage := open()
+
+		// The partitions of a channel share its batch size and durability
+		config := &pb.ChannelConfig{
+			Partitions: 2,
+			BatchSize:  2,
+			Durability: pb.Durability_DURABILITY_SYNC,
+		}
+		assert.NoError(t, s.CreateChannel("channel"))
+		assert.NoError(t, s.CreateChannel(PartitionChannel("channel", 1)))
+		assert.NoError(t, s.SetChannelConfig("channel", config))
+		assert.NoError(t, s.CreateChannel("other"))
+
+		for _, content := range []string{"a", "b", "c"} {
+			_, err := s.SaveMessage("channel", newMessage(content))
+			assert.NoError(t, err)
+		}
+		_, err := s.SaveMessages([]*pb.WalEntry{
+			{Channel: PartitionChannel("channel", 1), Message: newMessage("d")},
+			{Channel: PartitionChannel("channel", 1), Message: newMessage("e")},
+			{Channel: PartitionChannel("channel", 1), Message: newMessage("f")},
+			{Channel: "other", Message: newMessage("g")},
+		})
+		assert.NoError(t, err)
+		closeStorage()
+
+		// The batch size of the channel replaces the batch size of the storage, across restarts
+		s, closeStorage = open()
+		defer closeStorage()
+
+		messages, _, err := s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, contentsOf(messages))
+		messages, _, err = s.GetMessages(PartitionChannel("channel", 1), "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"d", "e"}, contentsOf(messages))
+
+		// The configuration can be changed, the new batch size applies to the next reads
+		config = &pb.ChannelConfig{
+			Partitions: 2,
+			BatchSize:  0,
+			Durability: pb.Durability_DURABILITY_DEFAULT,
+		}
+		assert.NoError(t, s.SetChannelConfig("channel", config))
+		for _, content := range []string{"h", "i", "j"} {
+			_, err := s.SaveMessage("channel", newMessage(content))
+			assert.NoError(t, err)
+		}
+		messages, _, err = s.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c", "h", "i"}, contentsOf(messages))
+	})
+}
@@ -0,0 +1,504 @@
+// pkg/storage/wal_recovery.go
+
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/rosedblabs/wal"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+// WalRecoveryMode is how the storage recovers from the damaged records of the WAL on startup
+type WalRecoveryMode string
+
+const (
+	// WalRecoveryModeTruncate truncates the WAL at the first damaged record, the records after it are lost
+	WalRecoveryModeTruncate WalRecoveryMode = "truncate"
+
+	// WalRecoveryModeSkip skips the damaged records, the records after them are kept
+	WalRecoveryModeSkip WalRecoveryMode = "skip"
+
+	// WalRecoveryModeRefuse refuses to start as long as the WAL has damaged records
+	WalRecoveryModeRefuse WalRecoveryMode = "refuse"
+)
+
+const (
+	// walBlockSize is the size of the blocks of the WAL segments, a chunk never spans two blocks
+	walBlockSize = 32 * wal.KB
+
+	// walChunkHeaderSize is the size of the header of a chunk of the WAL, its CRC-32 checksum followed by its length and its type
+	walChunkHeaderSize = 7
+)
+
+var (
+	// ErrWalCorrupt is returned when the WAL has damaged records and the recovery mode refuses to start
+	ErrWalCorrupt = errors.New("error: WAL is corrupt")
+
+	// ErrUnknownWalRecoveryMode is returned when the recovery mode of the WAL is not a known one
+	ErrUnknownWalRecoveryMode = errors.New("error: unknown WAL recovery mode")
+
+	// errTornChunk is the damage of a chunk that runs past the end of its block or of its segment
+	errTornChunk = errors.New("chunk is torn")
+
+	// errChunkChecksum is the damage of a chunk whose checksum does not match
+	errChunkChecksum = errors.New("chunk checksum mismatch")
+
+	// errChunkOutOfOrder is the damage of a chunk that does not follow the chunks before it
+	errChunkOutOfOrder = errors.New("chunk is out of order")
+
+	// errUndecodableRecord is the damage of a record that is whole but cannot be decoded
+	errUndecodableRecord = errors.New("record cannot be decoded")
+
+	// errNoWalRecord is returned when the WAL does not have a record at a position
+	errNoWalRecord = errors.New("no WAL record at the position")
+)
+
+// walDamage is a damaged range of a WAL segment, starting at the first chunk of the first record lost, along with
+// the number of records lost in it. The number of records lost is a lower bound, as the records after a chunk whose
+// length is corrupt cannot be told apart up to the end of its block.
+type walDamage struct {
+	segment wal.SegmentID
+	offset  int64
+	size    int64
+	records uint64
+	tail    bool
+	reason  error
+}
+
+// attrs returns the attributes the damaged range is logged with
+func (d *walDamage) attrs() []any {
+	return []any{
+		slog.Uint64("segment", uint64(d.segment)),
+		slog.Int64("block", d.offset/walBlockSize),
+		slog.Int64("chunk_offset", d.offset%walBlockSize),
+		slog.Int64("bytes", d.size),
+		slog.Uint64("records", d.records),
+		slog.Any("reason", d.reason),
+	}
+}
+
+// walSegments returns the IDs of the segments of the WAL in the directory, in order
+func walSegments(dirPath string, ext string) ([]wal.SegmentID, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	segments := make([]wal.SegmentID, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		var id wal.SegmentID
+		if _, err := fmt.Sscanf(entry.Name(), "%d"+ext, &id); err != nil {
+			continue
+		}
+		segments = append(segments, id)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+
+	return segments, nil
+}
+
+// walReader reads the records of the WAL segments in order straight from their files, and checks the checksum, the bounds
+// and the order of every chunk. The damaged ranges of the segments are skipped, the reader resuming at the next block,
+// and reported once over. Unlike the reader of the WAL, it neither stops nor panics at the first damaged chunk.
+type walReader struct {
+	dirPath    string
+	ext        string
+	segments   []wal.SegmentID
+	current    int
+	file       *os.File
+	size       int64
+	offset     int64
+	block      []byte
+	blockStart int64
+	damage     *walDamage
+	onDamage   func(*walDamage)
+}
+
+// newWalReader initializes a reader of the WAL segments in the directory, from the specified segment on
+func newWalReader(dirPath string, ext string, from wal.SegmentID, onDamage func(*walDamage)) (*walReader, error) {
+	segments, err := walSegments(dirPath, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	i := sort.Search(len(segments), func(i int) bool { return segments[i] >= from })
+	return &walReader{
+		dirPath:    dirPath,
+		ext:        ext,
+		segments:   segments[i:],
+		current:    -1,
+		file:       nil,
+		size:       0,
+		offset:     0,
+		block:      make([]byte, walBlockSize),
+		blockStart: -1,
+		damage:     nil,
+		onDamage:   onDamage,
+	}, nil
+}
+
+// Next returns the next record of the WAL and its position, or io.EOF once every segment has been read
+func (r *walReader) Next() ([]byte, *wal.ChunkPosition, error) {
+	for {
+		if r.file == nil {
+			if r.current+1 >= len(r.segments) {
+				return nil, nil, io.EOF
+			}
+			if err := r.open(r.current + 1); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		data, position, err := r.next()
+		if err != io.EOF {
+			return data, position, err
+		}
+
+		// The damaged range at the end of the segment is over
+		r.report()
+		_ = r.file.Close()
+		r.file = nil
+	}
+}
+
+// open opens the segment at the specified index to be read from its start
+func (r *walReader) open(i int) error {
+	file, err := os.Open(wal.SegmentFileName(r.dirPath, r.ext, r.segments[i]))
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.current, r.file, r.size, r.offset, r.blockStart = i, file, info.Size(), 0, -1
+	return nil
+}
+
+// next returns the next record of the current segment, or io.EOF once it has been read
+func (r *walReader) next() ([]byte, *wal.ChunkPosition, error) {
+	var data []byte
+	start := int64(-1)
+	for r.offset < r.size {
+		// The end of a block too short for a chunk header is padding
+		blockOffset := r.offset % walBlockSize
+		if blockOffset+walChunkHeaderSize >= walBlockSize {
+			r.offset += walBlockSize - blockOffset
+			continue
+		}
+
+		if err := r.load(r.offset - blockOffset); err != nil {
+			return nil, nil, err
+		}
+		nextBlock := min(r.offset-blockOffset+walBlockSize, r.size)
+
+		var reason error
+		var chunkType byte
+		var end int64
+		if blockOffset+walChunkHeaderSize > int64(len(r.block)) {
+			reason = errTornChunk
+		} else {
+			header := r.block[blockOffset : blockOffset+walChunkHeaderSize]
+			chunkType = header[6]
+			end = blockOffset + walChunkHeaderSize + int64(binary.LittleEndian.Uint16(header[4:6]))
+
+			switch {
+			case end > int64(len(r.block)):
+				reason = errTornChunk
+			case crc32.ChecksumIEEE(r.block[blockOffset+4:end]) != binary.LittleEndian.Uint32(header[:4]):
+				reason = errChunkChecksum
+			case start < 0 && chunkType != wal.ChunkTypeFull && chunkType != wal.ChunkTypeFirst,
+				start >= 0 && chunkType != wal.ChunkTypeMiddle && chunkType != wal.ChunkTypeLast:
+				reason = errChunkOutOfOrder
+			}
+		}
+
+		// A record that starts before the last chunk of the record being read is over, only the latter is lost
+		if reason == errChunkOutOfOrder && start >= 0 && (chunkType == wal.ChunkTypeFull || chunkType == wal.ChunkTypeFirst) {
+			r.damaged(start, r.offset, reason, 1)
+			data, start = nil, -1
+			continue
+		}
+
+		if reason != nil {
+			// The record the chunk belongs to is lost, unless the chunk is the whole continuation of a record lost already
+			lost := uint64(1)
+			if reason == errChunkOutOfOrder && start < 0 {
+				lost = 0
+			}
+
+			// The chunks after it in its block cannot be trusted to start where its length says,
+			// unless a valid chunk starts there
+			resume := nextBlock
+			if reason == errChunkChecksum && r.validChunk(end) {
+				resume = r.offset - blockOffset + end
+			}
+
+			from := r.offset
+			if start >= 0 {
+				from = start
+			}
+			r.damaged(from, resume, reason, lost)
+
+			data, start = nil, -1
+			r.offset = resume
+			continue
+		}
+
+		if start < 0 {
+			start = r.offset
+		}
+		data = append(data, r.block[blockOffset+walChunkHeaderSize:end]...)
+		r.offset += end - blockOffset
+
+		if chunkType == wal.ChunkTypeFull || chunkType == wal.ChunkTypeLast {
+			// The damaged range before the record is over
+			r.report()
+
+			return data, &wal.ChunkPosition{
+				SegmentId:   r.segments[r.current],
+				BlockNumber: uint32(start / walBlockSize),
+				ChunkOffset: start % walBlockSize,
+				ChunkSize:   uint32(r.offset - start),
+			}, nil
+		}
+	}
+
+	// A record whose last chunk is missing was torn at the end of the segment
+	if start >= 0 {
+		r.damaged(start, r.size, errTornChunk, 1)
+	}
+
+	return nil, nil, io.EOF
+}
+
+// load reads the block starting at the specified offset of the current segment, unless it is read already
+func (r *walReader) load(blockStart int64) error {
+	if r.blockStart == blockStart {
+		return nil
+	}
+
+	r.block = r.block[:min(walBlockSize, r.size-blockStart)]
+	if _, err := r.file.ReadAt(r.block, blockStart); err != nil {
+		return err
+	}
+	r.blockStart = blockStart
+
+	return nil
+}
+
+// validChunk reports whether a chunk whose checksum matches starts at the specified offset of the block being read
+func (r *walReader) validChunk(blockOffset int64) bool {
+	if blockOffset+walChunkHeaderSize > int64(len(r.block)) {
+		return false
+	}
+
+	header := r.block[blockOffset : blockOffset+walChunkHeaderSize]
+	end := blockOffset + walChunkHeaderSize + int64(binary.LittleEndian.Uint16(header[4:6]))
+	return end <= int64(len(r.block)) && crc32.ChecksumIEEE(r.block[blockOffset+4:end]) == binary.LittleEndian.Uint32(header[:4])
+}
+
+// damaged adds the range of the current segment to the damaged range it follows, if any, or starts a new damaged range
+func (r *walReader) damaged(from int64, to int64, reason error, records uint64) {
+	if r.damage != nil && r.damage.offset+r.damage.size == from {
+		r.damage.size = to - r.damage.offset
+		r.damage.records += records
+		r.damage.tail = to == r.size
+		return
+	}
+
+	r.report()
+	r.damage = &walDamage{
+		segment: r.segments[r.current],
+		offset:  from,
+		size:    to - from,
+		records: records,
+		tail:    to == r.size,
+		reason:  reason,
+	}
+}
+
+// report reports the damaged range being read, if any, once it is over
+func (r *walReader) report() {
+	if r.damage == nil {
+		return
+	}
+
+	if r.onDamage != nil {
+		r.onDamage(r.damage)
+	}
+	r.damage = nil
+}
+
+// Close closes the segment being read
+func (r *walReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// WalRecoveryOptions represents the options for the recovery of the WAL, the directory and the extension of its segment files
+// along with the recovery mode
+type WalRecoveryOptions struct {
+	DirPath        string
+	SegmentFileExt string
+	Mode           WalRecoveryMode
+}
+
+// readWalRecord reads the record of the WAL at the specified position, it fails with errNoWalRecord
+// if the WAL does not have a record starting there
+func readWalRecord(dirPath string, ext string, position *wal.ChunkPosition) ([]byte, error) {
+	reader, err := newWalReader(dirPath, ext, position.SegmentId, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if len(reader.segments) == 0 || reader.segments[0] != position.SegmentId {
+		return nil, errNoWalRecord
+	}
+	if err := reader.open(0); err != nil {
+		return nil, err
+	}
+
+	reader.offset = int64(position.BlockNumber)*walBlockSize + position.ChunkOffset
+	data, found, err := reader.next()
+	if err == io.EOF || (err == nil && (found.BlockNumber != position.BlockNumber || found.ChunkOffset != position.ChunkOffset)) {
+		return nil, errNoWalRecord
+	}
+
+	return data, err
+}
+
+// RecoverWal checks every record of the WAL before it is opened, for chunks torn by a crash in the middle of a write, chunks
+// whose checksum does not match and records that cannot be decoded, and recovers from the damaged records according to
+// the recovery mode. Every damaged range is reported along with its segment, the position it starts at and the number of
+// records lost. Unless the recovery mode refuses to start, a damaged range running up to the end of its segment is truncated,
+// so that the records written to the WAL afterwards do not follow it.
+func RecoverWal(options *WalRecoveryOptions) error {
+	switch options.Mode {
+	case WalRecoveryModeTruncate, WalRecoveryModeSkip, WalRecoveryModeRefuse:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownWalRecoveryMode, options.Mode)
+	}
+
+	var damages []*walDamage
+	reader, err := newWalReader(options.DirPath, options.SegmentFileExt, 0, func(damage *walDamage) {
+		damages = append(damages, damage)
+	})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// Count the records after the first damaged range, they are lost once the WAL is truncated
+	var after uint64
+	for {
+		data, position, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		if err := proto.Unmarshal(data, &pb.WalEntry{}); err != nil {
+			start := int64(position.BlockNumber)*walBlockSize + position.ChunkOffset
+			reader.damaged(start, reader.offset, errUndecodableRecord, 1)
+			continue
+		}
+
+		if len(damages) > 0 {
+			after++
+		}
+	}
+	reader.report()
+
+	if len(damages) == 0 {
+		return nil
+	}
+
+	var lost uint64
+	for _, damage := range damages {
+		lost += damage.records
+		slog.Warn("damaged WAL records", damage.attrs()...)
+	}
+
+	first := damages[0]
+	switch options.Mode {
+	case WalRecoveryModeRefuse:
+		return fmt.Errorf(
+			"%w: %d damaged ranges, the first one in segment %d at block %d, chunk offset %d (%v)",
+			ErrWalCorrupt,
+			len(damages),
+			first.segment,
+			first.offset/walBlockSize,
+			first.offset%walBlockSize,
+			first.reason,
+		)
+
+	case WalRecoveryModeTruncate:
+		// The segments after the damaged one are deleted along with the rest of it
+		if err := os.Truncate(wal.SegmentFileName(options.DirPath, options.SegmentFileExt, first.segment), first.offset); err != nil {
+			return err
+		}
+		for _, segment := range reader.segments {
+			if segment <= first.segment {
+				continue
+			}
+			if err := os.Remove(wal.SegmentFileName(options.DirPath, options.SegmentFileExt, segment)); err != nil {
+				return err
+			}
+		}
+
+		slog.Warn(
+			"WAL truncated",
+			slog.Uint64("segment", uint64(first.segment)),
+			slog.Int64("block", first.offset/walBlockSize),
+			slog.Int64("chunk_offset", first.offset%walBlockSize),
+			slog.Uint64("records", lost+after),
+		)
+
+	case WalRecoveryModeSkip:
+		for _, damage := range damages {
+			if !damage.tail {
+				continue
+			}
+			if err := os.Truncate(wal.SegmentFileName(options.DirPath, options.SegmentFileExt, damage.segment), damage.offset); err != nil {
+				return err
+			}
+		}
+
+		slog.Warn(
+			"damaged WAL records skipped",
+			slog.Int("ranges", len(damages)),
+			slog.Uint64("records", lost),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,323 @@
+// pkg/storage/wal_recovery_test.go
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rosedblabs/wal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
+)
+
+func recoverWal(dir string, mode WalRecoveryMode) error {
+	return RecoverWal(&WalRecoveryOptions{
+		DirPath:        dir,
+		SegmentFileExt: ".wal",
+		Mode:           mode,
+	})
+}
+
+// walSegmentData writes the data to the first segment of a new WAL, and returns the directory of the WAL
+func walSegmentData(t *testing.T, data []byte) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(wal.SegmentFileName(dir, ".wal", 1), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// walRecordAt returns the offset in its segment of the first WAL record holding an entry the match function matches
+func walRecordAt(t *testing.T, dir string, match func(entry *pb.WalEntry) bool) int64 {
+	reader, err := newWalReader(dir, ".wal", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for {
+		data, position, err := reader.Next()
+		if err == io.EOF {
+			t.Fatal("no WAL record matches")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entry := &pb.WalEntry{}
+		if err := proto.Unmarshal(data, entry); err == nil && match(entry) {
+			return int64(position.BlockNumber)*walBlockSize + position.ChunkOffset
+		}
+	}
+}
+
+// walMessageAt returns the offset in its segment of the WAL record holding the message with the content
+func walMessageAt(t *testing.T, dir string, content string) int64 {
+	return walRecordAt(t, dir, func(entry *pb.WalEntry) bool {
+		return string(entry.GetMessage().GetContent()) == content
+	})
+}
+
+// walDamages returns the damaged ranges of the WAL
+func walDamages(t *testing.T, dir string) []*walDamage {
+	var damages []*walDamage
+	reader, err := newWalReader(dir, ".wal", 0, func(damage *walDamage) {
+		damages = append(damages, damage)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for {
+		if _, _, err := reader.Next(); err != nil {
+			assert.Equal(t, io.EOF, err)
+			return damages
+		}
+	}
+}
+
+// contentsAfterRestart returns the contents of the messages of the channel once the storage is restarted on the WAL
+func contentsAfterRestart(t *testing.T, dir string) []string {
+	m, closeStorage := openMemoryStorage(t, dir, "")
+	defer closeStorage()
+
+	if !m.ChannelExists("channel") {
+		return nil
+	}
+	messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+	assert.NoError(t, err)
+	return contentsOf(messages)
+}
+
+func TestWalRecoveryCrash(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openMemoryStorage(t, dir, "")
+	assert.NoError(t, m.CreateChannel("channel"))
+
+	var acknowledged []string
+	for i := 0; i < 5; i++ {
+		content := fmt.Sprintf("message-%d", i)
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+		acknowledged = append(acknowledged, content)
+	}
+
+	info, err := os.Stat(wal.SegmentFileName(dir, ".wal", 1))
+	assert.NoError(t, err)
+	acknowledgedSize := info.Size()
+
+	// The broker crashes while the last message, spanning several blocks, is written
+	_, err = m.SaveMessage("channel", newMessage(string(bytes.Repeat([]byte("x"), 3*walBlockSize))))
+	assert.NoError(t, err)
+	closeStorage()
+
+	data, err := os.ReadFile(wal.SegmentFileName(dir, ".wal", 1))
+	assert.NoError(t, err)
+
+	size := int64(len(data))
+	cuts := []int64{
+		acknowledgedSize + 1,
+		acknowledgedSize + walChunkHeaderSize - 1,
+		acknowledgedSize + walChunkHeaderSize + 1,
+		walBlockSize - 1,
+		walBlockSize,
+		walBlockSize + walChunkHeaderSize,
+		2*walBlockSize + 100,
+		size - 1,
+	}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 16; i++ {
+		cuts = append(cuts, acknowledgedSize+1+random.Int63n(size-acknowledgedSize-1))
+	}
+
+	for _, mode := range []WalRecoveryMode{WalRecoveryModeTruncate, WalRecoveryModeSkip} {
+		for _, cut := range cuts {
+			// The write is either cut short, or followed by whatever the disk held before
+			noise := make([]byte, size-cut)
+			random.Read(noise)
+
+			for _, crashed := range [][]byte{data[:cut], append(bytes.Clone(data[:cut]), noise...)} {
+				dir := walSegmentData(t, crashed)
+				assert.NoError(t, recoverWal(dir, mode))
+				assert.Equal(t, acknowledged, contentsAfterRestart(t, dir), "%s, cut at %d", mode, cut)
+
+				// The messages saved once recovered survive the next restart
+				m, closeStorage := openMemoryStorage(t, dir, "")
+				offset, err := m.SaveMessage("channel", newMessage("after"))
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(len(acknowledged)), offset)
+				closeStorage()
+
+				assert.NoError(t, recoverWal(dir, mode))
+				assert.Equal(t, append(append([]string{}, acknowledged...), "after"), contentsAfterRestart(t, dir), "%s, cut at %d", mode, cut)
+			}
+		}
+	}
+}
+
+func TestWalRecoveryModes(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openMemoryStorage(t, dir, "")
+	assert.NoError(t, m.CreateChannel("channel"))
+	for _, content := range []string{"a", "b", "c", "d", "e", "f"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	closeStorage()
+
+	// Flip a byte of the record holding the third message
+	data, err := os.ReadFile(wal.SegmentFileName(dir, ".wal", 1))
+	assert.NoError(t, err)
+	offset := walMessageAt(t, dir, "c")
+	data[offset+walChunkHeaderSize+1] ^= 0xff
+
+	damages := walDamages(t, walSegmentData(t, data))
+	assert.Len(t, damages, 1)
+	assert.Equal(t, wal.SegmentID(1), damages[0].segment)
+	assert.Equal(t, offset, damages[0].offset)
+	assert.Equal(t, uint64(1), damages[0].records)
+	assert.Equal(t, errChunkChecksum, damages[0].reason)
+	assert.False(t, damages[0].tail)
+
+	// The WAL is left untouched when the recovery refuses to start
+	refused := walSegmentData(t, data)
+	assert.ErrorIs(t, recoverWal(refused, WalRecoveryModeRefuse), ErrWalCorrupt)
+	untouched, err := os.ReadFile(wal.SegmentFileName(refused, ".wal", 1))
+	assert.NoError(t, err)
+	assert.Equal(t, data, untouched)
+
+	skipped := walSegmentData(t, data)
+	assert.NoError(t, recoverWal(skipped, WalRecoveryModeSkip))
+	assert.Equal(t, []string{"a", "b", "d", "e", "f"}, contentsAfterRestart(t, skipped))
+
+	// The messages after the damaged one keep their offsets
+	m, closeStorage = openMemoryStorage(t, skipped, "")
+	messages, _, err := m.GetMessages("channel", "subscriber", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "d", string(messages[0].GetContent()))
+	closeStorage()
+
+	truncated := walSegmentData(t, data)
+	assert.NoError(t, recoverWal(truncated, WalRecoveryModeTruncate))
+	assert.Equal(t, []string{"a", "b"}, contentsAfterRestart(t, truncated))
+	assert.Empty(t, walDamages(t, truncated))
+
+	assert.ErrorIs(t, recoverWal(truncated, WalRecoveryMode("repair")), ErrUnknownWalRecoveryMode)
+}
+
+func TestWalRecoveryUndecodableRecord(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openMemoryStorage(t, dir, "")
+	assert.NoError(t, m.CreateChannel("channel"))
+	for _, content := range []string{"a", "b"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+
+	// A record whose checksum matches, but which is not an entry
+	_, err := m.wal.Write([]byte{0xff})
+	assert.NoError(t, err)
+
+	_, err = m.SaveMessage("channel", newMessage("c"))
+	assert.NoError(t, err)
+	closeStorage()
+
+	data, err := os.ReadFile(wal.SegmentFileName(dir, ".wal", 1))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, recoverWal(walSegmentData(t, data), WalRecoveryModeRefuse), ErrWalCorrupt)
+
+	skipped := walSegmentData(t, data)
+	assert.NoError(t, recoverWal(skipped, WalRecoveryModeSkip))
+	assert.Equal(t, []string{"a", "b", "c"}, contentsAfterRestart(t, skipped))
+
+	truncated := walSegmentData(t, data)
+	assert.NoError(t, recoverWal(truncated, WalRecoveryModeTruncate))
+	assert.Equal(t, []string{"a", "b"}, contentsAfterRestart(t, truncated))
+
+	_, err = os.Stat(filepath.Join(truncated, "000000001.wal"))
+	assert.NoError(t, err)
+}
+
+// replayWal opens a memory storage on the WAL in the directory, replaying it with the recovery mode
+func replayWal(t *testing.T, dir string, mode WalRecoveryMode) (*MemoryStorage, error) {
+	log, err := wal.Open(wal.Options{
+		DirPath:        dir,
+		SegmentSize:    wal.DefaultOptions.SegmentSize,
+		SegmentFileExt: ".wal",
+		Sync:           false,
+		BytesPerSync:   0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = log.Close() })
+
+	return NewMemoryStorage(&MemoryStorageOptions{
+		Wal:               log,
+		WalDirPath:        dir,
+		WalSegmentFileExt: ".wal",
+		WalSync:           false,
+		WalRecoveryMode:   mode,
+		BatchSize:         100,
+		SyncOnStartup:     true,
+		DedupWindow:       0,
+		SweepInterval:     0,
+		SnapshotDirPath:   "",
+		SnapshotInterval:  0,
+	})
+}
+
+func TestMemoryStorageReplayRecoveryModes(t *testing.T) {
+	dir := t.TempDir()
+	m, closeStorage := openMemoryStorage(t, dir, "")
+	assert.NoError(t, m.CreateChannel("channel"))
+	for _, content := range []string{"a", "b", "c"} {
+		_, err := m.SaveMessage("channel", newMessage(content))
+		assert.NoError(t, err)
+	}
+	closeStorage()
+
+	// The WAL was damaged after it was recovered
+	data, err := os.ReadFile(wal.SegmentFileName(dir, ".wal", 1))
+	assert.NoError(t, err)
+	data[walMessageAt(t, dir, "b")+walChunkHeaderSize+1] ^= 0xff
+
+	_, err = replayWal(t, walSegmentData(t, data), WalRecoveryModeRefuse)
+	assert.ErrorIs(t, err, ErrWalCorrupt)
+
+	for _, mode := range []WalRecoveryMode{WalRecoveryModeSkip, WalRecoveryModeTruncate} {
+		m, err := replayWal(t, walSegmentData(t, data), mode)
+		assert.NoError(t, err)
+		messages, _, err := m.GetMessages("channel", "subscriber", OffsetBeginning)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "c"}, contentsOf(messages), mode)
+	}
+
+	// A WAL that cannot be read fails the storage instead of the process
+	file := filepath.Join(t.TempDir(), "wal")
+	assert.NoError(t, os.WriteFile(file, nil, 0o644))
+	_, err = NewMemoryStorage(&MemoryStorageOptions{
+		Wal:               nil,
+		WalDirPath:        file,
+		WalSegmentFileExt: ".wal",
+		WalSync:           false,
+		WalRecoveryMode:   WalRecoveryModeSkip,
+		BatchSize:         100,
+		SyncOnStartup:     true,
+		DedupWindow:       0,
+		SweepInterval:     0,
+		SnapshotDirPath:   "",
+		SnapshotInterval:  0,
+	})
+	assert.Error(t, err)
+}
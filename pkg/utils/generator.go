@@ -19,6 +19,7 @@ type Generator interface {
 	GetUniqueMessageID() string
 	GetUniqueSubscriberID() string
 	GetCurrentTimestamp() int64
+	GetCurrentTimeMillis() int64
 }
 
 // generator is the default implementation of the Generator interface
@@ -43,3 +44,8 @@ func (g *generator) GetUniqueSubscriberID() string {
 func (g *generator) GetCurrentTimestamp() int64 {
 	return time.Now().Unix()
 }
+
+// GetCurrentTimeMillis returns the current time in milliseconds since the epoch
+func (g *generator) GetCurrentTimeMillis() int64 {
+	return time.Now().UnixMilli()
+}
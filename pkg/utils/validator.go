@@ -4,11 +4,27 @@
 package utils
 
 import (
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 
 	pb "github.com/hitesh22rana/mq/pkg/proto/mq"
 )
 
+const (
+	// MaxHeaders is the maximum number of headers of a message
+	MaxHeaders = 64
+
+	// MaxHeaderKeySize is the maximum size of the key of a header, in bytes
+	MaxHeaderKeySize = 256
+
+	// MaxHeaderValueSize is the maximum size of the value of a header, in bytes
+	MaxHeaderValueSize = 4096
+
+	// MaxHeadersSize is the maximum total size of the keys and values of the headers of a message, in bytes
+	MaxHeadersSize = 16384
+)
+
 // Validator defines the interface for validating input structs
 type Validator interface {
 	ValidateStruct(input interface{}) error
@@ -22,19 +38,65 @@ type val struct {
 func validateOffset(fl validator.FieldLevel) bool {
 	offset := fl.Field().Interface().(pb.Offset)
 	switch offset {
-	case pb.Offset_OFFSET_BEGINNING, pb.Offset_OFFSET_LATEST:
+	case pb.Offset_OFFSET_BEGINNING,
+		pb.Offset_OFFSET_LATEST,
+		pb.Offset_OFFSET_START_OFFSET,
+		pb.Offset_OFFSET_START_TIME:
+		return true
+	default:
+		return false
+	}
+}
+
+func validateDurability(fl validator.FieldLevel) bool {
+	durability := fl.Field().Interface().(pb.Durability)
+	switch durability {
+	case pb.Durability_DURABILITY_DEFAULT,
+		pb.Durability_DURABILITY_SYNC:
 		return true
 	default:
 		return false
 	}
 }
 
+func validateHeaders(fl validator.FieldLevel) bool {
+	headers := fl.Field().Interface().(map[string]string)
+	if len(headers) > MaxHeaders {
+		return false
+	}
+
+	size := 0
+	for key, value := range headers {
+		if key == "" || len(key) > MaxHeaderKeySize || len(value) > MaxHeaderValueSize {
+			return false
+		}
+		size += len(key) + len(value)
+	}
+
+	return size <= MaxHeadersSize
+}
+
+// validateIdempotencyKey reports whether the idempotency key can be supplied by a publisher, the keys starting
+// with a NUL byte are reserved for the keys derived from the producer IDs and sequence numbers
+func validateIdempotencyKey(fl validator.FieldLevel) bool {
+	return !strings.HasPrefix(fl.Field().String(), "\x00")
+}
+
 // NewValidator returns a new validator
 func NewValidator() Validator {
 	_val := validator.New()
 
 	// Register custom offset validation
 	_val.RegisterValidation("offset", validateOffset)
+
+	// Register custom durability validation
+	_val.RegisterValidation("durability", validateDurability)
+
+	// Register custom headers validation
+	_val.RegisterValidation("headers", validateHeaders)
+
+	// Register custom idempotency key validation
+	_val.RegisterValidation("idempotency_key", validateIdempotencyKey)
 	return &val{_val}
 }
 
@@ -3,6 +3,8 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +33,32 @@ func TestValidator(t *testing.T) {
 			},
 			isErr: false,
 		},
+		{
+			name: "Valid start offset",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Name   string    `validate:"required"`
+					Offset pb.Offset `validate:"required,offset"`
+				}{
+					Name:   "test",
+					Offset: pb.Offset_OFFSET_START_OFFSET,
+				})
+			},
+			isErr: false,
+		},
+		{
+			name: "Valid start time",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Name   string    `validate:"required"`
+					Offset pb.Offset `validate:"required,offset"`
+				}{
+					Name:   "test",
+					Offset: pb.Offset_OFFSET_START_TIME,
+				})
+			},
+			isErr: false,
+		},
 		{
 			name: "InvalidOffset",
 			fn: func() error {
@@ -44,6 +72,125 @@ func TestValidator(t *testing.T) {
 			},
 			isErr: true,
 		},
+		{
+			name: "Valid durability",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Durability pb.Durability `validate:"durability"`
+				}{
+					Durability: pb.Durability_DURABILITY_SYNC,
+				})
+			},
+			isErr: false,
+		},
+		{
+			name: "Invalid durability",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Durability pb.Durability `validate:"durability"`
+				}{
+					Durability: pb.Durability(42),
+				})
+			},
+			isErr: true,
+		},
+		{
+			name: "Valid headers",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Headers map[string]string `validate:"headers"`
+				}{
+					Headers: map[string]string{
+						"content-type": "application/json",
+						"trace-id":     "",
+					},
+				})
+			},
+			isErr: false,
+		},
+		{
+			name: "Valid empty headers",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Headers map[string]string `validate:"headers"`
+				}{})
+			},
+			isErr: false,
+		},
+		{
+			name: "InvalidHeaders: empty key",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Headers map[string]string `validate:"headers"`
+				}{
+					Headers: map[string]string{"": "value"},
+				})
+			},
+			isErr: true,
+		},
+		{
+			name: "InvalidHeaders: too many headers",
+			fn: func() error {
+				headers := make(map[string]string)
+				for i := 0; i <= MaxHeaders; i++ {
+					headers[fmt.Sprintf("key-%d", i)] = "value"
+				}
+				return v.ValidateStruct(struct {
+					Headers map[string]string `validate:"headers"`
+				}{
+					Headers: headers,
+				})
+			},
+			isErr: true,
+		},
+		{
+			name: "InvalidHeaders: value too large",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					Headers map[string]string `validate:"headers"`
+				}{
+					Headers: map[string]string{"key": strings.Repeat("v", MaxHeaderValueSize+1)},
+				})
+			},
+			isErr: true,
+		},
+		{
+			name: "InvalidHeaders: headers too large",
+			fn: func() error {
+				headers := make(map[string]string)
+				for i := 0; i < MaxHeaders; i++ {
+					headers[fmt.Sprintf("key-%d", i)] = strings.Repeat("v", MaxHeaderValueSize)
+				}
+				return v.ValidateStruct(struct {
+					Headers map[string]string `validate:"headers"`
+				}{
+					Headers: headers,
+				})
+			},
+			isErr: true,
+		},
+		{
+			name: "Valid idempotency key",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					IdempotencyKey string `validate:"idempotency_key"`
+				}{
+					IdempotencyKey: "producer:3",
+				})
+			},
+			isErr: false,
+		},
+		{
+			name: "InvalidIdempotencyKey: reserved prefix",
+			fn: func() error {
+				return v.ValidateStruct(struct {
+					IdempotencyKey string `validate:"idempotency_key"`
+				}{
+					IdempotencyKey: "\x00prod:producer:3",
+				})
+			},
+			isErr: true,
+		},
 	}
 
 	for _, tt := range tests {